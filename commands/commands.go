@@ -112,6 +112,39 @@ var (
 				return nil
 			},
 		},
+		{
+			Name:  "ReplayEvents",
+			Usage: "replay stored TransactionScan logs through callbacks without rpc, txs whose callbacks read the chain fail",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:     "chain",
+					Required: true,
+				},
+				cli.Int64Flag{
+					Name: "from",
+				},
+				cli.Int64Flag{
+					Name:  "to",
+					Usage: "0 means the latest scanned block",
+				},
+				cli.StringSliceFlag{
+					Name:  "contract",
+					Usage: "contract names, eg: ClockAuctionApostle",
+				},
+				cli.BoolFlag{
+					Name: "dry-run",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return ReplayEvents(context.TODO(), models.ReplayOpt{
+					Chain:         c.String("chain"),
+					FromBlock:     c.Int64("from"),
+					ToBlock:       c.Int64("to"),
+					ContractNames: c.StringSlice("contract"),
+					DryRun:        c.Bool("dry-run"),
+				})
+			},
+		},
 		{
 			Name: "RefreshElementRaffle",
 			Flags: []cli.Flag{
@@ -126,5 +159,7 @@ var (
 				return models.RefreshElementRaffle(context.TODO(), c.StringSlice("chain"), c.Int64Slice("start_block"))
 			},
 		},
+		parseTxErrorCommand,
+		reconcileLedgerCommand,
 	}
 )
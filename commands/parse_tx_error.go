@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/urfave/cli"
+)
+
+var parseTxErrorCommand = cli.Command{
+	Name:  "ParseTxError",
+	Usage: "manage the dead-letter queue of failed callbacks",
+	Subcommands: []cli.Command{
+		{
+			Name: "list",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "status",
+					Value: models.ParseTxErrorPending,
+				},
+				cli.StringFlag{
+					Name: "chain",
+				},
+				cli.IntFlag{
+					Name: "page",
+				},
+				cli.IntFlag{
+					Name:  "row",
+					Value: 20,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				query := models.ParseTxErrorQuery{Status: c.String("status"), Chain: c.String("chain"), Page: c.Int("page"), Row: c.Int("row")}
+				list, count := query.List(context.TODO())
+				fmt.Printf("total %d\n", count)
+				for _, pe := range list {
+					fmt.Printf("%d\t%s\t%s\t%s\t%s\tattempts %d\t%s\n", pe.ID, pe.Chain, pe.Tx, pe.ParseFunc, pe.Status, pe.Attempts, pe.Error)
+				}
+				return nil
+			},
+		},
+		{
+			Name:      "show",
+			ArgsUsage: "<id>",
+			Action: func(c *cli.Context) error {
+				pe, err := getParseTxError(c)
+				if err != nil {
+					return err
+				}
+				fmt.Println(util.ToString(pe.AsJson()))
+				return nil
+			},
+		},
+		{
+			Name:      "retry",
+			ArgsUsage: "<id>",
+			Action: func(c *cli.Context) error {
+				pe, err := getParseTxError(c)
+				if err != nil {
+					return err
+				}
+				return pe.Retry(context.TODO())
+			},
+		},
+		{
+			Name:      "discard",
+			ArgsUsage: "<id>",
+			Action: func(c *cli.Context) error {
+				pe, err := getParseTxError(c)
+				if err != nil {
+					return err
+				}
+				return pe.Discard(context.TODO())
+			},
+		},
+	},
+}
+
+func getParseTxError(c *cli.Context) (*models.ParseTxError, error) {
+	id := util.StringToInt(c.Args().Get(0))
+	if id <= 0 {
+		return nil, errors.New("invalid id")
+	}
+	pe := models.GetParseTxError(context.TODO(), uint(id))
+	if pe == nil {
+		return nil, fmt.Errorf("parse tx error %d not found", id)
+	}
+	return pe, nil
+}
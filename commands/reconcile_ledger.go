@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/urfave/cli"
+)
+
+var reconcileLedgerCommand = cli.Command{
+	Name:  "ReconcileLedger",
+	Usage: "check account balances against their version history and withdraws against scanned TakedBack events",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "chain",
+			Usage: "only check this chain",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "write the report as json to this file",
+		},
+		cli.BoolFlag{
+			Name:  "repair",
+			Usage: "set balance of mismatched accounts to the sum of their version history",
+		},
+		cli.BoolFlag{
+			Name:  "yes",
+			Usage: "skip the confirmation of repair",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		ctx := context.TODO()
+		report, err := models.ReconcileLedger(ctx, models.ReconcileOpt{Chain: c.String("chain")})
+		if err != nil {
+			return err
+		}
+		for _, d := range report.Discrepancies {
+			fmt.Printf("%s\t%s\t%d\t%s\t%s\t%s\texpected %s\tactual %s\t%s\n",
+				d.Kind, d.Chain, d.AccountId, d.Wallet, d.Currency, d.Tx, d.Expected, d.Actual, d.Detail)
+		}
+		fmt.Printf("checked %d accounts %d withdraws %d events, discrepancies %v\n", report.Accounts, report.Withdraws, report.Events, report.Count())
+		if output := c.String("output"); output != "" {
+			b, _ := json.MarshalIndent(report, "", "  ")
+			if err = os.WriteFile(output, b, 0644); err != nil {
+				return err
+			}
+		}
+		if !c.Bool("repair") {
+			return nil
+		}
+		n := report.Count()[models.DiscrepancyBalance]
+		if n == 0 {
+			return nil
+		}
+		if !c.Bool("yes") && !confirm(os.Stdin, fmt.Sprintf("repair balance of %d accounts? type yes to continue: ", n)) {
+			return errors.New("repair aborted")
+		}
+		repaired, err := report.Repair(ctx)
+		fmt.Printf("repaired %d accounts\n", repaired)
+		return err
+	},
+}
+
+func confirm(r io.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(r).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
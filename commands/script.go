@@ -141,7 +141,7 @@ type RebuildTransactionRecordsOpt struct {
 
 func RebuildTransactionRecords(ctx context.Context, opt RebuildTransactionRecordsOpt) {
 	if len(opt.Chain) == 0 {
-		opt.Chain = append(opt.Chain, models.CrabChain, models.EthChain, models.TronChain, models.HecoChain, models.PolygonChain)
+		opt.Chain = append(opt.Chain, util.Evo.Networks...)
 	}
 
 	for _, v := range opt.Chain {
@@ -164,7 +164,7 @@ func RebuildTransactionRecords(ctx context.Context, opt RebuildTransactionRecord
 
 		go func(chain string, startBlock uint64) {
 			var scanType = block_scan.SUBSCRIBE
-			if chain == models.TronChain {
+			if storage.GetChainWssRpc(chain) == "" {
 				scanType = block_scan.POLLING
 			}
 			var contractsName = make(map[services.ContractsAddress]services.ContractsName)
@@ -210,3 +210,17 @@ func RebuildTransactionRecords(ctx context.Context, opt RebuildTransactionRecord
 	}
 	select {}
 }
+
+func ReplayEvents(ctx context.Context, opt models.ReplayOpt) error {
+	opt.Progress = func(p models.ReplayProgress) {
+		log.Info("ReplayEvents %s %d/%d, block %d, dispatched %d, skipped %d, failed %d",
+			opt.Chain, p.Scanned, p.Total, p.Block, p.Dispatched, p.Skipped, p.Failed)
+	}
+	p, err := models.ReplayEvents(ctx, opt)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("replay %s done: total %d, dispatched %d, skipped %d, failed %d\n",
+		opt.Chain, p.Total, p.Dispatched, p.Skipped, p.Failed)
+	return nil
+}
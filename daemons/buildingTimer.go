@@ -0,0 +1,42 @@
+package daemons
+
+import (
+	"context"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+)
+
+// BuildingTimer complete constructions and upgrades whose timer elapsed and expire employed admins.
+// Updates are guarded by the building status and admin row, running on several instances is safe
+func BuildingTimer(ctx context.Context) {
+	defer util.Recover("BuildingTimer error")
+	t := time.NewTicker(time.Second * 30)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug("BuildingTimer done")
+			return
+		case <-t.C:
+			util.OnceTask(ctx, "BuildingTimer", 300, func() {
+				defer metrics.Since(metrics.DaemonTickDuration, time.Now(), "BuildingTimer")
+				now := time.Now().Unix()
+				for _, building := range models.DueBuildings(ctx, now, 500) {
+					if err := building.UpgradeComplete(ctx); err != nil {
+						log.Error("BuildingTimer complete building %d error: %s", building.ID, err)
+					}
+				}
+				for _, admin := range models.ExpiredBuildingAdmins(ctx, now, 500) {
+					if err := admin.Expire(ctx); err != nil {
+						log.Error("BuildingTimer expire admin %s of building %d error: %s", admin.ApostleTokenId, admin.BuildingId, err)
+					}
+				}
+				models.MarkDaemonSuccess(ctx, "BuildingTimer")
+			})
+		}
+	}
+}
@@ -1,7 +1,15 @@
 package daemons
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+)
 
 func StartUploadData(ctx context.Context) {
+	defer metrics.Since(metrics.DaemonTickDuration, time.Now(), "UploadData")
 	UploadProjectData(ctx, "heco")
+	models.MarkDaemonSuccess(ctx, "UploadData")
 }
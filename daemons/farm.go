@@ -12,6 +12,7 @@ import (
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
 	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
 	"github.com/evolutionlandorg/staker/apr"
 	"github.com/shopspring/decimal"
 )
@@ -46,9 +47,12 @@ func FreshFarmAPR(ctx context.Context) {
 			log.Debug("FreshFarmAPR done")
 			return
 		case <-t.C:
+			start := time.Now()
 			FreshChainFarmAPR(ctx, "Heco")
 			FreshChainFarmAPR(ctx, "Polygon")
 			FreshChainFarmAPR(ctx, "Crab")
+			metrics.Since(metrics.DaemonTickDuration, start, "FreshFarmAPR")
+			models.MarkDaemonSuccess(ctx, "FreshFarmAPR")
 			t.Reset(time.Minute)
 		}
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
@@ -26,6 +27,7 @@ func FreshBlockStatus(ctx context.Context) {
 			log.Debug("FreshBlockStatus done")
 			return
 		case <-t.C:
+			start := time.Now()
 			span, spanCtx := tracer.StartSpanFromContext(ctx, "daemons.worker",
 				tracer.ServiceName("evo-backend-worker"),
 				tracer.SpanType(ext.SpanTypeMessageConsumer),
@@ -44,6 +46,8 @@ func FreshBlockStatus(ctx context.Context) {
 				span.Finish()
 				t.Reset(time.Second * 5)
 			}
+			metrics.Since(metrics.DaemonTickDuration, start, "FreshBlockStatus")
+			models.MarkDaemonSuccess(ctx, "FreshBlockStatus")
 		}
 	}
 }
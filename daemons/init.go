@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	block_scan "github.com/evolutionlandorg/block-scan"
@@ -13,80 +14,181 @@ import (
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
 	"github.com/gomodule/redigo/redis"
+	"github.com/itering/go-workers"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/spf13/cast"
 )
 
-func Start(ctx context.Context) {
-	fns := []func(ctx context.Context){
-		FreshBlockStatus,
-		FreshFarmAPR,
-		FreshSwapStatus,
-		StartUploadData,
-		StartWorker,
-		StartWipeBlock,
-		StartSnapshot,
-	}
+// daemonList daemons of the scheduler role, each records its last success by name with models.MarkDaemonSuccess.
+// Only the instance holding the lease of a singleton daemon runs it, others take over when the leader is gone
+var daemonList = []struct {
+	name      string
+	fn        func(ctx context.Context)
+	singleton bool
+}{
+	{"FreshBlockStatus", FreshBlockStatus, true},
+	{"FreshFarmAPR", FreshFarmAPR, true},
+	{"FreshSwapStatus", FreshSwapStatus, true},
+	{"UploadData", StartUploadData, true},
+	{"Snapshot", StartSnapshot, true},
+	{"RetryParseTxErrors", RetryParseTxErrors, true},
+	{"ReconcileLedger", ReconcileLedger, true},
+	{"BuildingTimer", BuildingTimer, false}, // ticks are guarded by OnceTask
+}
 
-	do := func(fn func(ctx context.Context)) func() {
-		return func() {
-			fn(ctx)
-		}
+// running daemons started by this process, Wait waits them to return after ctx is canceled
+var running sync.WaitGroup
+
+// Names names of daemons of all roles, WipeBlock of the scanner role and Worker of the worker role
+func Names() []string {
+	names := []string{"WipeBlock", "Worker"}
+	for _, d := range daemonList {
+		names = append(names, d.name)
 	}
-	for _, fn := range fns {
-		go util.RecoverRunForever("freshBlockStatus error", do(fn), time.Second*10, true)
+	return names
+}
+
+func leaseTTL() time.Duration {
+	ttl := time.Duration(cast.ToInt(util.GetEnv("DAEMON_LEASE_TTL", "30"))) * time.Second
+	if ttl <= 0 {
+		return 30 * time.Second
 	}
+	return ttl
 }
 
-func StartWipeBlock(ctx context.Context) {
-	do := func(chain string, contractsMap util.ContractAddress) func() {
-		return func() {
-			startWipeTrxBlock(ctx, chain, contractsMap)
+// run runs fn until it returns on ctx canceled, restarting it on panic. singleton fn only runs on the instance
+// holding the lease of name
+func run(ctx context.Context, name string, fn func(ctx context.Context), singleton bool) {
+	goRecover(fmt.Sprintf("%s error", name), func() {
+		if singleton {
+			util.RunWithLease(ctx, fmt.Sprintf("daemon:%s", name), leaseTTL(), fn)
+			return
 		}
+		fn(ctx)
+	})
+}
+
+// goRecover runs f in a goroutine waited by Wait, f is restarted after 10s when it panics
+func goRecover(msg string, f func()) {
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		util.RecoverRunForever(msg, f, time.Second*10, true)
+	}()
+}
+
+// Start starts all roles, scanners of all chains, processors of all queues and daemons of the scheduler
+func Start(ctx context.Context) {
+	util.Panic(StartScanner(ctx, nil))
+	StartWorker(ctx, nil)
+	StartScheduler(ctx)
+}
+
+// StartScheduler starts daemons of daemonList
+func StartScheduler(ctx context.Context) {
+	for _, d := range daemonList {
+		run(ctx, d.name, d.fn, d.singleton)
 	}
-	for chain, contractsMap := range util.Evo.Contracts {
-		if util.IsProduction() && chain == storage.Bsc {
-			continue
-		}
+}
 
-		if !util.IsProduction() && util.IntInSlice(chain, []string{storage.Ethereum}) {
-			continue
+// StartScanner starts block scan and reorg check of chains, all chains of the environment if chains is empty.
+// Each chain is leased on its own, so scanners of different chains can be spread over instances
+func StartScanner(ctx context.Context, chains []string) error {
+	if len(chains) == 0 {
+		for chain := range util.Evo.Contracts {
+			if util.IsProduction() && chain == storage.Bsc {
+				continue
+			}
+			if !util.IsProduction() && util.IntInSlice(chain, []string{storage.Ethereum}) {
+				continue
+			}
+			chains = append(chains, chain)
+		}
+	}
+	for _, chain := range chains {
+		if _, ok := util.Evo.Contracts[chain]; !ok {
+			return fmt.Errorf("unknown chain %s", chain)
 		}
-		go util.RecoverRunForever(
-			fmt.Sprintf("%s WipeBlock error", chain),
-			do(chain, contractsMap),
-			time.Second*10,
-			true,
-		)
 	}
+	for _, chain := range chains {
+		contractsMap := util.Evo.Contracts[chain]
+		run(ctx, fmt.Sprintf("WipeBlock:%s", chain), func(ctx context.Context) {
+			goRecover(fmt.Sprintf("%s ReorgCheck error", chain), func() { startReorgCheck(ctx, chain, contractsMap) })
+			startWipeTrxBlock(ctx, chain, contractsMap)
+		}, true)
+	}
+	return nil
+}
+
+// StartWorker starts processors of queues, all <chain>Process queues if queues is empty.
+// Processors quit when ctx is canceled, workers.Quit stops fetching and returns after in-flight callbacks finish,
+// so Wait covers them
+func StartWorker(ctx context.Context, queues []string) {
+	run(ctx, "Worker", func(ctx context.Context) {
+		RunWorker(queues)
+		workers.Start()
+		defer workers.Quit()
+		<-ctx.Done()
+	}, false)
 }
 
-func StartWorker(ctx context.Context) {
-	go util.RecoverRunForever("worker error", RunWorker, time.Second*10, true)
+// Wait waits daemons to return after ctx of them is canceled, it returns false if timeout comes first
+func Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
 
 func startWipeTrxBlock(ctx context.Context, chain string, contractsMap util.ContractAddress) {
 	var scanType = block_scan.SUBSCRIBE
-	if chain == models.TronChain {
+	if storage.GetChainWssRpc(chain) == "" {
 		scanType = block_scan.POLLING
 	}
+	sg := newScannedChain(storage.New(chain))
+	opt := scanEventsOptions(chain, contractsMap)
+	opt.ChainIo = sg
+	opt.GetStartBlock = func() uint64 {
+		n, _ := redis.Uint64(util.SubPoolWithContextDo(context.TODO())("HGET", "WipeBlock", chain))
+		return n
+	}
+	opt.SetStartBlock = func(currentBlockNum uint64) {
+		_, _ = util.SubPoolWithContextDo(context.TODO())("HSET", "WipeBlock", chain, currentBlockNum)
+		recordScanBlock(ctx, chain, sg, currentBlockNum)
+		models.MarkDaemonSuccess(ctx, "WipeBlock")
+		models.MarkDaemonSuccess(ctx, fmt.Sprintf("WipeBlock:%s", chain))
+	}
+	opt.SleepTime = 5
+	opt.InitBlock = util.Evo.WipeBlock[chain].InitBlock
+	opt.RunForever = true
+	opt.BeforePushMiddleware = []services.BeforePushFunc{
+		func(tx string, BlockTimestamp uint64, receipt *services.Receipts) bool {
+			saveTransactionScan(chain, tx, BlockTimestamp, receipt)
+			return true
+		},
+	}
+	_ = os.Setenv(fmt.Sprintf("%s_WSS_RPC", strings.ToUpper(chain)), storage.GetChainWssRpc(chain))
+	// losing the lease or shutting down cancels ctx, the scan returns then and another instance takes over
+	if err := block_scan.StartScanChainEvents(ctx, scanType, opt); err != nil && ctx.Err() == nil {
+		util.Panic(err)
+	}
+}
+
+func scanEventsOptions(chain string, contractsMap util.ContractAddress) services.ScanEventsOptions {
 	var contractsName = make(map[services.ContractsAddress]services.ContractsName)
 	for address, name := range contractsMap {
 		contractsName[services.ContractsAddress(strings.ToLower(address))] = services.ContractsName(name)
 	}
-	_ = os.Setenv(fmt.Sprintf("%s_WSS_RPC", strings.ToUpper(chain)), storage.GetChainWssRpc(chain))
-	util.Panic(block_scan.StartScanChainEvents(ctx, scanType, services.ScanEventsOptions{
-		ChainIo: storage.New(chain),
-		GetStartBlock: func() uint64 {
-			n, _ := redis.Uint64(util.SubPoolWithContextDo(context.TODO())("HGET", "WipeBlock", chain))
-			return n
-		},
-		SetStartBlock: func(currentBlockNum uint64) {
-			_, _ = util.SubPoolWithContextDo(context.TODO())("HSET", "WipeBlock", chain, currentBlockNum)
-		},
+	return services.ScanEventsOptions{
 		Chain:         chain,
 		ContractsName: contractsName,
-		SleepTime:     5,
 		GetCallbackFunc: func(tx string, blockTimestamp uint64, receipt *services.Receipts) interface{} {
 			return models.EthTransactionCallback{
 				Tx:             tx,
@@ -95,21 +197,17 @@ func startWipeTrxBlock(ctx context.Context, chain string, contractsMap util.Cont
 			}
 		},
 		CallbackMethodPrefix: util.Evo.ContractsListen,
-		InitBlock:            util.Evo.WipeBlock[chain].InitBlock,
-		RunForever:           true,
-		BeforePushMiddleware: []services.BeforePushFunc{
-			func(tx string, BlockTimestamp uint64, receipt *services.Receipts) bool {
-				ts := &models.TransactionScan{
-					Tx:             tx,
-					Chain:          chain,
-					BlockNumber:    cast.ToInt64(receipt.BlockNumber),
-					BlockTimestamp: int64(BlockTimestamp),
-				}
-				data, _ := jsoniter.Marshal(receipt.Logs)
-				ts.Logs = string(data)
-				ts.New(context.TODO())
-				return true
-			},
-		},
-	}))
+	}
+}
+
+func saveTransactionScan(chain, tx string, blockTimestamp uint64, receipt *services.Receipts) {
+	ts := &models.TransactionScan{
+		Tx:             tx,
+		Chain:          chain,
+		BlockNumber:    cast.ToInt64(receipt.BlockNumber),
+		BlockTimestamp: int64(blockTimestamp),
+	}
+	data, _ := jsoniter.Marshal(receipt.Logs)
+	ts.Logs = string(data)
+	ts.New(context.TODO())
 }
@@ -0,0 +1,35 @@
+package daemons
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+)
+
+// RetryParseTxErrors retry failed callbacks of the dead-letter queue when their backoff is up
+func RetryParseTxErrors(ctx context.Context) {
+	defer util.Recover("RetryParseTxErrors error")
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug("RetryParseTxErrors done")
+			return
+		case <-t.C:
+			start := time.Now()
+			for _, pe := range models.DueParseTxErrors(ctx, 100) {
+				if err := pe.Retry(ctx); errors.Is(err, models.ErrRetryInProgress) {
+					log.Debug("parse tx error %d %s", pe.ID, err)
+				}
+			}
+			metrics.Since(metrics.DaemonTickDuration, start, "RetryParseTxErrors")
+			models.MarkDaemonSuccess(ctx, "RetryParseTxErrors")
+		}
+	}
+}
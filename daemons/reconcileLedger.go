@@ -0,0 +1,46 @@
+package daemons
+
+import (
+	"context"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+	"github.com/spf13/cast"
+)
+
+// ReconcileLedger report ledger discrepancies every RECONCILE_LEDGER_INTERVAL hours, repair is left to the ReconcileLedger command
+func ReconcileLedger(ctx context.Context) {
+	defer util.Recover("ReconcileLedger error")
+	interval := cast.ToInt(util.GetEnv("RECONCILE_LEDGER_INTERVAL", "24"))
+	if interval <= 0 {
+		log.Debug("ReconcileLedger disabled")
+		return
+	}
+	t := time.NewTicker(time.Duration(interval) * time.Hour)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug("ReconcileLedger done")
+			return
+		case <-t.C:
+			util.OnceTask(ctx, "ReconcileLedger", interval*3600, func() {
+				defer metrics.Since(metrics.DaemonTickDuration, time.Now(), "ReconcileLedger")
+				report, err := models.ReconcileLedger(ctx, models.ReconcileOpt{})
+				if err != nil {
+					log.Error("ReconcileLedger error: %s", err)
+					return
+				}
+				log.Info("ReconcileLedger checked %d accounts %d withdraws %d events, discrepancies %v",
+					report.Accounts, report.Withdraws, report.Events, report.Count())
+				for _, d := range report.Discrepancies {
+					log.Warn("ReconcileLedger discrepancy %s", util.ToString(d))
+				}
+				models.MarkDaemonSuccess(ctx, "ReconcileLedger")
+			})
+		}
+	}
+}
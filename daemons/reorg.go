@@ -0,0 +1,193 @@
+package daemons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/services/storage"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+)
+
+// defaultSafeDepth used when wipeBlock.safeDepth of chain is not configured
+const defaultSafeDepth = 12
+
+func safeDepth(chain string) uint64 {
+	if depth := util.Evo.WipeBlock[chain].SafeDepth; depth > 0 {
+		return depth
+	}
+	return defaultSafeDepth
+}
+
+// scannedChain ChainIo of block scan remembering the hash of each block whose transactions it filtered
+type scannedChain struct {
+	storage.IStorage
+	hashes sync.Map // block number => hash
+}
+
+func newScannedChain(sg storage.IStorage) *scannedChain {
+	return &scannedChain{IStorage: sg}
+}
+
+func (s *scannedChain) FilterTrans(blockNum uint64, filter []string) (txn []string, contracts []string, timestamp uint64, transactionTo []string) {
+	bf, ok := s.IStorage.(storage.BlockFilter)
+	if !ok {
+		return s.IStorage.FilterTrans(blockNum, filter)
+	}
+	var hash string
+	hash, txn, contracts, timestamp, transactionTo = bf.FilterBlock(blockNum, filter)
+	if hash != "" {
+		s.hashes.Store(blockNum, hash)
+	}
+	return
+}
+
+// recordScanBlock save hashes of blocks scanned up to blockNum. Blocks not filtered by the scan, e.g. received from a
+// subscription, take the hash of the current header
+func recordScanBlock(ctx context.Context, chain string, sc *scannedChain, blockNum uint64) {
+	scanned := map[uint64]string{}
+	sc.hashes.Range(func(key, value interface{}) bool {
+		if n := key.(uint64); n <= blockNum {
+			scanned[n] = value.(string)
+			sc.hashes.Delete(key)
+		}
+		return true
+	})
+	if _, ok := scanned[blockNum]; !ok {
+		header := sc.BlockHeader(blockNum)
+		if header == nil || header.Hash == "" { // tron block header has no hash
+			return
+		}
+		scanned[blockNum] = header.Hash
+	}
+	for n, hash := range scanned {
+		if err := models.SaveScanBlock(ctx, chain, int64(n), hash); err != nil {
+			log.Error("%s save scan block %d error: %s", chain, n, err)
+		}
+	}
+}
+
+func startReorgCheck(ctx context.Context, chain string, contractsMap util.ContractAddress) {
+	interval := time.Duration(util.Evo.WipeBlock[chain].BlockTime) * time.Second
+	if interval <= 0 {
+		interval = time.Second * 5
+	}
+	sg := storage.New(chain)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Debug("%s ReorgCheck done", chain)
+			return
+		case <-t.C:
+			start := time.Now()
+			err := CheckReorg(ctx, chain, sg, contractsMap)
+			metrics.Since(metrics.DaemonTickDuration, start, fmt.Sprintf("ReorgCheck:%s", chain))
+			if err != nil {
+				log.Error("%s ReorgCheck error: %s", chain, err)
+				continue
+			}
+			models.MarkDaemonSuccess(ctx, fmt.Sprintf("ReorgCheck:%s", chain))
+		}
+	}
+}
+
+// CheckReorg compare recorded hashes of blocks within safe depth with chain,
+// roll back and re-derive the blocks from the first orphaned one
+func CheckReorg(ctx context.Context, chain string, sg storage.IStorage, contractsMap util.ContractAddress) error {
+	head := sg.BlockNumber()
+	if head == 0 {
+		return nil
+	}
+	var from int64
+	if depth := safeDepth(chain); head > depth {
+		from = int64(head - depth)
+	}
+	blocks := models.GetScanBlocks(ctx, chain, from)
+	if len(blocks) == 0 {
+		return nil
+	}
+	orphaned := int64(-1)
+	for _, block := range blocks {
+		if uint64(block.BlockNumber) > head {
+			orphaned = block.BlockNumber
+			break
+		}
+		header := sg.BlockHeader(uint64(block.BlockNumber))
+		if header == nil || header.Hash == "" {
+			return fmt.Errorf("get block %d header fail", block.BlockNumber)
+		}
+		if !strings.EqualFold(header.Hash, block.BlockHash) {
+			orphaned = block.BlockNumber
+			break
+		}
+	}
+	if err := models.PruneScanBlocks(ctx, chain, from); err != nil {
+		return err
+	}
+	if orphaned < 0 {
+		return nil
+	}
+
+	to := uint64(blocks[len(blocks)-1].BlockNumber)
+	if to > head {
+		to = head
+	}
+	log.Warn("%s reorg detected at block %d, re-derive blocks %d-%d", chain, orphaned, orphaned, to)
+	txs, err := models.RollbackOrphanedBlocks(ctx, chain, orphaned, sg)
+	if err != nil {
+		return err
+	}
+	log.Info("%s rolled back %d transactions of orphaned blocks", chain, len(txs))
+	return rederiveBlocks(ctx, chain, sg, contractsMap, uint64(orphaned), to)
+}
+
+// dispatchRederived dispatch a re-derived tx to the callback of contract, a failed callback is recorded in the
+// parse tx error queue as the worker does
+func dispatchRederived(ctx context.Context, ec *models.EthTransactionCallback, contractName string) {
+	chain, methodName := ec.Receipt.ChainSource, models.CallbackMethodName(contractName)
+	err := ec.Dispatch(ctx, contractName)
+	switch {
+	case err == nil:
+		ec.PublishCommitted(ctx, contractName)
+	case errors.Is(err, models.ErrCallbackNotFound), strings.EqualFold(err.Error(), "tx exist"):
+	default:
+		log.Error("%s re-derive tx %s call %s error: %s", chain, ec.Tx, methodName, err)
+		if err := models.RecordParseTxError(ctx, ec.Tx, chain, methodName, ec.BlockTimestamp, ec.Receipt, err); err != nil {
+			log.Error("record parse tx error fail %s. chain %s tx %s", err, chain, ec.Tx)
+		}
+		ec.PublishTxFail(ctx, contractName)
+	}
+}
+
+// rederiveBlocks scan canonical blocks again and dispatch their receipts to callbacks
+func rederiveBlocks(ctx context.Context, chain string, sg storage.IStorage, contractsMap util.ContractAddress, from, to uint64) error {
+	var filter []string
+	for address := range contractsMap {
+		filter = append(filter, strings.ToLower(address))
+	}
+	sc := newScannedChain(sg)
+	for n := from; n <= to; n++ {
+		txs, _, timestamp, _ := sc.FilterTrans(n, filter)
+		for _, tx := range txs {
+			receipt, err := sg.ReceiptLog(tx)
+			if err != nil || receipt == nil {
+				return fmt.Errorf("get receipt of %s fail, err: %v", tx, err)
+			}
+			saveTransactionScan(chain, tx, timestamp, receipt)
+			ec := &models.EthTransactionCallback{Tx: tx, Receipt: receipt, BlockTimestamp: int64(timestamp)}
+			for _, contractName := range models.ListenContractNames(chain, receipt) {
+				dispatchRederived(ctx, ec, contractName)
+			}
+		}
+		recordScanBlock(ctx, chain, sc, n)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package daemons
+
+import (
+	"context"
+	"testing"
+
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/services/storage"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckReorg(t *testing.T) {
+	assert.NoError(t, util.InitRedis())
+	assert.NoError(t, models.MigrationDbTable())
+	ctx := context.TODO()
+	sg := storage.NewSimChain(storage.Sim)
+	tx := sg.EmitLogs("0x0000000000000000000000000000000000000abc")
+	head := sg.AdvanceBlocks(2)
+	sc := newScannedChain(sg)
+	for n := uint64(0); n <= head.Number; n++ {
+		sc.FilterTrans(n, nil)
+	}
+	// a reorg between scan and record does not replace the hash of the scanned block
+	sg.Reorg(1, false)
+	recordScanBlock(ctx, storage.Sim, sc, head.Number)
+	assert.NotEqual(t, sg.BlockHeader(head.Number).Hash, models.GetScanBlocks(ctx, storage.Sim, int64(head.Number))[0].BlockHash)
+	assert.NoError(t, CheckReorg(ctx, storage.Sim, sg, nil))
+	for n := uint64(0); n <= head.Number; n++ {
+		sc.FilterTrans(n, nil)
+	}
+	recordScanBlock(ctx, storage.Sim, sc, head.Number)
+	saveTransactionScan(storage.Sim, tx, head.Timestamp, &services.Receipts{BlockNumber: util.IntToHex(head.Number - 2)})
+	assert.NoError(t, CheckReorg(ctx, storage.Sim, sg, nil))
+	assert.Len(t, models.GetScanBlocks(ctx, storage.Sim, 0), 4)
+
+	sg.Reorg(3, true)
+	assert.NoError(t, CheckReorg(ctx, storage.Sim, sg, nil))
+	var count int
+	util.WithContextDb(ctx).Model(models.TransactionScan{}).Where("chain = ? AND tx = ?", storage.Sim, tx).Count(&count)
+	assert.Equal(t, 0, count)
+	for _, block := range models.GetScanBlocks(ctx, storage.Sim, 0) {
+		assert.Equal(t, sg.BlockHeader(uint64(block.BlockNumber)).Hash, block.BlockHash)
+	}
+}
@@ -4,19 +4,21 @@ import (
 	"context"
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
 	"time"
 )
 
 func StartSnapshot(ctx context.Context) {
-	chains := []string{
-		models.CrabChain,
-		models.EthChain,
-		models.PolygonChain,
-		models.TronChain,
-	}
-
-	for _, chain := range chains {
-		go util.ScheduledTask(ctx, models.SaveSnapshot(ctx, chain), time.Minute*2)
+	for _, chain := range util.Evo.Networks {
+		if util.Evo.SkipSnapshot[chain] {
+			continue
+		}
+		save := models.SaveSnapshot(ctx, chain)
+		go util.ScheduledTask(ctx, func() {
+			defer metrics.Since(metrics.DaemonTickDuration, time.Now(), "Snapshot")
+			save()
+			models.MarkDaemonSuccess(ctx, "Snapshot")
+		}, time.Minute*2)
 	}
 
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
@@ -30,6 +31,7 @@ func FreshSwapStatus(ctx context.Context) {
 			log.Debug("FreshSwapStatus done")
 			return
 		case <-t.C:
+			start := time.Now()
 			span, spanCtx := tracer.StartSpanFromContext(ctx, "daemons.worker",
 				tracer.ServiceName("evo-backend-worker"),
 				tracer.SpanType(ext.SpanTypeMessageConsumer),
@@ -66,6 +68,8 @@ func FreshSwapStatus(ctx context.Context) {
 				_ = tx.UpdateSwapTx(spanCtx, int(confirmationBlock), chain)
 			}
 			span.Finish()
+			metrics.Since(metrics.DaemonTickDuration, start, "FreshSwapStatus")
+			models.MarkDaemonSuccess(ctx, "FreshSwapStatus")
 			t.Reset(time.Second * 5)
 		}
 	}
@@ -3,32 +3,37 @@ package daemons
 import (
 	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
-	"reflect"
 	"strings"
+	"time"
 
 	"github.com/evolutionlandorg/block-scan/services"
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/util"
 	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
 	"github.com/itering/go-workers"
 	"github.com/spf13/cast"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
-func RunWorker() {
+// RunWorker registers processors of queues, all <chain>Process queues if queues is empty. workers.Start starts them
+func RunWorker(queues []string) {
 	processCount := cast.ToInt(util.GetEnv("WORKER_PROCESS_COUNT", "10"))
 	if processCount == 0 {
 		processCount = 10
 	}
-	workers.Process("ethProcess", ethProcess, processCount)
-	workers.Process("tronProcess", tronProcess, processCount)
-	workers.Process("crabProcess", crabProcess, processCount)
-	workers.Process("hecoProcess", crabProcess, processCount)
-	workers.Process("bscProcess", crabProcess, processCount)
-	workers.Process("polygonProcess", crabProcess, processCount)
-	workers.Run()
+	// block scan pushes receipts of chain to queue <chain>Process, e.g. ethProcess
+	if len(queues) == 0 {
+		for _, chain := range util.Evo.Networks {
+			queues = append(queues, fmt.Sprintf("%sProcess", strings.ToLower(chain)))
+		}
+	}
+	for _, queue := range queues {
+		workers.Process(queue, chainProcess, processCount)
+	}
 }
 
 type ChainPayload struct {
@@ -45,9 +50,9 @@ func ethProcess(m *workers.Msg) {
 	util.UnmarshalAny(&payload, args)
 
 	ecInstant := &models.EthTransactionCallback{Tx: payload.Tx, Receipt: payload.Receipts, BlockTimestamp: payload.BlockTimestamp}
-	wReflect := reflect.ValueOf(&ecInstant).Elem()
 	deal := func() {
-		methodName := fmt.Sprintf("%sCallback", payload.ContractName)
+		defer metrics.Since(metrics.WorkerDuration, time.Now(), fmt.Sprintf("%sProcess", strings.ToLower(payload.Chain)))
+		methodName := models.CallbackMethodName(payload.ContractName)
 		span, ctx := tracer.StartSpanFromContext(context.TODO(), "daemons.worker",
 			tracer.ServiceName("evo-backend-worker"),
 			tracer.SpanType(ext.SpanTypeMessageConsumer),
@@ -55,38 +60,44 @@ func ethProcess(m *workers.Msg) {
 			tracer.Tag("worker-name", methodName),
 		)
 		defer span.Finish()
-		methodFunc := wReflect.MethodByName(methodName)
-		if !methodFunc.IsValid() {
+		log.Debug("%s %s block tx %s call %s", payload.Chain, payload.Receipts.BlockNumber, payload.Tx, methodName)
+		err := ecInstant.Dispatch(ctx, payload.ContractName)
+		if errors.Is(err, models.ErrCallbackNotFound) {
 			log.Warn("%s not found method %s", payload.Chain, methodName)
 			return
 		}
-		log.Debug("%s %s block tx %s call %s", payload.Chain, payload.Receipts.BlockNumber, payload.Tx, methodName)
-		res := methodFunc.Call([]reflect.Value{reflect.ValueOf(ctx)})
-		if v := res[0].Interface(); v != nil {
-			if err, ok := v.(error); ok && !strings.EqualFold(err.Error(), "tx exist") {
-				log.Error("Process error %s. chain %s tx %s", err, payload.Chain, payload.Tx)
-				util.WithContextDb(ctx).Create(&models.ParseTxError{
-					Tx:        payload.Tx,
-					Chain:     payload.Chain,
-					Error:     err.Error(),
-					ParseFunc: methodName,
-					Receipts:  util.ToString(payload.Receipts),
-				})
-				return
+		// only live txs are counted, not the ones dispatched again by replay, reorg or retry
+		metrics.CallbackTotal.WithLabelValues(methodName, payload.Chain, callbackResult(err)).Inc()
+		if err != nil && !strings.EqualFold(err.Error(), "tx exist") {
+			log.Error("Process error %s. chain %s tx %s", err, payload.Chain, payload.Tx)
+			if err := models.RecordParseTxError(ctx, payload.Tx, payload.Chain, methodName, payload.BlockTimestamp, payload.Receipts, err); err != nil {
+				log.Error("record parse tx error fail %s. chain %s tx %s", err, payload.Chain, payload.Tx)
 			}
+			ecInstant.PublishTxFail(ctx, payload.ContractName)
+			return
+		}
+		if err == nil {
+			ecInstant.PublishCommitted(ctx, payload.ContractName)
 		}
+		models.MarkDaemonSuccess(ctx, "Worker")
 	}
 
 	key := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s:%s", payload.Tx, payload.ContractName))))
 	util.OnceTask(context.TODO(), fmt.Sprintf("ethProcess:%s", key), 5, deal)
 }
 
-func tronProcess(m *workers.Msg) {
-	defer util.Recover("tronProcess error")
-	ethProcess(m)
+// callbackResult result label of metrics.CallbackTotal
+func callbackResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case strings.EqualFold(err.Error(), "tx exist"):
+		return "skip"
+	}
+	return "fail"
 }
 
-func crabProcess(m *workers.Msg) {
-	defer util.Recover("crabProcess error")
+func chainProcess(m *workers.Msg) {
+	defer util.Recover("chainProcess error")
 	ethProcess(m)
 }
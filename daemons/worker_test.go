@@ -1,12 +1,16 @@
 package daemons
 
 import (
+	"context"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/evolutionlandorg/evo-backend/config"
 	"github.com/evolutionlandorg/evo-backend/util"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/itering/go-workers"
 	"github.com/stretchr/testify/assert"
 )
@@ -109,3 +113,33 @@ func Test_apostlePro(t *testing.T) {
 	}}))
 	ethProcess(m)
 }
+
+func newTestRedis(t *testing.T) *miniredis.Miniredis {
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_HOST", mr.Host())
+	t.Setenv("REDIS_PORT", mr.Port())
+	assert.NoError(t, util.InitRedis())
+	assert.NoError(t, util.InitWorkers())
+	return mr
+}
+
+func TestStartWorkerQuit(t *testing.T) {
+	newTestRedis(t)
+	started := make(chan struct{})
+	var finished int32
+	workers.Process("quitTest", func(m *workers.Msg) {
+		close(started)
+		time.Sleep(500 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	StartWorker(ctx, []string{"testProcess"})
+	_, err := workers.Enqueue("quitTest", "quitTest", nil)
+	assert.NoError(t, err)
+
+	// shutdown while the callback is in flight waits it to finish
+	<-started
+	cancel()
+	assert.True(t, Wait(5*time.Second))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
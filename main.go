@@ -66,47 +66,144 @@ func setupApp() *cli.App {
 		Name:  "EVOLUTION LAND",
 		Usage: "Evolution.land Backend",
 		Action: func(c *cli.Context) error {
-			server := &http.Server{
-				Addr:    util.GetEnv("PORT", ":2333"),
-				Handler: setupRouter(),
+			if cast.ToBool(util.GetEnv("DISABLE_DAEMONS", "false")) {
+				return serve(setupRouter(), nil)
 			}
-			if !cast.ToBool(util.GetEnv("DISABLE_DAEMONS", "false")) {
+			return serve(setupRouter(), func(ctx context.Context) error {
 				daemons.Start(ctx)
-			}
-
-			go func() {
-				if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-					log.Fatal("listen error: %s", err)
-				}
-			}()
-
-			quit := make(chan os.Signal, 1)
-			signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-			<-quit
-			cancel()
-			_ = server.Shutdown(context.TODO())
-			time.Sleep(time.Second * 5)
-			return nil
+				return nil
+			})
 		},
 		Version:  "1.0",
-		Commands: commands.SubAction,
+		Commands: append(roleCommands, commands.SubAction...),
+	}
+}
+
+// roleCommands run a single role of the backend, so each role can be deployed and scaled on its own
+var roleCommands = []cli.Command{
+	{
+		Name:  "serve-api",
+		Usage: "serve http api only",
+		Action: func(c *cli.Context) error {
+			return serve(setupRouter(), nil)
+		},
+	},
+	{
+		Name:  "scan",
+		Usage: "scan blocks of chains and check reorg, each chain runs on the instance holding its lease",
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "chain",
+				Usage: "chains to scan, eg: Crab,Heco, default all chains",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			chains := splitFlag(c.StringSlice("chain"))
+			return serve(setupOpsRouter(), func(ctx context.Context) error {
+				return daemons.StartScanner(ctx, chains)
+			})
+		},
+	},
+	{
+		Name:  "work",
+		Usage: "process callbacks of worker queues",
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "queues",
+				Usage: "queues to process, eg: crabProcess,hecoProcess, default all <chain>Process queues",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			queues := splitFlag(c.StringSlice("queues"))
+			return serve(setupOpsRouter(), func(ctx context.Context) error {
+				daemons.StartWorker(ctx, queues)
+				return nil
+			})
+		},
+	},
+	{
+		Name:  "schedule",
+		Usage: "run scheduled daemons, singletons run on the instance holding their lease",
+		Action: func(c *cli.Context) error {
+			return serve(setupOpsRouter(), func(ctx context.Context) error {
+				daemons.StartScheduler(ctx)
+				return nil
+			})
+		},
+	},
+}
+
+func splitFlag(values []string) []string {
+	var items []string
+	for _, v := range values {
+		items = append(items, util.RemoveEmptyStrings(strings.Split(v, ","))...)
 	}
+	return items
+}
+
+// serve serves handler and runs start until SIGINT or SIGTERM. On shutdown it stops accepting requests and
+// waits in-flight requests, callbacks and daemon ticks to finish, at most SHUTDOWN_TIMEOUT seconds
+func serve(handler http.Handler, start func(ctx context.Context) error) error {
+	server := &http.Server{Addr: util.GetEnv("PORT", ":2333"), Handler: handler}
+	if start != nil {
+		if err := start(ctx); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("listen error: %s", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	cancel()
+	timeout := time.Duration(cast.ToInt(util.GetEnv("SHUTDOWN_TIMEOUT", "30"))) * time.Second
+	shutdownCtx, done := context.WithTimeout(context.Background(), timeout)
+	defer done()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Warn("shutdown server error: %s", err)
+	}
+	deadline, _ := shutdownCtx.Deadline()
+	if !daemons.Wait(time.Until(deadline)) {
+		log.Warn("shutdown timeout, exit with daemons running")
+	}
+	return nil
+}
+
+// setupOpsRouter probes and metrics of roles serving no api
+func setupOpsRouter() (server *gin.Engine) {
+	server = gin.New()
+	server.Use(middlewares.Recovery(), middlewares.Metrics())
+	server.GET("/healthz", routes.Healthz())
+	server.GET("/readyz", routes.Readyz())
+	server.GET("/metrics", routes.Metrics())
+	return
 }
 
 func setupRouter() (server *gin.Engine) {
 	server = gin.New()
 	server.Use(middlewares.Recovery(),
+		middlewares.RequestId(),
+		middlewares.Metrics(),
 		gintrace.Middleware("EVO-BACKEND", gintrace.WithAnalytics(true)),
 		middlewares.CORS(),
 		middlewares.Logger())
 
 	server.MaxMultipartMemory = 3 << 20
-	server.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"code": 10000,
-			"msg":  "not found",
-		})
-	})
+	// client ip of rate limit is taken from X-Forwarded-For only if the request comes from these proxies
+	if proxies := util.GetEnv("TRUSTED_PROXIES", ""); proxies != "" {
+		if err := server.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+			log.Fatal("trusted proxies %s error: %s", proxies, err)
+		}
+	}
+	server.NoRoute(routes.NoRoute())
+	server.GET("/healthz", routes.Healthz())
+	server.GET("/readyz", routes.Readyz())
+	server.GET("/metrics", routes.Metrics())
 	server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	server.GET("/apostle/:genes", func(ctx *gin.Context) {
 		apostlePictureFilePath := filepath.Join(util.ApostlePictureDir, ctx.Param("genes"))
@@ -147,5 +244,7 @@ func setupRouter() (server *gin.Engine) {
 	})
 	api := routes.ApiHandle{RouterGroup: server.Group("/api")}
 	api.StartHttpApi()
+	v1 := routes.ApiHandle{RouterGroup: server.Group(routes.ApiV1Prefix), ErrorEnvelope: true}
+	v1.StartHttpApi()
 	return
 }
@@ -7,8 +7,8 @@ func CORS() gin.HandlerFunc {
 		context.Writer.Header().Add("Access-Control-Allow-Origin", "*")
 		context.Writer.Header().Set("Access-Control-Max-Age", "86400")
 		context.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, EVO-AUTH, EVO-NETWORK")
-		context.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
+		context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, EVO-AUTH, EVO-NETWORK, EVO-ADMIN-TOKEN, EVO-API-KEY, X-Request-Id")
+		context.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, X-Request-Id, Retry-After")
 		context.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if context.Request.Method == "OPTIONS" {
@@ -24,13 +24,14 @@ func Logger() gin.HandlerFunc {
 				params = data
 			}
 		}
-		msg := fmt.Sprintf("%v -- %v -- %v -- %s -- %s -- %s",
+		msg := fmt.Sprintf("%v -- %v -- %v -- %s -- %s -- %s -- %s",
 			c.Writer.Status(),
 			latency,
 			c.Request.Method,
 			c.Request.URL.Path,
 			params,
-			c.Request.Header.Get("EVO-NETWORK"))
+			c.Request.Header.Get("EVO-NETWORK"),
+			c.GetString("RequestId"))
 		log.Info(msg)
 
 	}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics observe latency of requests by route template, requests without route are counted as "unmatched"
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.Since(metrics.HttpRequestDuration, start, route, c.Request.Method, strconv.Itoa(c.Writer.Status()))
+	}
+}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/satori/go.uuid"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+// RequestId keep the request id from client or proxy, otherwise generate one.
+// It is set as RequestId of context and echoed in the response header
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIdHeader)
+		if id == "" || len(id) > 64 {
+			id = uuid.Must(uuid.NewV4(), nil).String()
+		}
+		c.Set("RequestId", id)
+		c.Header(RequestIdHeader, id)
+		c.Next()
+	}
+}
@@ -3,10 +3,9 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 )
 
 func (ec *EthTransactionCallback) ItemBaseCallback(ctx context.Context) (err error) {
@@ -17,45 +16,26 @@ func (ec *EthTransactionCallback) ItemBaseCallback(ctx context.Context) (err err
 	txn := util.DbBegin(ctx)
 	defer txn.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("ItemBase", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-
-			// indexed user, indexed tokenId, index, rate, objClassExt, class, grade, prefer, major, id, minor, amount, now
-			case services.AbiEncodingMethod("Enchanced(address,uint256,uint256,uint128,uint16,uint16,uint16,uint16,address,uint256,address,uint256,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				address := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				tokenId := util.TrimHex(log.Topics[2])
-				formulaIndex := util.StringToInt(util.U256(logSlice[0]).String())
-
-				objClassExt := util.StringToInt(util.U256(logSlice[2]).String())
-				class := util.StringToInt(util.U256(logSlice[3]).String())
-				grade := util.StringToInt(util.U256(logSlice[4]).String())
-				prefer := util.StringToInt(util.U256(logSlice[5]).String())
-				createTime := util.StringToInt(util.U256(logSlice[10]).String())
-
-				err := CreateDrill(txn, address, tokenId, createTime, class, grade, prefer, formulaIndex, objClassExt, chain)
-
-				if err != nil {
-					return err
-				}
-				// history record
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, Action: DrillEnchanced, TokenId: tokenId}
-				_ = th.New(txn)
-			// indexed user,tokenId,address,major, id, minor, amount
-			case services.AbiEncodingMethod("Disenchanted(address,uint256,address,uint256,address,uint256)"):
-
-				logSlice := util.LogAnalysis(log.Data)
-				address := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				tokenId := util.TrimHex(logSlice[0])
-				// history record
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, Action: DrillDisenchanted, TokenId: tokenId}
-				_ = th.New(txn)
+	err = ec.eachEvent(util.GetContractAddress("ItemBase", chain),
+		events.On(func(ev *ItemEnchanced) error {
+			address := eventAddress(ev.User, chain)
+			tokenId := eventTokenId(ev.TokenId)
+			err := CreateDrill(txn, address, tokenId, int(ev.Now.Int64()), int(ev.Class), int(ev.Grade), int(ev.Prefer), int(ev.Index.Int64()), int(ev.ObjClassExt), chain)
+			if err != nil {
+				return err
 			}
-		}
-	}
-
+			// history record
+			th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, Action: DrillEnchanced, TokenId: tokenId}
+			_ = th.New(txn)
+			return nil
+		}),
+		events.On(func(ev *ItemDisenchanted) error {
+			// history record
+			th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: eventAddress(ev.User, chain), Action: DrillDisenchanted, TokenId: eventTokenId(ev.TokenId)}
+			_ = th.New(txn)
+			return nil
+		}),
+	)
 	if err != nil {
 		return err
 	}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"math/big"
 	"time"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/shopspring/decimal"
 )
@@ -109,91 +109,71 @@ func (ec *EthTransactionCallback) LandResourceCallback(ctx context.Context) (err
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("landResource", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			// event StartMining(uint256 minerTokenId, uint256 landTokenId, address _resource, uint256 strength);
-			case services.AbiEncodingMethod("StartMining(uint256,uint256,address,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				tokenId := logSlice[0]
-				apostle := GetApostleByTokenId(ctx, tokenId)
-				db.Model(&apostle).UpdateColumn("status", apostleWorking)
-				land := getLand(ctx, logSlice[1])
-				currencyMap := util.GetContractsMap(chain)
-				lp := LandApostle{LandId: land.ID, ApostleId: apostle.ID, DigElement: currencyMap[strings.ToLower(util.AddHex(logSlice[2][24:64], chain))], Strength: util.BigToDecimal(util.U256(logSlice[3]))}
-				_ = lp.new(ctx)
-				apostle.startHabergerMode(db)
-			case services.AbiEncodingMethod("StopMining(uint256,uint256,address,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				tokenId := logSlice[0]
-				apostle := GetApostleByTokenId(ctx, tokenId)
-				status := apostleFresh
-				if auction := GetApostleWork(ctx, tokenId, AuctionFinish); auction != nil { // 在租赁中，状态改为Hiring
-					status = apostleHiring
-				}
-				db.Model(&apostle).Where("status = ?", apostleWorking).UpdateColumn("status", status)
-				land := getLand(ctx, logSlice[1])
-				currencyMap := util.GetContractsMap(chain)
-				lp := LandApostle{LandId: land.ID, ApostleId: apostle.ID, DigElement: currencyMap[strings.ToLower(util.AddHex(logSlice[2][24:64], chain))]}
-				db.Table("land_apostles").Where(lp).Delete(&LandApostle{})
-			case services.AbiEncodingMethod("ResourceClaimed(address,uint256,uint256,uint256,uint256,uint256,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				address := util.AddHex(util.TrimHex(logSlice[0])[24:64], chain)
-				tokenId := logSlice[1]
-				land := GetLandByTokenId(ctx, tokenId)
-				goldBalance := util.BigToDecimal(util.U256(logSlice[2]))
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, BalanceChange: goldBalance, Currency: currencyGold, Action: TransactionHistoryClaimResource, TokenId: tokenId, Coordinate: fmt.Sprintf("%d,%d", land.Lon, land.Lat)}
-				if goldBalance.Sign() > 0 {
-					_ = th.New(db)
-				}
-				woodBalance := util.BigToDecimal(util.U256(logSlice[3]))
-				if woodBalance.Sign() > 0 {
-					th.BalanceChange = woodBalance
-					th.Currency = currencyWood
-					_ = th.New(db)
-				}
-				waterBalance := util.BigToDecimal(util.U256(logSlice[4]))
-				if waterBalance.Sign() > 0 {
-					th.BalanceChange = waterBalance
-					th.Currency = currencyWater
-					_ = th.New(db)
-				}
-				fireBalance := util.BigToDecimal(util.U256(logSlice[5]))
-				if fireBalance.Sign() > 0 {
-					th.BalanceChange = fireBalance
-					th.Currency = currencyFire
-					_ = th.New(db)
-				}
-				soilBalance := util.BigToDecimal(util.U256(logSlice[6]))
-				if soilBalance.Sign() > 0 {
-					th.BalanceChange = soilBalance
-					th.Currency = currencySoil
+	currencyMap := util.GetContractsMap(chain)
+	err = ec.eachEvent(util.GetContractAddress("landResource", chain),
+		events.On(func(ev *StartMining) error {
+			tokenId := eventTokenId(ev.MinerTokenId)
+			apostle := GetApostleByTokenId(ctx, tokenId)
+			db.Model(&apostle).UpdateColumn("status", apostleWorking)
+			land := getLand(ctx, eventTokenId(ev.LandId))
+			lp := LandApostle{LandId: land.ID, ApostleId: apostle.ID, DigElement: currencyMap[eventAddress(ev.Resource, chain)], Strength: util.BigToDecimal(ev.Strength)}
+			_ = lp.new(ctx)
+			apostle.startHabergerMode(db)
+			return nil
+		}),
+		events.On(func(ev *StopMining) error {
+			tokenId := eventTokenId(ev.MinerTokenId)
+			apostle := GetApostleByTokenId(ctx, tokenId)
+			status := apostleFresh
+			if auction := GetApostleWork(ctx, tokenId, AuctionFinish); auction != nil { // 在租赁中，状态改为Hiring
+				status = apostleHiring
+			}
+			db.Model(&apostle).Where("status = ?", apostleWorking).UpdateColumn("status", status)
+			land := getLand(ctx, eventTokenId(ev.LandId))
+			lp := LandApostle{LandId: land.ID, ApostleId: apostle.ID, DigElement: currencyMap[eventAddress(ev.Resource, chain)]}
+			db.Table("land_apostles").Where(lp).Delete(&LandApostle{})
+			return nil
+		}),
+		events.On(func(ev *ResourceClaimed) error {
+			tokenId := eventTokenId(ev.LandTokenId)
+			land := GetLandByTokenId(ctx, tokenId)
+			th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: eventAddress(ev.Owner, chain), Action: TransactionHistoryClaimResource, TokenId: tokenId, Coordinate: fmt.Sprintf("%d,%d", land.Lon, land.Lat)}
+			for _, claimed := range []struct {
+				currency string
+				balance  *big.Int
+			}{
+				{currencyGold, ev.GoldBalance},
+				{currencyWood, ev.WoodBalance},
+				{currencyWater, ev.WaterBalance},
+				{currencyFire, ev.FireBalance},
+				{currencySoil, ev.SoilBalance},
+			} {
+				if balance := util.BigToDecimal(claimed.balance); balance.Sign() > 0 {
+					th.BalanceChange = balance
+					th.Currency = claimed.currency
 					_ = th.New(db)
 				}
-			case services.AbiEncodingMethod("UpdateMiningStrengthWhenStart(uint256,uint256,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				tokenId := logSlice[0]
-				land := getLand(ctx, logSlice[1])
-				apostle := GetApostleByTokenId(ctx, tokenId)
-				lp := LandApostle{LandId: land.ID, ApostleId: apostle.ID}
-				db.Table("land_apostles").Where(lp).UpdateColumn(LandApostle{Strength: util.BigToDecimal(util.U256(logSlice[2]))})
-				// index tokenId, resource, index, staker, token, id
-			case services.AbiEncodingMethod("Equip(uint256,address,uint256,address,address,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				landTokenId := util.TrimHex(log.Topics[1])
-				index := util.StringToInt(util.U256(logSlice[1]).String())
-				address := util.AddHex(logSlice[2][24:64], chain)
-				drillTokenId := logSlice[4]
-				resource := util.AddHex(logSlice[0][24:64], chain)
-				db.Delete(LandEquip{}, "land_token_id = ? and `index` = ?", landTokenId, index)
-				err = CreateLandEquip(ctx, db, chain, landTokenId, index, address, drillTokenId, resource, ec.BlockTimestamp)
-			case services.AbiEncodingMethod("Divest(uint256,address,uint256,address,address,uint256)"):
-				logSlice := util.LogAnalysis(log.Data)
-				LandEquipRemove(ctx, logSlice[4])
 			}
-		}
-	}
+			return nil
+		}),
+		events.On(func(ev *UpdateMiningStrengthWhenStart) error {
+			land := getLand(ctx, eventTokenId(ev.LandId))
+			apostle := GetApostleByTokenId(ctx, eventTokenId(ev.ApostleTokenId))
+			lp := LandApostle{LandId: land.ID, ApostleId: apostle.ID}
+			db.Table("land_apostles").Where(lp).UpdateColumn(LandApostle{Strength: util.BigToDecimal(ev.Strength)})
+			return nil
+		}),
+		events.On(func(ev *Equip) error {
+			landTokenId := eventTokenId(ev.TokenId)
+			index := int(ev.Index.Int64())
+			db.Delete(LandEquip{}, "land_token_id = ? and `index` = ?", landTokenId, index)
+			return CreateLandEquip(ctx, db, chain, landTokenId, index, eventAddress(ev.Staker, chain), eventTokenId(ev.Id), eventAddress(ev.Resource, chain), ec.BlockTimestamp)
+		}),
+		events.On(func(ev *Divest) error {
+			LandEquipRemove(ctx, eventTokenId(ev.Id))
+			return nil
+		}),
+	)
 	if err != nil {
 		return
 	}
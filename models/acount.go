@@ -20,6 +20,61 @@ type Account struct {
 	Balance  decimal.Decimal `json:"balance" sql:"type:decimal(32,16); default:0"`
 }
 
+type AccountJson struct {
+	Id       uint            `json:"id"`
+	Currency string          `json:"currency"`
+	Wallet   string          `json:"wallet"`
+	Chain    string          `json:"chain"`
+	Balance  decimal.Decimal `json:"balance"`
+}
+
+func (a *Account) AsJson() AccountJson {
+	return AccountJson{Id: a.ID, Currency: a.Currency, Wallet: a.Wallet, Chain: a.Chain, Balance: a.Balance}
+}
+
+// Accounts accounts of member and its wallets, currency and chain filter when not empty
+func (m *Member) Accounts(ctx context.Context, currency, chain string) []Account {
+	var wallets []string
+	for _, wallet := range []string{m.Wallet, m.TronWallet} {
+		if wallet != "" {
+			wallets = append(wallets, wallet)
+		}
+	}
+	db := util.WithContextDb(ctx)
+	if m.ID > 0 {
+		db = db.Where("member_id = ? OR wallet in (?)", m.ID, wallets)
+	} else {
+		db = db.Where("wallet in (?)", wallets)
+	}
+	if currency != "" {
+		db = db.Where("currency = ?", currency)
+	}
+	if chain != "" {
+		db = db.Where("chain = ?", chain)
+	}
+	var accounts []Account
+	db.Order("id asc").Find(&accounts)
+	return accounts
+}
+
+// Ledger balance changes of accounts, ledger of accounts out of them is not visible
+func (avq *AvQuery) Ledger(ctx context.Context, accounts []Account) ([]AccountVersionJson, int) {
+	chains := make(map[uint]string)
+	var ids []uint
+	for _, account := range accounts {
+		if avq.WhereQuery.AccountId != 0 && avq.WhereQuery.AccountId != account.ID {
+			continue
+		}
+		chains[account.ID] = account.Chain
+		ids = append(ids, account.ID)
+	}
+	avs, count := avq.GetHistory(ctx, ids)
+	for i := range avs {
+		avs[i].Chain = chains[avs[i].AccountId]
+	}
+	return avs, count
+}
+
 func (a *Account) AddBalance(db *util.GormDB, amount decimal.Decimal, reason string) error {
 	if a != nil && amount.IsPositive() {
 		if query := db.Model(a).Where("balance=?", a.Balance).Update(map[string]interface{}{"balance": gorm.Expr("balance + ?", amount)}); query == nil || query.RowsAffected == 0 {
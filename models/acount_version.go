@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"regexp"
 	"strings"
 	"time"
 
@@ -24,10 +25,13 @@ type AccountVersion struct {
 
 type AccountVersionJson struct {
 	CreatedAt time.Time       `json:"created_at"`
+	AccountId uint            `json:"account_id"`
 	Balance   decimal.Decimal `json:"balance" sql:"type:decimal(32,16);"`
 	Reason    string          `json:"reason"`
 	Remark    string          `json:"remark"`
 	Currency  string          `json:"currency"`
+	Chain     string          `json:"chain"`
+	Tx        string          `json:"tx"` // originating tx, remark of reasons caused by tx
 }
 
 type AvQuery struct {
@@ -42,34 +46,40 @@ type VersionQuery struct {
 	Remark    string `json:"remark" table_name:"account_versions"`
 }
 
+var txHashRegex = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{64}$`)
+
 func (av *AccountVersion) New(db *util.GormDB) error {
 	result := db.Create(&av)
 	return result.Error
 }
 
+func (avq *AvQuery) where(ctx context.Context, accountId []uint) *gorm.DB {
+	db := util.WithContextDb(ctx).Table("account_versions")
+	wheres, values := util.StructToSql(avq.WhereQuery)
+	if len(wheres) != 0 {
+		db = db.Where(strings.Join(wheres, " AND "), values...)
+	}
+	return db.Where("account_id in (?)", accountId)
+}
+
 func (avq *AvQuery) GetHistory(ctx context.Context, AccountId []uint) ([]AccountVersionJson, int) {
 	if len(AccountId) == 0 {
 		return nil, 0
 	}
-	db := util.WithContextDb(ctx).Table("account_versions")
 	var avs []AccountVersionJson
 	var count int
-	wheres, values := util.StructToSql(avq.WhereQuery)
-	if len(wheres) != 0 {
-		db = db.Where(strings.Join(wheres, "AND"), values...)
-	}
-	query := db.Where("account_id in (?)", AccountId).
+	query := avq.where(ctx, AccountId).
 		Offset(avq.Page * avq.Row).Limit(avq.Row).
 		Order("id desc").
 		Scan(&avs)
 	if query.Error != nil || query == nil || query.RecordNotFound() {
 		return nil, 0
 	}
-	db = db.Table("account_versions")
-	if len(wheres) != 0 {
-		db = db.Where(strings.Join(wheres, "AND"), values...)
+	avq.where(ctx, AccountId).Count(&count)
+	for i := range avs {
+		if txHashRegex.MatchString(avs[i].Remark) {
+			avs[i].Tx = avs[i].Remark
+		}
 	}
-	db.Where(avq.WhereQuery).Where("account_id in (?)", AccountId).
-		Count(&count)
 	return avs, count
 }
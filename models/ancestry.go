@@ -30,6 +30,14 @@ func (m *Member) ancestorIds() []string {
 	return ancestry
 }
 
+// childAncestry ancestry of member referred by m
+func (m *Member) childAncestry() string {
+	if m.Ancestry == "" {
+		return fmt.Sprintf("%d", m.ID)
+	}
+	return fmt.Sprintf("%s/%d", m.Ancestry, m.ID)
+}
+
 func (m *Member) childrenIds(ctx context.Context) []string {
 	if m == nil {
 		return nil
@@ -14,8 +14,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 
 	randomdata "github.com/Pallinder/go-randomdata"
 	"github.com/jinzhu/gorm"
@@ -160,6 +160,9 @@ type ApostleQuery struct {
 
 var (
 	ApostlesOrder = []string{"price", "gen", "id", "token_index", "atk", "crit", "def", "hp_limit", "occupational", "mining_power"}
+	// talent columns of apostle_talents filtered by talent[column]=min
+	apostleTalentFilter = []string{"life", "mood", "strength", "agile", "finesse", "hp", "intellect", "lucky", "potential", "charm",
+		"skills", "mining_power", "atk", "crit", "def", "hp_limit"}
 )
 
 const (
@@ -415,9 +418,7 @@ func (apq *ApostleQuery) Apostles(ctx context.Context, occupational []string) (*
 	// first need filter
 	priceMap, tokenMap := ApostlePriceCache(ctx, apq.Chain)
 	tokenQuery, tokenIndex, needFilter := apq.multiFilterApostle(ctx, priceMap)
-	for _, w := range tokenQuery {
-		query = query.Where(w)
-	}
+	query = tokenQuery.Apply(query)
 	if needFilter {
 		if len(tokenIndex) == 0 {
 			return nil, 0
@@ -482,15 +483,15 @@ func (apq *ApostleQuery) AllApostles(ctx context.Context, occupational []string)
 		db = db.Where("occupational IN (?)", occupational)
 	}
 
-	switch strings.ToLower(apq.OrderField) {
+	switch field, direction := strings.ToLower(apq.OrderField), util.SortDirection(apq.Order); field {
 	case "mining_power", "atk", "hp_limit":
 		query = query.Joins("INNER JOIN apostle_talents as a ON a.token_id COLLATE utf8mb4_general_ci = apostles.token_id").Select("apostles.*")
-		query = query.Order(fmt.Sprintf("a.%s %s", apq.OrderField, apq.Order))
+		query = query.Order(fmt.Sprintf("a.%s %s", field, direction))
 	case "gen", "token_index":
-		query = query.Order(fmt.Sprintf("apostles.%s %s", apq.OrderField, apq.Order))
+		query = query.Order(fmt.Sprintf("apostles.%s %s", field, direction))
 	case "price":
 		query = query.Joins("LEFT JOIN auction_apostles as a ON a.token_id = apostles.token_id")
-		query = query.Order(fmt.Sprintf("a.start_%s %s", apq.OrderField, apq.Order))
+		query = query.Order("a.start_price " + direction)
 	}
 	query.Where("apostles.status !=?", apostleBirth).Offset(apq.Page * apq.Row).Limit(apq.Row).Scan(&apostles)
 	db.Where("apostles.status !=?", apostleBirth).Count(&count)
@@ -589,7 +590,7 @@ func (apq *ApostleQuery) filterApostle(ctx context.Context, apostles []ApostleJs
 	return apostles
 }
 
-func (apq *ApostleQuery) multiFilterApostle(ctx context.Context, priceMap map[string]decimal.Decimal) (tokenQuery []string, apostleTokenIndex []int64, needFilter bool) {
+func (apq *ApostleQuery) multiFilterApostle(ctx context.Context, priceMap map[string]decimal.Decimal) (tokenQuery util.Wheres, apostleTokenIndex []int64, needFilter bool) {
 	var compare PriceCompare
 	multiFilter := apq.MultiFilter
 
@@ -612,6 +613,9 @@ func (apq *ApostleQuery) multiFilterApostle(ctx context.Context, priceMap map[st
 			}
 		}
 		for talent, value := range multiFilter.Talent {
+			if !util.StringInSlice(talent, apostleTalentFilter) {
+				continue
+			}
 			query = query.Where(fmt.Sprintf("%s >= ?", talent), value)
 		}
 		query.Pluck("token_id", &apostleElementId)
@@ -637,10 +641,10 @@ func (apq *ApostleQuery) multiFilterApostle(ctx context.Context, priceMap map[st
 
 	if len(multiFilter.Gen) > 0 {
 		if get, ok := multiFilter.Gen["gte"]; ok {
-			tokenQuery = append(tokenQuery, fmt.Sprintf("gen >= %d ", util.StringToInt(get)))
+			tokenQuery = append(tokenQuery, util.Gte("gen", util.StringToInt(get)))
 		}
 		if lte, ok := multiFilter.Gen["lte"]; ok {
-			tokenQuery = append(tokenQuery, fmt.Sprintf("gen <= %d ", util.StringToInt(lte)))
+			tokenQuery = append(tokenQuery, util.Lte("gen", util.StringToInt(lte)))
 		}
 	}
 	for _, tokenId := range apostleElementId {
@@ -671,27 +675,16 @@ func (ec *EthTransactionCallback) ApostleCallback(ctx context.Context) (err erro
 
 	chain := ec.Receipt.ChainSource
 
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("apostle", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			logSlice := util.LogAnalysis(log.Data)
-			switch eventName {
-			case services.AbiEncodingMethod("Birth(address,uint256,uint256,uint256,uint256,uint256,uint256,uint256,uint256)"):
-				owner := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				coolDown := util.StringToInt(util.U256(logSlice[5]).String())
-				Gen := util.StringToInt(util.U256(logSlice[6]).String())
-				birthTime := util.StringToInt(util.U256(logSlice[7]).String())
-				err = createApostles(ctx, db, owner, logSlice[0], logSlice[1], logSlice[2], logSlice[3], logSlice[4], Gen, coolDown, birthTime)
-
-			case services.AbiEncodingMethod("Pregnant(uint256,uint256,uint256,uint256,uint256,uint256)"):
-				MatronCoolDownEnd := util.StringToInt(util.U256(logSlice[1]).String())
-				SireCoolDownEnd := util.StringToInt(util.U256(logSlice[4]).String())
-				MatronCoolDown := util.StringToInt(util.U256(logSlice[2]).String())
-				SireCoolDown := util.StringToInt(util.U256(logSlice[5]).String())
-				err = dealApostlePregnant(ctx, db, ec.Tx, logSlice[0], logSlice[3], MatronCoolDown, SireCoolDown, MatronCoolDownEnd, SireCoolDownEnd)
-			}
-		}
-	}
+	err = ec.eachEvent(util.GetContractAddress("apostle", chain),
+		events.On(func(ev *Birth) error {
+			return createApostles(ctx, db, eventAddress(ev.Owner, chain), eventTokenId(ev.ApostleTokenId), eventTokenId(ev.MatronId), eventTokenId(ev.SireId),
+				eventTokenId(ev.Genes), eventTokenId(ev.Talents), int(ev.Generation.Int64()), int(ev.CoolDownIndex.Int64()), int(ev.BirthTime.Int64()))
+		}),
+		events.On(func(ev *Pregnant) error {
+			return dealApostlePregnant(ctx, db, ec.Tx, eventTokenId(ev.MatronId), eventTokenId(ev.SireId), int(ev.MatronCoolDownIndex.Int64()), int(ev.SireCoolDownIndex.Int64()),
+				int(ev.MatronCoolDownEndTime.Int64()), int(ev.SireCoolDownEndTime.Int64()))
+		}),
+	)
 
 	if err != nil {
 		return err
@@ -818,6 +811,11 @@ func renderingApostle(ctx context.Context, chain string, apostles []ApostleJson)
 	}
 
 	onsellAuction := getAuctionApostleReversalKey(ctx, onsellIds, AuctionGoing)
+	onsellAucs := make([]AuctionApostle, 0, len(onsellAuction))
+	for _, auc := range onsellAuction {
+		onsellAucs = append(onsellAucs, auc)
+	}
+	onsellPrices := auctionApostlePrices(onsellAucs)
 	// onFertilityAuction := getApostleFertilityReversalKey(onFertilityIds, AuctionGoing)
 	// onRentAuction := getApostleWorkTradeReversalKey(onRentIds, AuctionGoing)
 	onWorkingAuction := getApostleWorkTradeReversalKey(ctx, onWorking, AuctionFinish)
@@ -851,7 +849,11 @@ func renderingApostle(ctx context.Context, chain string, apostles []ApostleJson)
 					apostles[i].Status = apostleFresh
 				}
 			}
-			apostles[i].CurrentPrice = auc.CurrentPrice()
+			if price, ok := onsellPrices[auc.TokenId]; ok {
+				apostles[i].CurrentPrice = price
+			} else {
+				apostles[i].CurrentPrice = auc.CurrentPrice()
+			}
 			if auc.LastBidStart != 0 && time.Now().Unix()-int64(auc.LastBidStart) > apostleClaimTime(getNFTDistrict(apostle.TokenId)) {
 				apostles[i].Status = AuctionClaimed
 			}
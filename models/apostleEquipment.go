@@ -4,10 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 )
 
 // Equipment 装备系统
@@ -98,40 +97,32 @@ func (ec *EthTransactionCallback) CraftBaseCallback(ctx context.Context) (err er
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("CraftBase", chain)) {
-			logSlice := util.LogAnalysis(log.Data)
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			// Crafted(address to, uint256 tokenId, uint256 obj_id, uint256 rarity, uint256 prefer, uint256 timestamp)
-			case services.AbiEncodingMethod("Crafted(address,uint256,uint256,uint256,uint256,uint256)"):
-
-				objId := util.StringToInt(util.U256(logSlice[2]).String())
-				rarity := util.StringToInt(util.U256(logSlice[3]).String())
-				prefer := ""
-				if preferValue := util.StringToInt(util.U256(logSlice[4]).String()); preferValue > 0 {
-					prefer = preferMap[int(math.Log2(float64(preferValue)))-1]
-				}
-				db.Create(&Equipment{
-					Owner:            util.AddHex(util.TrimHex(logSlice[0])[24:64], chain),
-					EquipmentTokenId: logSlice[1],
-					Rarity:           rarity,
-					Prefer:           prefer,
-					Object:           objectMap[objId],
-					Level:            0,
-					Chain:            chain,
-				})
-			// Enchanced(uint256 id, uint8 class, uint256 timestamp);
-			case services.AbiEncodingMethod("Enchanced(uint256,uint8,uint256)"):
-				db.Model(Equipment{}).Where("equipment_token_id = ?", logSlice[0]).
-					Update("level", util.StringToInt(util.U256(logSlice[1]).String()))
-
-			case services.AbiEncodingMethod("Disenchanted(uint256,uint8,uint256)"):
-				db.Model(Equipment{}).Where("equipment_token_id = ?", logSlice[0]).
-					Update("level", util.StringToInt(util.U256(logSlice[1]).String()))
+	err = ec.eachEvent(util.GetContractAddress("CraftBase", chain),
+		events.On(func(ev *Crafted) error {
+			prefer := ""
+			if preferValue := ev.Prefer.Int64(); preferValue > 0 {
+				prefer = preferMap[int(math.Log2(float64(preferValue)))-1]
 			}
-		}
-	}
+			db.Create(&Equipment{
+				Owner:            eventAddress(ev.To, chain),
+				EquipmentTokenId: eventTokenId(ev.TokenId),
+				Rarity:           int(ev.Rarity.Int64()),
+				Prefer:           prefer,
+				Object:           objectMap[int(ev.ObjId.Int64())],
+				Level:            0,
+				Chain:            chain,
+			})
+			return nil
+		}),
+		events.On(func(ev *Enchanced) error {
+			db.Model(Equipment{}).Where("equipment_token_id = ?", eventTokenId(ev.Id)).Update("level", int(ev.Class))
+			return nil
+		}),
+		events.On(func(ev *Disenchanted) error {
+			db.Model(Equipment{}).Where("equipment_token_id = ?", eventTokenId(ev.Id)).Update("level", int(ev.Class))
+			return nil
+		}),
+	)
 	if err != nil {
 		return err
 	}
@@ -146,11 +137,12 @@ func (ec *EthTransactionCallback) CraftBaseCallback(ctx context.Context) (err er
 
 func EquipmentList(ctx context.Context, opt ListOpt) (list []Equipment, count int) {
 	query := util.WithContextDb(ctx).Model(Equipment{})
-	for _, w := range opt.WhereQuery {
-		query = query.Where(w)
-	}
+	query = opt.WhereQuery.Apply(query)
 	query.Count(&count)
-	order := util.TrueOrElse(opt.Order == "", "id desc", fmt.Sprintf("%s %s", opt.OrderField, opt.Order))
+	order := "id desc"
+	if o := util.OrderBy(opt.OrderField, opt.Order, []string{"id", "rarity"}); opt.Order != "" && o != "" {
+		order = o
+	}
 	query.Order(order).Offset(opt.Page * opt.Row).Limit(opt.Row).Find(&list)
 	return
 }
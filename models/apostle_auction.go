@@ -3,12 +3,12 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/evolutionlandorg/evo-backend/util/log"
 	"github.com/jinzhu/gorm"
 	"github.com/shopspring/decimal"
@@ -82,16 +82,29 @@ func (auc *AuctionApostle) CurrentPrice() decimal.Decimal {
 }
 
 func (auc *AuctionApostle) CurrentPriceFromChain() decimal.Decimal {
-	chain := GetChainByTokenId(auc.TokenId)
+	if !auc.priceOnChain() {
+		return auc.CurrentPrice()
+	}
+	sg := storage.New(GetChainByTokenId(auc.TokenId))
+	return sg.ApostleCurrentPriceInToken(auc.TokenId)
+}
+
+// priceOnChain the auction is running and its price is read from chain, ended auctions without bid are at EndPrice
+func (auc *AuctionApostle) priceOnChain() bool {
 	now := int(time.Now().Unix())
 	if auc.Status != AuctionGoing || now < auc.StartAt || auc.Duration == 0 {
-		return decimal.RequireFromString("-1")
+		return false
 	}
-	if (auc.LastBidder == noneAddress || auc.LastBidder == "") && (now-auc.StartAt > auc.Duration) {
-		return auc.EndPrice
+	return (auc.LastBidder != noneAddress && auc.LastBidder != "") || now-auc.StartAt <= auc.Duration
+}
+
+// auctionApostlePrices current prices of apostle auctions keyed by token id, read from chain in batches
+func auctionApostlePrices(aucs []AuctionApostle) map[string]decimal.Decimal {
+	prices := make([]auctionPrice, len(aucs))
+	for i := range aucs {
+		prices[i] = auctionPrice{TokenId: aucs[i].TokenId, Local: aucs[i].CurrentPrice(), OnChain: aucs[i].priceOnChain()}
 	}
-	sg := storage.New(chain)
-	return sg.ApostleCurrentPriceInToken(auc.TokenId)
+	return batchAuctionPrices(prices, storage.IStorage.BatchApostleCurrentPriceInToken)
 }
 
 // ClockAuctionApostleCallback 使徒拍卖/取消/拍卖成功/出价回调函数
@@ -103,22 +116,20 @@ func (ec *EthTransactionCallback) ClockAuctionApostleCallback(ctx context.Contex
 	defer db.DbRollback()
 	var event, winner, tokenId string
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("clockAuctionApostle", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("AuctionCreated(uint256,address,uint256,uint256,uint256,address,uint256)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				err = createAuctionApostle(ctx, db, dataSlice, ec.Tx, chain)
-			case services.AbiEncodingMethod("AuctionCancelled(uint256)"):
-				err = cancelAuctionApostle(ctx, db, ec.Tx, util.TrimHex(log.Data))
-			case services.AbiEncodingMethod("AuctionSuccessful(uint256,uint256,address)"):
-				err = successAuctionApostle(ctx, db, ec.Tx, chain, util.LogAnalysis(log.Data))
-			case services.AbiEncodingMethod("NewBid(uint256,address,address,uint256,address,uint256,uint256)"):
-				err = recordAuctionNewBid(ctx, db, ec.Tx, util.TrimHex(log.Topics[1]), chain, util.LogAnalysis(log.Data))
-			}
-		}
-	}
+	err = ec.eachEvent(util.GetContractAddress("clockAuctionApostle", chain),
+		events.On(func(ev *AuctionCreated) error {
+			return createAuctionApostle(ctx, db, ev, ec.Tx, chain)
+		}),
+		events.On(func(ev *AuctionCancelled) error {
+			return cancelAuctionApostle(ctx, db, ec.Tx, eventTokenId(ev.TokenId))
+		}),
+		events.On(func(ev *AuctionSuccessful) error {
+			return successAuctionApostle(ctx, db, ec.Tx, chain, ev)
+		}),
+		events.On(func(ev *NewBid) error {
+			return recordAuctionNewBid(ctx, db, ec.Tx, chain, ev)
+		}),
+	)
 	if err != nil {
 		db.DbRollback()
 		return err
@@ -174,11 +185,15 @@ func OnsellApostleList(ctx context.Context, seller string, chain string, where [
 	if len(aucs) == 0 {
 		return
 	}
+	var onsell []AuctionApostle
 	for _, auction := range aucs {
 		if auction.LastBidStart != 0 && time.Now().Unix()-int64(auction.LastBidStart) > apostleClaimTime(getNFTDistrict(auction.TokenId)) { // 排除unclaimed
 			continue
 		}
-		priceMap[auction.TokenId] = auction.CurrentPrice()
+		onsell = append(onsell, auction)
+	}
+	priceMap = auctionApostlePrices(onsell)
+	for _, auction := range onsell {
 		tokenMap[auction.TokenId] = util.Evo.GetToken(GetChainByDistrict(auction.District), auction.Currency)
 		tokenIdArr = append(tokenIdArr, auction.TokenId)
 		if auction.LastBidStart != 0 {
@@ -188,24 +203,24 @@ func OnsellApostleList(ctx context.Context, seller string, chain string, where [
 	return
 }
 
-func createAuctionApostle(ctx context.Context, db *util.GormDB, data []string, tx, chain string) error {
-	tokenId := data[0]
-	seller := util.AddHex(data[1][24:64], chain)
+func createAuctionApostle(ctx context.Context, db *util.GormDB, ev *AuctionCreated, tx, chain string) error {
+	tokenId := eventTokenId(ev.TokenId)
+	seller := eventAddress(ev.Seller, chain)
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil {
 		_ = services.NewIssue("not create this Apostle :" + tx)
 		return errors.New("not create this Apostle")
 	}
 	auc := AuctionApostle{ApostleId: apostle.ID, TokenId: apostle.TokenId, CreateTX: tx, Seller: seller}
-	auc.Currency = util.AddHex(data[5][24:64], chain)
+	auc.Currency = eventAddress(ev.Token, chain)
 
 	tokenInfo := util.Evo.GetToken(chain, auc.Currency)
 
-	auc.StartPrice = util.BigToDecimal(util.U256(data[2]), int32(tokenInfo.Decimals))
-	auc.EndPrice = util.BigToDecimal(util.U256(data[3]), int32(tokenInfo.Decimals))
-	auc.Duration = util.StringToInt(util.U256(data[4]).String())
+	auc.StartPrice = util.BigToDecimal(ev.StartingPriceInToken, int32(tokenInfo.Decimals))
+	auc.EndPrice = util.BigToDecimal(ev.EndingPriceInToken, int32(tokenInfo.Decimals))
+	auc.Duration = int(ev.Duration.Int64())
 
-	auc.StartAt = util.StringToInt(util.U256(data[6]).String())
+	auc.StartAt = int(ev.StartedAt.Int64())
 	auc.District = apostle.District
 	if err := auc.New(db); err != nil {
 		return errors.New(" create this auction fail")
@@ -224,7 +239,8 @@ func createAuctionApostle(ctx context.Context, db *util.GormDB, data []string, t
 	return nil
 }
 
-func recordAuctionNewBid(ctx context.Context, db *util.GormDB, tx, tokenId, chain string, logData []string) error {
+func recordAuctionNewBid(ctx context.Context, db *util.GormDB, tx, chain string, ev *NewBid) error {
+	tokenId := eventTokenId(ev.TokenId)
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil {
 		_ = services.NewIssue("not create this Apostle :" + tx)
@@ -235,14 +251,14 @@ func recordAuctionNewBid(ctx context.Context, db *util.GormDB, tx, tokenId, chai
 		return errors.New("auction not going")
 	}
 	previousBuyer := auction.LastBidder
-	lastBuyer := util.AddHex(logData[0][24:64], chain)
-	price := util.BigToDecimal(util.U256(logData[2]), util.GetTokenDecimals(chain))
-	lastStart := util.StringToInt(util.U256(logData[4]).String())
-	bidToken := util.AddHex(logData[3][24:64], chain)
+	lastBuyer := eventAddress(ev.LastBidder, chain)
+	price := util.BigToDecimal(ev.LastRecord, util.GetTokenDecimals(chain))
+	lastStart := int(ev.BidStartAt.Int64())
+	bidToken := eventAddress(ev.TokenAddress, chain)
 	if price.GreaterThanOrEqual(auction.LastPrice) {
 		db.Model(&auction).UpdateColumn(AuctionApostle{LastBidder: lastBuyer, LastPrice: price, LastBidStart: lastStart})
 	}
-	returnToLastBidder := util.BigToDecimal(util.U256(logData[5]), util.GetTokenDecimals(chain))
+	returnToLastBidder := util.BigToDecimal(ev.ReturnToLastBidder, util.GetTokenDecimals(chain))
 	ah := AuctionHistory{AuctionId: auction.ID, TokenId: tokenId, TxId: tx, Buyer: lastBuyer, BidPrice: price, StartAt: lastStart, BidToken: bidToken, AssetType: AssetApostle}
 	if err := ah.New(ctx); err != nil {
 		db.DbRollback()
@@ -257,8 +273,8 @@ func recordAuctionNewBid(ctx context.Context, db *util.GormDB, tx, tokenId, chai
 	return nil
 }
 
-func successAuctionApostle(ctx context.Context, db *util.GormDB, tx, chain string, logData []string) error {
-	tokenId := util.TrimHex(logData[0])
+func successAuctionApostle(ctx context.Context, db *util.GormDB, tx, chain string, ev *AuctionSuccessful) error {
+	tokenId := eventTokenId(ev.TokenId)
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil {
 		_ = services.NewIssue("not create this Apostle :" + tx)
@@ -268,8 +284,8 @@ func successAuctionApostle(ctx context.Context, db *util.GormDB, tx, chain strin
 	if auction == nil {
 		return errors.New("auction not going")
 	}
-	price := util.BigToDecimal(util.U256(logData[1]), util.GetTokenDecimals(chain))
-	winner := util.AddHex(logData[2][24:64], chain)
+	price := util.BigToDecimal(ev.TotalPrice, util.GetTokenDecimals(chain))
+	winner := eventAddress(ev.Winner, chain)
 	db.Model(&auction).UpdateColumn(AuctionApostle{Winner: winner, FinalPrice: price, Status: AuctionFinish, ClaimTime: int(time.Now().Unix())})
 	_ = apostle.TransferOwner(db, winner, apostleFresh, "", 0, chain)
 	th := TransactionHistory{Tx: tx, Chain: chain, BalanceAddress: winner, Action: TransactionHistoryApostleSuccessAuction, TokenId: tokenId}
@@ -3,11 +3,12 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/evolutionlandorg/evo-backend/services"
+	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/evolutionlandorg/evo-backend/util/log"
 	"github.com/jinzhu/gorm"
 	"github.com/shopspring/decimal"
@@ -91,21 +92,17 @@ func (ec *EthTransactionCallback) ApostleFertilityCallback(ctx context.Context)
 	defer db.DbRollback()
 	var event, winner, tokenId string
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("apostleFertility", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("AuctionCreated(uint256,address,uint256,uint256,uint256,address,uint256)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				err = createApostleFertility(ctx, db, dataSlice, ec.Tx, chain)
-			case services.AbiEncodingMethod("AuctionCancelled(uint256)"):
-				err = cancelApostleFertility(ctx, db, ec.Tx, util.TrimHex(log.Data))
-			case services.AbiEncodingMethod("AuctionSuccessful(uint256,uint256,address)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				err = finishApostleFertility(ctx, db, ec.Tx, chain, dataSlice)
-			}
-		}
-	}
+	err = ec.eachEvent(util.GetContractAddress("apostleFertility", chain),
+		events.On(func(ev *AuctionCreated) error {
+			return createApostleFertility(ctx, db, ev, ec.Tx, chain)
+		}),
+		events.On(func(ev *AuctionCancelled) error {
+			return cancelApostleFertility(ctx, db, ec.Tx, eventTokenId(ev.TokenId))
+		}),
+		events.On(func(ev *AuctionSuccessful) error {
+			return finishApostleFertility(ctx, db, ec.Tx, chain, ev)
+		}),
+	)
 	if err != nil {
 		db.DbRollback()
 		return err
@@ -126,20 +123,20 @@ func (ec *EthTransactionCallback) ApostleFertilityCallback(ctx context.Context)
 	return err
 }
 
-func createApostleFertility(ctx context.Context, db *util.GormDB, data []string, tx, chain string) error {
-	tokenId := data[0]
-	seller := util.AddHex(data[1][24:64], chain)
+func createApostleFertility(ctx context.Context, db *util.GormDB, ev *AuctionCreated, tx, chain string) error {
+	tokenId := eventTokenId(ev.TokenId)
+	seller := eventAddress(ev.Seller, chain)
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil {
 		_ = services.NewIssue("not create this Apostle :" + tx)
 		return errors.New("not create this Apostle")
 	}
 	auc := ApostleFertility{ApostleId: apostle.ID, TokenId: apostle.TokenId, CreateTX: tx, Seller: seller, Status: AuctionGoing}
-	auc.StartPrice = util.BigToDecimal(util.U256(data[2]), util.GetTokenDecimals(chain))
-	auc.EndPrice = util.BigToDecimal(util.U256(data[3]), util.GetTokenDecimals(chain))
-	auc.Duration = util.StringToInt(util.U256(data[4]).String())
-	auc.StartAt = util.StringToInt(util.U256(data[6]).String())
-	auc.Currency = util.ToChecksumAddress(data[5][24:64])
+	auc.StartPrice = util.BigToDecimal(ev.StartingPriceInToken, util.GetTokenDecimals(chain))
+	auc.EndPrice = util.BigToDecimal(ev.EndingPriceInToken, util.GetTokenDecimals(chain))
+	auc.Duration = int(ev.Duration.Int64())
+	auc.StartAt = int(ev.StartedAt.Int64())
+	auc.Currency = util.ToChecksumAddress(ev.Token.Hex())
 	auc.District = apostle.District
 	if err := auc.New(db); err != nil {
 		return errors.New(" create this auction fail")
@@ -171,10 +168,10 @@ func cancelApostleFertility(ctx context.Context, db *util.GormDB, tx, tokenId st
 	return nil
 }
 
-func finishApostleFertility(ctx context.Context, db *util.GormDB, tx, chain string, logData []string) error {
-	tokenId := util.TrimHex(logData[0])
-	price := util.BigToDecimal(util.U256(logData[1]), util.GetTokenDecimals(chain))
-	winner := util.AddHex(logData[2][24:64], chain)
+func finishApostleFertility(ctx context.Context, db *util.GormDB, tx, chain string, ev *AuctionSuccessful) error {
+	tokenId := eventTokenId(ev.TokenId)
+	price := util.BigToDecimal(ev.TotalPrice, util.GetTokenDecimals(chain))
+	winner := eventAddress(ev.Winner, chain)
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil {
 		_ = services.NewIssue("not create this Apostle :" + tx)
@@ -247,8 +244,13 @@ func SiringApostlePriceList(ctx context.Context, chain string, where ...string)
 	if len(aucs) == 0 {
 		return
 	}
+	now := int(time.Now().Unix())
+	prices := make([]auctionPrice, len(aucs))
+	for i, auction := range aucs {
+		prices[i] = auctionPrice{TokenId: auction.TokenId, Local: auction.CurrentPrice(), OnChain: auction.Status == AuctionGoing && now >= auction.StartAt && auction.Duration != 0 && now-auction.StartAt <= auction.Duration}
+	}
+	priceMap = batchAuctionPrices(prices, storage.IStorage.BatchSiringCurrentPriceInToken)
 	for _, auction := range aucs {
-		priceMap[auction.TokenId] = auction.CurrentPrice()
 		tokenMap[auction.TokenId] = util.Evo.GetToken(GetChainByDistrict(auction.District), auction.Currency)
 	}
 	return
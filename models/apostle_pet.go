@@ -8,8 +8,8 @@ import (
 	"github.com/evolutionlandorg/evo-backend/util/nft/polkaPet"
 	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 
 	"github.com/jinzhu/gorm"
 )
@@ -139,56 +139,46 @@ func (ec *EthTransactionCallback) PetBaseCallback(ctx context.Context) (err erro
 	txn := util.DbBegin(ctx)
 	defer txn.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("petBase", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("Tied(uint256,uint256,uint256,bool,address,address)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				mirrorTokenId := dataSlice[1]
-				apostleTokenId := dataSlice[0]
-				talent := dataSlice[2]
-				apostle := GetApostleByTokenId(ctx, apostleTokenId)
-				if apostle == nil {
-					return errors.New("apostle token id error")
-				}
-				from := util.AddHex(dataSlice[5][24:64], chain)
-				txn.Model(ApostlePet{}).Where("mirror_token_id = ?", mirrorTokenId).UpdateColumn(map[string]interface{}{
-					"apostle_id": apostle.ID, "apostle_token_id": apostle.TokenId,
-				})
-				if err := apostle.RefreshTalent(txn, talent); err != nil {
-					txn.DbRollback()
-					return err
-				}
-				if err := apostle.updateApostlePetCount(ctx, false); err != nil {
-					txn.DbRollback()
-					return err
-				}
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: from, Action: TransactionHistoryApostleBindPet, TokenId: apostleTokenId}
-				_ = th.New(txn)
-			case services.AbiEncodingMethod("UnTied(uint256,uint256,uint256,bool,address,address)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				mirrorTokenId := dataSlice[1]
-				apostleTokenId := dataSlice[0]
-				talent := dataSlice[2]
-				from := util.AddHex(dataSlice[5][24:64], chain)
-				apostle := GetApostleByTokenId(ctx, apostleTokenId)
-				if apostle == nil {
-					return errors.New("apostle token id error")
-				}
-				txn.Table("apostle_pets").Where("mirror_token_id = ?", mirrorTokenId).UpdateColumn(map[string]interface{}{"apostle_id": 0, "apostle_token_id": ""})
-				if err := apostle.RefreshTalent(txn, talent); err != nil {
-					txn.DbRollback()
-					return err
-				}
-				if err := apostle.updateApostlePetCount(ctx, true); err != nil {
-					txn.DbRollback()
-					return err
-				}
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: from, Action: TransactionHistoryApostleUnbindPet, TokenId: apostleTokenId}
-				_ = th.New(txn)
+	err = ec.eachEvent(util.GetContractAddress("petBase", chain),
+		events.On(func(ev *Tied) error {
+			apostleTokenId := eventTokenId(ev.ApostleTokenId)
+			apostle := GetApostleByTokenId(ctx, apostleTokenId)
+			if apostle == nil {
+				return errors.New("apostle token id error")
 			}
-		}
+			txn.Model(ApostlePet{}).Where("mirror_token_id = ?", eventTokenId(ev.MirrorTokenId)).UpdateColumn(map[string]interface{}{
+				"apostle_id": apostle.ID, "apostle_token_id": apostle.TokenId,
+			})
+			if err := apostle.RefreshTalent(txn, eventTokenId(ev.EnhancedTalents)); err != nil {
+				return err
+			}
+			if err := apostle.updateApostlePetCount(ctx, false); err != nil {
+				return err
+			}
+			th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: eventAddress(ev.Owner, chain), Action: TransactionHistoryApostleBindPet, TokenId: apostleTokenId}
+			_ = th.New(txn)
+			return nil
+		}),
+		events.On(func(ev *UnTied) error {
+			apostleTokenId := eventTokenId(ev.ApostleTokenId)
+			apostle := GetApostleByTokenId(ctx, apostleTokenId)
+			if apostle == nil {
+				return errors.New("apostle token id error")
+			}
+			txn.Table("apostle_pets").Where("mirror_token_id = ?", eventTokenId(ev.MirrorTokenId)).UpdateColumn(map[string]interface{}{"apostle_id": 0, "apostle_token_id": ""})
+			if err := apostle.RefreshTalent(txn, eventTokenId(ev.EnhancedTalents)); err != nil {
+				return err
+			}
+			if err := apostle.updateApostlePetCount(ctx, true); err != nil {
+				return err
+			}
+			th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: eventAddress(ev.Owner, chain), Action: TransactionHistoryApostleUnbindPet, TokenId: apostleTokenId}
+			_ = th.New(txn)
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
 	}
 
 	if err := ec.NewUniqueTransaction(txn, "petBase"); err != nil {
@@ -3,11 +3,10 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/jinzhu/gorm"
 )
 
@@ -27,16 +26,12 @@ func (ec *EthTransactionCallback) TakeBackNFTCallback(ctx context.Context) (err
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("takeBackNFT", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("TakenBackNFT(address,uint256,uint256)"):
-				address := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				logSlice := util.LogAnalysis(log.Data)
-				confirmApostleReward(db, address, ec.Tx, logSlice[0])
-			}
-		}
+	err = ec.eachEvent(util.GetContractAddress("takeBackNFT", chain), events.On(func(ev *TakenBackNFT) error {
+		confirmApostleReward(db, eventAddress(ev.User, chain), ec.Tx, eventTokenId(ev.TokenId))
+		return nil
+	}))
+	if err != nil {
+		return err
 	}
 
 	if err := ec.NewUniqueTransaction(db, "takeBackNFT"); err != nil {
@@ -3,11 +3,11 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/jinzhu/gorm"
 	"github.com/shopspring/decimal"
 )
@@ -79,23 +79,20 @@ func (ec *EthTransactionCallback) TokenUseCallback(ctx context.Context) (err err
 	chain := ec.Receipt.ChainSource
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("tokenUse", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("OfferCreated(uint256,uint256,uint256,address,address)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				err = offerCreated(ctx, db, ec.Tx, chain, util.TrimHex(log.Topics[1]), dataSlice)
-			case services.AbiEncodingMethod("OfferCancelled(uint256)"):
-				err = offerCancelled(ctx, db, ec.Tx, chain, util.TrimHex(log.Data))
-			case services.AbiEncodingMethod("OfferTaken(uint256,address,address,uint256,uint256)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				err = offerTaken(ctx, db, ec.Tx, chain, util.TrimHex(log.Topics[1]), dataSlice)
-			case services.AbiEncodingMethod("TokenUseRemoved(uint256,address,address,address)"): // 打工时间到期
-				err = tokenUseRemoved(ctx, db, ec.Tx, chain, util.TrimHex(log.Topics[1]))
-			}
-		}
-	}
+	err = ec.eachEvent(util.GetContractAddress("tokenUse", chain),
+		events.On(func(ev *OfferCreated) error {
+			return offerCreated(ctx, db, ec.Tx, chain, ev)
+		}),
+		events.On(func(ev *OfferCancelled) error {
+			return offerCancelled(ctx, db, ec.Tx, chain, eventTokenId(ev.TokenId))
+		}),
+		events.On(func(ev *OfferTaken) error {
+			return offerTaken(ctx, db, ec.Tx, chain, ev)
+		}),
+		events.On(func(ev *TokenUseRemoved) error { // 打工时间到期
+			return tokenUseRemoved(ctx, db, ec.Tx, chain, eventTokenId(ev.TokenId))
+		}),
+	)
 	if err != nil {
 		return err
 	}
@@ -135,17 +132,18 @@ func GetApostleWork(ctx context.Context, tokenId, status string) *ApostleWorkTra
 	return &auc
 }
 
-func offerCreated(ctx context.Context, db *util.GormDB, tx, chain, tokenId string, data []string) error {
+func offerCreated(ctx context.Context, db *util.GormDB, tx, chain string, ev *OfferCreated) error {
+	tokenId := eventTokenId(ev.TokenId)
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil {
 		_ = services.NewIssue("not create this Apostle :" + tx)
 		return errors.New("not create this Apostle")
 	}
 	aw := ApostleWorkTrade{ApostleId: apostle.ID, TokenId: apostle.TokenId, CreateTX: tx, Status: AuctionGoing}
-	aw.Duration = util.StringToInt(util.U256(data[0]).String())
-	aw.Price = util.BigToDecimal(util.U256(data[1]), util.GetTokenDecimals(chain))
-	aw.Activity = util.AddHex(data[2][24:64], chain)
-	aw.Seller = util.AddHex(data[3][24:64], chain)
+	aw.Duration = int(ev.Duration.Int64())
+	aw.Price = util.BigToDecimal(ev.Price, util.GetTokenDecimals(chain))
+	aw.Activity = eventAddress(ev.AcceptedActivity, chain)
+	aw.Seller = eventAddress(ev.Owner, chain)
 	aw.Currency = util.GetContractAddress("ring", chain)
 
 	aw.District = apostle.District
@@ -179,13 +177,14 @@ func offerCancelled(ctx context.Context, db *util.GormDB, tx, chain, tokenId str
 	return nil
 }
 
-func offerTaken(ctx context.Context, db *util.GormDB, tx, chain, tokenId string, data []string) error {
+func offerTaken(ctx context.Context, db *util.GormDB, tx, chain string, ev *OfferTaken) error {
+	tokenId := eventTokenId(ev.TokenId)
 	if auction := GetApostleWork(ctx, tokenId, AuctionGoing); auction == nil {
 		return errors.New("not find")
 	} else {
 		apostle := GetApostleByTokenId(ctx, tokenId)
-		winner := util.AddHex(data[0][24:64], chain)
-		startAt := util.StringToInt(util.U256(data[2]).String())
+		winner := eventAddress(ev.From, chain)
+		startAt := int(ev.Now.Int64())
 		db.Model(&auction).UpdateColumn(ApostleWorkTrade{Status: AuctionFinish, Winner: winner, StartAt: startAt})
 		_ = apostle.TransferOwner(db, winner, apostleHiring, auction.Seller, apostle.OriginId, chain)
 		th := TransactionHistory{Tx: tx, Chain: chain, BalanceAddress: winner, BalanceChange: auction.Price.Neg(), Action: TransactionHistoryApostleRentSuccess, TokenId: tokenId, Currency: currencyRing}
@@ -85,6 +85,35 @@ func (auc *Auction) CurrentPrice() decimal.Decimal {
 	return sg.LandCurrentPriceInToken(auc.TokenId)
 }
 
+// auctionPrice current price of an auction in a list, OnChain ones are read from chain
+type auctionPrice struct {
+	TokenId string
+	Local   decimal.Decimal
+	OnChain bool
+}
+
+// batchAuctionPrices current prices of auctions, the OnChain ones are read in one batch for each chain,
+// the local price is kept if the chain does not return it
+func batchAuctionPrices(auctions []auctionPrice, read func(sg storage.IStorage, tokenIds []string) []decimal.Decimal) map[string]decimal.Decimal {
+	prices := make(map[string]decimal.Decimal, len(auctions))
+	chainTokenIds := make(map[string][]string)
+	for _, auc := range auctions {
+		prices[auc.TokenId] = auc.Local
+		if auc.OnChain {
+			chain := GetChainByTokenId(auc.TokenId)
+			chainTokenIds[chain] = append(chainTokenIds[chain], auc.TokenId)
+		}
+	}
+	for chain, tokenIds := range chainTokenIds {
+		for i, price := range read(storage.New(chain), tokenIds) {
+			if !price.IsNegative() {
+				prices[tokenIds[i]] = price
+			}
+		}
+	}
+	return prices
+}
+
 func GetCurrentAuction(ctx context.Context, tokenId, status string) *Auction {
 	db := util.WithContextDb(ctx)
 	var auc Auction
@@ -121,19 +150,24 @@ func MyAuctionLandList(ctx context.Context, district int, wallets []string) (tok
 	return tokenIds
 }
 
-func OnsellLandList(ctx context.Context, district int, where string) (tokenIdArr []string, hasBidArr []string, priceMap map[string]decimal.Decimal, startAtMap map[string]int, tokenMap map[string]*util.Token) {
+func OnsellLandList(ctx context.Context, district int, wheres ...util.Where) (tokenIdArr []string, hasBidArr []string, priceMap map[string]decimal.Decimal, startAtMap map[string]int, tokenMap map[string]*util.Token) {
 	db := util.WithContextDb(ctx)
 	var aucs []Auction
 	priceMap = make(map[string]decimal.Decimal)
 	tokenMap = make(map[string]*util.Token)
 	startAtMap = make(map[string]int)
-	query := db.Where("district = ?", district).Where(where).Find(&aucs)
+	query := util.Wheres(wheres).Apply(db.Where("district = ?", district)).Find(&aucs)
 	if query.RecordNotFound() {
 		return
 	}
+	now := int(time.Now().Unix())
+	prices := make([]auctionPrice, len(aucs))
+	for i, auction := range aucs {
+		prices[i] = auctionPrice{TokenId: auction.TokenId, Local: auction.CurrentPriceLocal(), OnChain: auction.Status == AuctionGoing && now >= auction.StartAt && auction.Duration != 0}
+	}
+	priceMap = batchAuctionPrices(prices, storage.IStorage.BatchLandCurrentPriceInToken)
 	for _, auction := range aucs {
 		tokenMap[auction.TokenId] = util.Evo.GetToken(GetChainByDistrict(auction.District), auction.Currency)
-		priceMap[auction.TokenId] = auction.CurrentPriceLocal()
 		startAtMap[auction.TokenId] = auction.StartAt
 		if auction.LastBidStart != 0 && time.Now().Unix()-int64(auction.LastBidStart) > landClaimTime(district) { // 排除unclaimed
 			continue
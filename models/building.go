@@ -3,7 +3,6 @@ package models
 import (
 	"context"
 	"errors"
-	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -131,6 +130,18 @@ const (
 	perWorkerSubTime = 60 * 60
 )
 
+var (
+	ErrDrawing         = errors.New("error drawing index")
+	ErrBuildingLand    = errors.New("error land tokenId or this land already building")
+	ErrBuildingRange   = errors.New("land choose out of range")
+	ErrBuildingOwner   = errors.New("not owner of building")
+	ErrBuildingStatus  = errors.New("building status not allowed")
+	ErrBuildingApostle = errors.New("error apostle")
+	ErrMinerLimit      = errors.New("exceed miner limit of drawing")
+	ErrAdminLimit      = errors.New("exceed admin limit of drawing")
+	ErrHiringClosed    = errors.New("building not hiring")
+)
+
 func GetDrawings() []Drawing {
 	if len(drawings) > 0 {
 		return drawings
@@ -141,34 +152,41 @@ func GetDrawings() []Drawing {
 	return drawings
 }
 
-// create building level 0
-func (d *Drawing) CreateBuilding(ctx context.Context, tokenId string, wallet string) error {
-	land := getLand(ctx, tokenId)
-	if land == nil || land.BuildingId > 0 {
-		return errors.New("error land tokenId or this land already building")
+func GetDrawing(index int) *Drawing {
+	list := GetDrawings()
+	if index < 1 || index > len(list) {
+		return nil
 	}
-	coords := checkLandNear(land.Lon, land.Lat, d.Index)
+	return &list[index-1]
+}
 
-	xRange := []int{-112, 68}
-	yRange := []int{-22, 22}
-	if land.District == 2 {
-		xRange = []int{68, 112}
-		yRange = []int{-22, 22}
+// CheckLand lands the building of drawing occupies when placed at tokenId, all of them must be owned by wallet
+func (d *Drawing) CheckLand(ctx context.Context, tokenId string, wallet string) ([]*Land, error) {
+	lands, err := d.BuildingNearLand(ctx, tokenId)
+	if err != nil {
+		return nil, err
 	}
-	var tokenIds []string
-	for _, coord := range coords {
-		coordSlice := strings.Split(coord, ",")
-		x := util.StringToInt(coordSlice[0])
-		y := util.StringToInt(coordSlice[1])
-		if x < xRange[0] || x > xRange[1] || y < yRange[0] || y > yRange[1] {
-			log.Debug("CreateBuilding info. x=%d. y=%d. xRange=%v; yRange=%v", x, y, xRange, yRange)
-			return errors.New("land choose out of range")
-		}
-		land := GetLandByCoord(ctx, x, y)
-		if !strings.EqualFold(land.Owner, wallet) || land.Status != landFresh || land.BuildingId > 0 {
-			return errors.New("error land tokenId or this land already building")
+	if len(lands) != d.LandRequire {
+		return nil, ErrBuildingLand
+	}
+	for _, land := range lands {
+		if !strings.EqualFold(land.Owner, wallet) {
+			return nil, ErrBuildingLand
 		}
-		tokenIds = append(tokenIds, land.TokenId)
+	}
+	return lands, nil
+}
+
+// create building level 0
+func (d *Drawing) CreateBuilding(ctx context.Context, tokenId string, wallet string) (*Building, error) {
+	lands, err := d.CheckLand(ctx, tokenId, wallet)
+	if err != nil {
+		return nil, err
+	}
+	land := lands[0]
+	var tokenIds []string
+	for _, l := range lands {
+		tokenIds = append(tokenIds, l.TokenId)
 	}
 	txn := util.DbBegin(ctx)
 	defer txn.Rollback()
@@ -184,40 +202,38 @@ func (d *Drawing) CreateBuilding(ctx context.Context, tokenId string, wallet str
 		TokenId:              interstellarEncoding(BuildingContractId, land.District, len(Builds(ctx, nil))),
 	}
 	if tx := txn.Create(&building); tx.Error != nil {
-		return tx.Error
+		return nil, tx.Error
 	}
 	if err := setLandBuilding(txn, building.ID, tokenIds); err != nil {
-		return err
+		return nil, err
 	}
 	txn.DbCommit()
-	return nil
+	return &building, txn.Error
 }
 
 func Builds(ctx context.Context, opt *ListOpt) []Building {
 	var list []Building
 	query := util.WithContextDb(ctx).Model(Building{})
 	if opt != nil {
-		if opt.OrderField != "" && opt.Order != "" {
-			query = query.Order(fmt.Sprintf("%s %s", opt.OrderField, opt.Order))
+		if order := util.OrderBy(opt.OrderField, opt.Order, []string{"id", "level", "created_at"}); order != "" {
+			query = query.Order(order)
 		}
 		if opt.Row > 0 {
 			query = query.Offset(opt.Page * opt.Row).Limit(opt.Row)
 		}
-		for _, q := range opt.WhereQuery {
-			query = query.Where(q)
-		}
+		query = opt.WhereQuery.Apply(query)
 	}
 	query.Find(&list)
 	return list
 }
 
-//func GetBuilding(buildingId uint) *Building {
-//	var building Building
-//	if query := util.DB.First(&building, buildingId); query.RecordNotFound() {
-//		return nil
-//	}
-//	return &building
-//}
+func GetBuilding(ctx context.Context, buildingId uint) *Building {
+	var building Building
+	if query := util.WithContextDb(ctx).First(&building, buildingId); query.Error != nil {
+		return nil
+	}
+	return &building
+}
 
 func (b *Building) AsJson(ctx context.Context, _ *Member) *BuildingDetail {
 	var detail BuildingDetail
@@ -281,35 +297,77 @@ func (b *Building) AsJson(ctx context.Context, _ *Member) *BuildingDetail {
 //	return getLand(b.LandTokenId)
 //}
 
-func (b *Building) Upgrade(ctx context.Context) {
-	util.WithContextDb(ctx).Model(b).Update(Building{
-		Status:               Upgrading,
-		ConstructionStartAt:  int(time.Now().Unix()),
-		ConstructionDuration: b.Drawing().UpgradeDuration[b.Level],
+// Upgrade idle building to the next level of drawing
+func (b *Building) Upgrade(ctx context.Context) error {
+	durations := b.Drawing().UpgradeDuration
+	if b.Status != Idle || b.Level >= len(durations) {
+		return ErrBuildingStatus
+	}
+	query := util.WithContextDb(ctx).Model(b).Where("status = ?", Idle).Update(map[string]interface{}{
+		"status":                Upgrading,
+		"construction_start_at": int(time.Now().Unix()),
+		"construction_duration": durations[b.Level],
+		"construction_sub":      0,
 	})
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return ErrBuildingStatus
+	}
+	return nil
 }
 
 func (b *Building) Drawing() *Drawing {
 	return &GetDrawings()[b.DrawingIndex-1]
 }
 
-func (b *Building) UpgradeComplete(ctx context.Context) {
-	if query := util.WithContextDb(ctx).Model(b).Where("level = ?", b.Level).Update(map[string]interface{}{
+// FinishAt unix time the construction or upgrade completes, workers joined shorten it
+func (b *Building) FinishAt() int {
+	return b.ConstructionStartAt + b.ConstructionDuration - b.ConstructionSub
+}
+
+// DueBuildings constructing or upgrading buildings whose timer elapsed at now
+func DueBuildings(ctx context.Context, now int64, limit int) []Building {
+	var list []Building
+	util.WithContextDb(ctx).Where("status in (?)", []string{Constructing, Upgrading}).
+		Where("construction_start_at + construction_duration - construction_sub <= ?", now).
+		Order("id asc").Limit(limit).Find(&list)
+	return list
+}
+
+// UpgradeComplete level up and free workers, it is done once even called concurrently
+func (b *Building) UpgradeComplete(ctx context.Context) error {
+	return b.finishConstruction(ctx, map[string]interface{}{
 		"status":        Idle,
 		"level":         b.Level + 1,
 		"hiring_status": false,
-	}); query.RowsAffected > 0 {
-		_ = b.freeWorker(ctx)
-	}
+	})
 }
 
-func (b *Building) CancelUpgrade(ctx context.Context) {
-	if query := util.WithContextDb(ctx).Model(b).Update(map[string]interface{}{
+func (b *Building) CancelUpgrade(ctx context.Context) error {
+	return b.finishConstruction(ctx, map[string]interface{}{
 		"status":        Idle,
 		"hiring_status": false,
-	}); query.RowsAffected > 0 {
-		_ = b.freeWorker(ctx)
+	})
+}
+
+func (b *Building) finishConstruction(ctx context.Context, values map[string]interface{}) error {
+	txn := util.DbBegin(ctx)
+	defer txn.DbRollback()
+	query := txn.Model(Building{}).Where("id = ? AND level = ?", b.ID, b.Level).
+		Where("status in (?)", []string{Constructing, Upgrading}).UpdateColumns(values)
+	if query.Error != nil {
+		return query.Error
+	}
+	if query.RowsAffected == 0 {
+		return nil
+	}
+	if err := b.freeWorker(txn); err != nil {
+		return err
 	}
+	txn.DbCommit()
+	return txn.Error
 }
 
 func (b *Building) setHiring(ctx context.Context) {
@@ -348,15 +406,39 @@ func (b *Building) Hiring(ctx context.Context) *BuildingHiring {
 }
 
 func (h *BuildingHiring) Join(ctx context.Context, account string, apostleTokenIds []string) error {
+	b := GetBuilding(ctx, h.BuildingId)
+	if b == nil || !util.StringInSlice(b.Status, []string{Constructing, Upgrading}) {
+		return ErrBuildingStatus
+	}
+	if !b.HiringStatus {
+		return ErrHiringClosed
+	}
 	apostles := getApostleReversalKey(ctx, apostleTokenIds)
+	if len(apostles) != len(apostleTokenIds) {
+		return ErrBuildingApostle
+	}
 	for _, apostle := range apostles {
 		if !strings.EqualFold(apostle.Owner, account) || apostle.Status != apostleFresh {
-			return errors.New("error apostle")
+			return ErrBuildingApostle
 		}
 	}
 	txn := util.DbBegin(ctx)
 	defer txn.Rollback()
 
+	// joins of a building wait for the lock of its hiring, so workers are counted with the ones just joined
+	var hiring BuildingHiring
+	if err := txn.Set("gorm:query_option", "FOR UPDATE").First(&hiring, h.BuildingId).Error; err != nil {
+		return err
+	}
+	var joined int
+	if err := txn.Model(BuildingWorker{}).Where("building_id = ?", h.BuildingId).Count(&joined).Error; err != nil {
+		return err
+	}
+	workers := joined + len(apostleTokenIds)
+	if workers > int(hiring.WorkerLimit) || workers > b.Drawing().MinerLimit {
+		return ErrMinerLimit
+	}
+
 	var insertRecords []interface{}
 	for _, tokenId := range apostleTokenIds {
 		insertRecords = append(insertRecords, BuildingWorker{BuildingId: h.BuildingId, ApostleTokenId: tokenId})
@@ -365,8 +447,8 @@ func (h *BuildingHiring) Join(ctx context.Context, account string, apostleTokenI
 		return err
 	}
 
-	txn.Model(Building{}).UpdateColumn(map[string]interface{}{
-		"construction_duration": gorm.Expr("construction_sub + ?", len(apostleTokenIds)*perWorkerSubTime),
+	txn.Model(Building{}).Where("id = ?", h.BuildingId).UpdateColumn(map[string]interface{}{
+		"construction_sub": gorm.Expr("construction_sub + ?", len(apostleTokenIds)*perWorkerSubTime),
 	})
 	query := txn.Model(Apostle{}).Where("token_id in (?)", apostleTokenIds).Where("status =?", apostleFresh).Update(Apostle{Status: apostleBuild})
 	if int(query.RowsAffected) != len(apostleTokenIds) {
@@ -412,7 +494,7 @@ func (b *BuildingAdminHiring) Update(ctx context.Context, payment string, amount
 		return errors.New("error worker limit")
 	}
 	query := util.WithContextDb(ctx).Model(b).UpdateColumn(map[string]interface{}{
-		"payment": payment, "payment_amount": amount, "talent_limit": talentLimit, "worker_limit": adminLimit, "duration": duration,
+		"payment": payment, "payment_amount": amount, "talent_limit": talentLimit, "admin_limit": adminLimit, "duration": duration,
 	})
 	return query.Error
 }
@@ -424,6 +506,7 @@ func (b *BuildingAdminHiring) Admins(ctx context.Context) (list []string) {
 
 func (b *Building) CreateAdminHiring(ctx context.Context, payment string, amount decimal.Decimal, talentLimit, adminLimit uint, duration uint) error {
 	tx := util.WithContextDb(ctx).Create(&BuildingAdminHiring{
+		BuildingId:    b.ID,
 		Payment:       payment,
 		PaymentAmount: amount,
 		TalentLimit:   talentLimit,
@@ -441,22 +524,56 @@ func (b *Building) ToggleAdmin(ctx context.Context) {
 }
 
 func (b *BuildingAdminHiring) Join(ctx context.Context, owner, account string, apostleTokenIds []string) error {
+	building := GetBuilding(ctx, b.BuildingId)
+	if building == nil || building.Status != Idle {
+		return ErrBuildingStatus
+	}
+	isEmployment := !strings.EqualFold(owner, account)
+	if isEmployment && !building.AdminHiringStatus {
+		return ErrHiringClosed
+	}
 	apostles := getApostleReversalKey(ctx, apostleTokenIds)
+	if len(apostles) != len(apostleTokenIds) {
+		return ErrBuildingApostle
+	}
 	for _, apostle := range apostles {
 		if !strings.EqualFold(apostle.Owner, account) || apostle.Status != apostleFresh {
-			return errors.New("error apostle")
+			return ErrBuildingApostle
 		}
 	}
 
 	txn := util.DbBegin(ctx)
 	defer txn.Rollback()
 
+	// joins of a building wait for the lock of its admin hiring, so admins are counted with the ones just joined
+	var hiring BuildingAdminHiring
+	if err := txn.Set("gorm:query_option", "FOR UPDATE").First(&hiring, b.BuildingId).Error; err != nil {
+		return err
+	}
+	var joined, employments int
+	if err := txn.Model(BuildingAdmin{}).Where("building_id = ?", b.BuildingId).Count(&joined).Error; err != nil {
+		return err
+	}
+	if err := txn.Model(BuildingAdmin{}).Where("building_id = ? AND is_employment = ?", b.BuildingId, true).Count(&employments).Error; err != nil {
+		return err
+	}
+	drawing := building.Drawing()
+	admins := joined + len(apostleTokenIds)
+	if admins > int(hiring.AdminLimit) || admins > drawing.AdminLimit {
+		return ErrAdminLimit
+	}
+	if isEmployment && employments+len(apostleTokenIds) > drawing.AdminEmploymentLimit {
+		return ErrAdminLimit
+	}
+
 	apostle := Apostle{Status: apostleBuildAdmin}
-	isEmployment := owner != account
 	if isEmployment {
 		apostle.WorkerEnd = b.Duration*86400 + uint(time.Now().Unix())
 	}
-	txn.Model(Apostle{}).Where("token_id in (?)", apostleTokenIds).Update()
+	query := txn.Model(Apostle{}).Where("token_id in (?)", apostleTokenIds).Where("status = ?", apostleFresh).Update(apostle)
+	if int(query.RowsAffected) != len(apostleTokenIds) {
+		return errors.New("join admin hiring fail")
+	}
 
 	var insertRecords []interface{}
 	for _, tokenId := range apostleTokenIds {
@@ -469,30 +586,58 @@ func (b *BuildingAdminHiring) Join(ctx context.Context, owner, account string, a
 	return nil
 }
 
+// ExpiredBuildingAdmins employed admins whose worker_end passed at now
+func ExpiredBuildingAdmins(ctx context.Context, now int64, limit int) []BuildingAdmin {
+	var list []BuildingAdmin
+	util.WithContextDb(ctx).Table("building_admins").Select("building_admins.*").
+		Joins("JOIN apostles ON apostles.token_id = building_admins.apostle_token_id").
+		Where("building_admins.is_employment = ?", true).
+		Where("apostles.status = ? AND apostles.worker_end > 0 AND apostles.worker_end <= ?", apostleBuildAdmin, now).
+		Limit(limit).Scan(&list)
+	return list
+}
+
+// Expire end the employment of admin when its duration is up
+func (a *BuildingAdmin) Expire(ctx context.Context) error {
+	apostle := GetApostleByTokenId(ctx, a.ApostleTokenId)
+	if apostle == nil {
+		return ErrBuildingApostle
+	}
+	hiring := BuildingAdminHiring{BuildingId: a.BuildingId}
+	return hiring.WorkEnds(ctx, apostle.Owner, a.ApostleTokenId)
+}
+
 func (b *BuildingAdminHiring) WorkEnds(ctx context.Context, account string, tokenId string) error {
 	apostle := GetApostleByTokenId(ctx, tokenId)
 	if apostle == nil || !strings.EqualFold(apostle.Owner, account) || apostle.Status != apostleBuildAdmin {
-		return errors.New("error apostle")
+		return ErrBuildingApostle
 	}
 	txn := util.DbBegin(ctx)
 	defer txn.Rollback()
-	txn.Model(Apostle{}).Where("token_id  = ?", tokenId).Update(Apostle{Status: apostleFresh})
-	if query := txn.Where("apostle_token_id = ? ", tokenId).Delete(&BuildingAdmin{}); query.Error != nil {
+	query := txn.Where("building_id = ? AND apostle_token_id = ?", b.BuildingId, tokenId).Delete(&BuildingAdmin{})
+	if query.Error != nil {
 		return query.Error
 	}
+	if query.RowsAffected == 0 {
+		return ErrBuildingApostle
+	}
+	txn.Model(Apostle{}).Where("token_id  = ?", tokenId).UpdateColumn(map[string]interface{}{"status": apostleFresh, "worker_end": 0})
 	txn.DbCommit()
 	return nil
 }
 
-func (b *Building) freeWorker(ctx context.Context) error {
+func (b *Building) freeWorker(txn *util.GormDB) error {
 	var list []string
-	util.WithContextDb(ctx).Model(BuildingWorker{}).Where("building_id = ?", b.ID).Pluck("apostle_token_id", &list)
-	txn := util.DbBegin(ctx)
-	defer txn.Rollback()
-	txn.Model(Apostle{}).Where("token_id in (?)", list).Update(Apostle{Status: apostleFresh})
-	util.WithContextDb(ctx).Where("building_id = ?", b.ID).Delete(BuildingWorker{})
-	txn.DbCommit()
-	return nil
+	if query := txn.Model(BuildingWorker{}).Where("building_id = ?", b.ID).Pluck("apostle_token_id", &list); query.Error != nil {
+		return query.Error
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	if query := txn.Model(Apostle{}).Where("token_id in (?)", list).Where("status = ?", apostleBuild).Update(Apostle{Status: apostleFresh}); query.Error != nil {
+		return query.Error
+	}
+	return txn.Where("building_id = ?", b.ID).Delete(BuildingWorker{}).Error
 }
 
 func (b *Building) renderBuildingApostle(ctx context.Context, apostleTokenIds []string) (list []BuildingApostle) {
@@ -512,7 +657,7 @@ func (b *Building) renderBuildingApostle(ctx context.Context, apostleTokenIds []
 func (d *Drawing) BuildingNearLand(ctx context.Context, tokenId string) ([]*Land, error) {
 	land := getLand(ctx, tokenId)
 	if land == nil || land.BuildingId > 0 {
-		return nil, errors.New("error land tokenId or this land already building")
+		return nil, ErrBuildingLand
 	}
 	coords := checkLandNear(land.Lon, land.Lat, d.Index)
 
@@ -529,11 +674,11 @@ func (d *Drawing) BuildingNearLand(ctx context.Context, tokenId string) ([]*Land
 		y := util.StringToInt(coordSlice[1])
 		if x < xRange[0] || x > xRange[1] || y < yRange[0] || y > yRange[1] {
 			log.Debug("CreateBuilding info. x=%d. y=%d. xRange=%v; yRange=%v", x, y, xRange, yRange)
-			return nil, errors.New("land choose out of range")
+			return nil, ErrBuildingRange
 		}
 		land := GetLandByCoord(ctx, x, y)
-		if land.Status != landFresh || land.BuildingId > 0 {
-			return nil, errors.New("error land tokenId or this land already building")
+		if land == nil || land.Status != landFresh || land.BuildingId > 0 {
+			return nil, ErrBuildingLand
 		}
 		lands = append(lands, land)
 	}
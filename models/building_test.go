@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/config"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_buildingUpgradeComplete(t *testing.T) {
+	config.InitApplication()
+	util.Panic(util.InitMysql())
+	util.Panic(MigrationDbTable())
+
+	var (
+		ctx = context.Background()
+		db  = util.WithContextDb(ctx)
+		now = time.Now().Unix()
+	)
+	building := Building{TokenId: util.RandStr(32), Level: 1, Status: Upgrading, ConstructionStartAt: int(now) - 100, ConstructionDuration: 200, ConstructionSub: 150}
+	assert.NoError(t, db.Create(&building).Error)
+	defer db.Delete(&building)
+	apostle := Apostle{TokenId: util.RandStr(32), Status: apostleBuild}
+	assert.NoError(t, db.Create(&apostle).Error)
+	defer db.Unscoped().Delete(&apostle)
+	assert.NoError(t, db.Create(&BuildingWorker{BuildingId: building.ID, ApostleTokenId: apostle.TokenId}).Error)
+	defer db.Where("building_id = ?", building.ID).Delete(BuildingWorker{})
+
+	// workers joined shortened the timer, it is due
+	assert.Equal(t, int(now)-50, building.FinishAt())
+	var due *Building
+	for _, b := range DueBuildings(ctx, now, 500) {
+		if b.ID == building.ID {
+			due = &b
+		}
+	}
+	if !assert.NotNil(t, due) {
+		return
+	}
+
+	// completed once by timers of several instances
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(b Building) {
+			defer wg.Done()
+			assert.NoError(t, b.UpgradeComplete(ctx))
+		}(*due)
+	}
+	wg.Wait()
+	assert.NoError(t, due.UpgradeComplete(ctx))
+
+	db.First(&building, building.ID)
+	assert.Equal(t, 2, building.Level)
+	assert.Equal(t, Idle, building.Status)
+	db.First(&apostle, apostle.ID)
+	assert.Equal(t, apostleFresh, apostle.Status)
+	var workers int
+	db.Model(BuildingWorker{}).Where("building_id = ?", building.ID).Count(&workers)
+	assert.Zero(t, workers)
+	for _, b := range DueBuildings(ctx, now, 500) {
+		assert.NotEqual(t, building.ID, b.ID)
+	}
+}
+
+func Test_buildingHiringJoin(t *testing.T) {
+	config.InitApplication()
+	util.Panic(util.InitMysql())
+	util.Panic(MigrationDbTable())
+
+	var (
+		ctx     = context.Background()
+		db      = util.WithContextDb(ctx)
+		account = "0x" + util.RandStr(20)
+	)
+	defer func(d []Drawing) { drawings = d }(drawings)
+	drawings = []Drawing{{Index: 1, MinerLimit: 10}}
+	building := Building{TokenId: util.RandStr(32), DrawingIndex: 1, Status: Constructing, HiringStatus: true}
+	assert.NoError(t, db.Create(&building).Error)
+	defer db.Delete(&building)
+	hiring := BuildingHiring{BuildingId: building.ID, WorkerLimit: 1}
+	assert.NoError(t, db.Create(&hiring).Error)
+	defer db.Delete(&hiring)
+	defer db.Where("building_id = ?", building.ID).Delete(BuildingWorker{})
+	var tokenIds []string
+	for i := 0; i < 3; i++ {
+		apostle := Apostle{TokenId: util.RandStr(32), Owner: account, Status: apostleFresh}
+		assert.NoError(t, db.Create(&apostle).Error)
+		defer db.Unscoped().Delete(&apostle)
+		tokenIds = append(tokenIds, apostle.TokenId)
+	}
+
+	// concurrent joins are counted against the limit one after another
+	var (
+		wg     sync.WaitGroup
+		joined = make(chan bool, len(tokenIds))
+	)
+	for _, tokenId := range tokenIds {
+		wg.Add(1)
+		go func(tokenId string) {
+			defer wg.Done()
+			joined <- hiring.Join(ctx, account, []string{tokenId}) == nil
+		}(tokenId)
+	}
+	wg.Wait()
+	close(joined)
+	var succeeded int
+	for ok := range joined {
+		if ok {
+			succeeded++
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Len(t, hiring.Workers(ctx), 1)
+}
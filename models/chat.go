@@ -21,4 +21,5 @@ func broadcastChannel(ctx context.Context, content, ContentType string) {
 	util.WithContextDb(ctx).Create(&c)
 	publishBytes, _ := json.Marshal(publish)
 	_, _ = util.SubPoolWithContextDo(ctx)("publish", "consensus-chat", string(publishBytes))
+	PublishStreamEvent(ctx, &StreamEvent{Type: StreamBroadcast, Data: publish})
 }
@@ -168,7 +168,7 @@ type ListOpt struct {
 	Order      string
 	Display    string
 	Filter     string
-	WhereQuery []interface{}
+	WhereQuery util.Wheres
 	Chain      string
 }
 
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -24,6 +25,8 @@ type Dapp struct {
 }
 
 type DappJson struct {
+	ID           uint   `json:"id"`
+	LandId       uint   `json:"land_id"`
 	Name         string `json:"name"`
 	Status       string `json:"status"`
 	Category     string `json:"category"`
@@ -60,11 +63,42 @@ type DappReport struct {
 	Remark string `json:"remark"`
 }
 
+type DappReportJson struct {
+	ID        uint      `json:"id"`
+	DappId    uint      `json:"dapp_id"`
+	Reason    []string  `json:"reason"`
+	Remark    string    `json:"remark"`
+	CreatedAt int64     `json:"created_at"`
+	Dapp      *DappJson `json:"dapp"`
+}
+
+type DappQuery struct {
+	Category string
+	Status   string
+	Page     int
+	Row      int
+}
+
+type DappReportQuery struct {
+	DappId uint
+	Page   int
+	Row    int
+}
+
 var DappCategory = []string{"Game", "Social", "Tool", "MarketPlace", "Casino", "Other"}
 
 const (
 	DappSubmitted     = "submitted"
 	DappSubmitSuccess = "success"
+	DappRejected      = "rejected"
+	DappBanned        = "banned"
+)
+
+var (
+	ErrDappExist      = errors.New("land already has dapp")
+	ErrDappLandOwner  = errors.New("not owner of land")
+	ErrDappStatus     = errors.New("error dapp status")
+	ErrDappNotPublish = errors.New("dapp not published")
 )
 
 func (va *ValidateAddDapp) AddDapp(ctx context.Context, memberId uint, status ...string) error {
@@ -79,6 +113,8 @@ func (va *ValidateAddDapp) AddDapp(ctx context.Context, memberId uint, status ..
 	return query.Error
 }
 
+// EditDapp submit a new version of dapp for review, the published one is online until it is approved.
+// A version already waiting for review is replaced
 func (va *ValidateEditDapp) EditDapp(ctx context.Context, status ...string) error {
 	db := util.WithContextDb(ctx)
 	dapp := Dapp{Name: va.Name, Category: va.Category, Introduction: va.Introduction, Cover: va.Cover, Email: va.Email, Url: va.Url}
@@ -88,6 +124,12 @@ func (va *ValidateEditDapp) EditDapp(ctx context.Context, status ...string) erro
 	}
 	dapp.MemberId = va.DappInstant.MemberId
 	dapp.LandId = va.DappInstant.LandId
+	if dapp.Status == DappSubmitted {
+		var submitted Dapp
+		if query := db.Where("land_id = ? AND status = ?", dapp.LandId, DappSubmitted).First(&submitted); query.Error == nil {
+			return db.Model(&submitted).Updates(dapp).Error
+		}
+	}
 	query := db.Create(&dapp)
 	return query.Error
 }
@@ -97,10 +139,96 @@ func (dapp *Dapp) Del(txn *util.GormDB) error {
 	return query.Error
 }
 
-func (dapp *Dapp) Report(ctx context.Context, reason []string, remark string) {
+func (dapp *Dapp) Report(ctx context.Context, reason []string, remark string) error {
 	db := util.WithContextDb(ctx)
 	report := DappReport{Reason: strings.Join(reason, "|"), Remark: remark, DappId: dapp.ID}
-	db.Create(&report)
+	return db.Create(&report).Error
+}
+
+func (dapp *Dapp) AsJson() DappJson {
+	return DappJson{
+		ID: dapp.ID, LandId: dapp.LandId, Name: dapp.Name, Status: dapp.Status, Category: dapp.Category,
+		Introduction: dapp.Introduction, Cover: dapp.Cover, Email: dapp.Email, Url: dapp.Url,
+	}
+}
+
+// SetStatus moderate dapp, land shows the dapp approved as success. Older version of the land in the same
+// status is dropped, and pending reports are resolved when dapp is banned
+func (dapp *Dapp) SetStatus(ctx context.Context, status string) error {
+	if !util.StringInSlice(status, []string{DappSubmitSuccess, DappRejected, DappBanned}) {
+		return ErrDappStatus
+	}
+	txn := util.DbBegin(ctx)
+	defer txn.DbRollback()
+	if query := txn.Where("land_id = ? AND status = ? AND id <> ?", dapp.LandId, status, dapp.ID).Delete(Dapp{}); query.Error != nil {
+		return query.Error
+	}
+	if query := txn.Model(dapp).UpdateColumn("status", status); query.Error != nil {
+		return query.Error
+	}
+	landQuery := txn.Model(Land{}).Where("id = ?", dapp.LandId)
+	if status == DappSubmitSuccess {
+		landQuery = landQuery.UpdateColumn("dapp_id", dapp.ID)
+	} else {
+		landQuery = landQuery.Where("dapp_id = ?", dapp.ID).UpdateColumn("dapp_id", 0)
+	}
+	if landQuery.Error != nil {
+		return landQuery.Error
+	}
+	if status == DappBanned {
+		if query := txn.Where("dapp_id = ?", dapp.ID).Delete(DappReport{}); query.Error != nil {
+			return query.Error
+		}
+	}
+	txn.DbCommit()
+	return txn.Error
+}
+
+// List dapps, category and status are optional
+func (q *DappQuery) List(ctx context.Context) (list []Dapp, count int) {
+	db := util.WithContextDb(ctx).Model(Dapp{})
+	if q.Category != "" {
+		db = db.Where("category = ?", q.Category)
+	}
+	if q.Status != "" {
+		db = db.Where("status = ?", q.Status)
+	}
+	db.Count(&count)
+	db.Order("id desc").Offset(q.Page * q.Row).Limit(q.Row).Find(&list)
+	return
+}
+
+// List reports waiting for review, reviewed reports are soft deleted
+func (q *DappReportQuery) List(ctx context.Context) (list []DappReport, count int) {
+	db := util.WithContextDb(ctx).Model(DappReport{})
+	if q.DappId > 0 {
+		db = db.Where("dapp_id = ?", q.DappId)
+	}
+	db.Count(&count)
+	db.Order("id asc").Offset(q.Page * q.Row).Limit(q.Row).Find(&list)
+	return
+}
+
+func GetDappReport(ctx context.Context, id uint) *DappReport {
+	var report DappReport
+	if query := util.WithContextDb(ctx).First(&report, id); query.Error != nil {
+		return nil
+	}
+	return &report
+}
+
+func (r *DappReport) AsJson(ctx context.Context) DappReportJson {
+	j := DappReportJson{ID: r.ID, DappId: r.DappId, Reason: strings.Split(r.Reason, "|"), Remark: r.Remark, CreatedAt: r.CreatedAt.Unix()}
+	if dapp := GetDappById(ctx, r.DappId); dapp != nil {
+		dj := dapp.AsJson()
+		j.Dapp = &dj
+	}
+	return j
+}
+
+// Resolve mark report reviewed
+func (r *DappReport) Resolve(ctx context.Context) error {
+	return util.WithContextDb(ctx).Delete(r).Error
 }
 
 func GetDappById(ctx context.Context, id uint) *Dapp {
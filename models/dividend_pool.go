@@ -3,10 +3,9 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 )
 
 func (ec *EthTransactionCallback) DividendPoolCallback(ctx context.Context) (err error) {
@@ -18,16 +17,13 @@ func (ec *EthTransactionCallback) DividendPoolCallback(ctx context.Context) (err
 	defer db.DbRollback()
 	exec := false
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("dividendPool", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("TransferredChannelDividend(address,uint256)"):
-				// logSlice := services.LogAnalysis(log.Data)
-				// airDropFromKton(ec.Tx, util.U256(logSlice[0]))
-				exec = true
-			}
-		}
+	err = ec.eachEvent(util.GetContractAddress("dividendPool", chain), events.On(func(ev *TransferredChannelDividend) error {
+		// airDropFromKton(ec.Tx, ev.Value)
+		exec = true
+		return nil
+	}))
+	if err != nil {
+		return err
 	}
 	if !exec {
 		return nil
@@ -94,11 +94,9 @@ func Drills(ctx context.Context, opt ListOpt, owner string, multiFilter DrillMul
 	)
 	query := util.WithContextDb(ctx).Model(Drill{})
 
-	for _, q := range opt.WhereQuery {
-		query = query.Where(q)
-	}
+	query = opt.WhereQuery.Apply(query)
 	if opt.Order != "" {
-		query = query.Order(fmt.Sprintf("formula_id %s", opt.Order))
+		query = query.Order("formula_id " + util.SortDirection(opt.Order))
 	}
 
 	query.Find(&list)
@@ -11,9 +11,9 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/evolutionlandorg/block-scan/services"
-	evoServices "github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/evolutionlandorg/evo-backend/util/log"
 	"github.com/jinzhu/gorm"
 	jsoniter "github.com/json-iterator/go"
@@ -63,45 +63,17 @@ func (ec *EthTransactionCallback) raffleCallback(ctx context.Context, element st
 	defer txn.DbRollback()
 	chain := ec.Receipt.ChainSource
 	address := []string{
-		strings.ToLower(util.GetContractAddress("GoldRaffle", chain)),
-		strings.ToLower(util.GetContractAddress("WoodRaffle", chain)),
-		strings.ToLower(util.GetContractAddress("WaterRaffle", chain)),
-		strings.ToLower(util.GetContractAddress("FireRaffle", chain)),
-		strings.ToLower(util.GetContractAddress("SoilRaffle", chain)),
-		strings.ToLower(util.GetContractAddress("objectOwnership", chain)),
+		util.GetContractAddress("GoldRaffle", chain),
+		util.GetContractAddress("WoodRaffle", chain),
+		util.GetContractAddress("WaterRaffle", chain),
+		util.GetContractAddress("FireRaffle", chain),
+		util.GetContractAddress("SoilRaffle", chain),
+		util.GetContractAddress("objectOwnership", chain),
 	}
 	var waitInsertData = make(map[string]*ElementRaffle)
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) == 0 || !util.StringInSlice(strings.ToLower(util.AddHex(log.Address, chain)), address) {
-			continue
-		}
-		var drawMethod string
-		switch util.AddHex(log.Topics[0]) {
-		case evoServices.AbiEncodingMethod("Transfer(address,address,uint256)"):
-			to := util.AddHex(util.TrimHex(log.Topics[2])[24:64], chain)
-			var tokenId string
-			if len(log.Topics) < 4 {
-				tokenId = util.TrimHex(log.Data)
-			} else {
-				tokenId = util.TrimHex(log.Topics[3])
-			}
-			if v, ok := waitInsertData[strings.ToLower(to)]; ok && v.IsWin {
-				waitInsertData[strings.ToLower(to)].PrizeTokenId = tokenId
-				continue
-			}
-			waitInsertData[strings.ToLower(to)] = &ElementRaffle{PrizeTokenId: tokenId}
-			continue
-		case evoServices.AbiEncodingMethod("LargeDraw(address,uint256,uint8)"):
-			drawMethod = "Large"
-		case evoServices.AbiEncodingMethod("SmallDraw(address,uint256,uint8)"):
-			drawMethod = "Small"
-		default:
-			continue
-		}
-		logSlice := util.LogAnalysis(log.Data)
-		prizeType := util.U256(logSlice[2]).Int64()
+	var draw = func(owner common.Address, prizeType int64, drawMethod string) {
 		e := &ElementRaffle{
-			Owner:      util.AddHex(util.TrimHex(logSlice[0])[24:64], chain),
+			Owner:      eventAddress(owner, chain),
 			Chain:      chain,
 			Element:    element,
 			Timestamp:  ec.BlockTimestamp,
@@ -110,10 +82,32 @@ func (ec *EthTransactionCallback) raffleCallback(ctx context.Context, element st
 			IsWin:      prizeType != 0,
 			PrizeType:  GetAssetTypeById(int(prizeType)),
 		}
-		if v, ok := waitInsertData[strings.ToLower(e.Owner)]; ok && v.PrizeTokenId != "" && e.IsWin {
+		if v, ok := waitInsertData[e.Owner]; ok && v.PrizeTokenId != "" && e.IsWin {
 			e.PrizeTokenId = v.PrizeTokenId
 		}
-		waitInsertData[strings.ToLower(e.Owner)] = e
+		waitInsertData[e.Owner] = e
+	}
+	err := ec.eachEventOf(address,
+		events.On(func(ev *Transfer) error {
+			to := eventAddress(ev.To, chain)
+			if v, ok := waitInsertData[to]; ok && v.IsWin {
+				v.PrizeTokenId = ev.tokenId()
+				return nil
+			}
+			waitInsertData[to] = &ElementRaffle{PrizeTokenId: ev.tokenId()}
+			return nil
+		}),
+		events.On(func(ev *LargeDraw) error {
+			draw(ev.User, int64(ev.PrizeType), "Large")
+			return nil
+		}),
+		events.On(func(ev *SmallDraw) error {
+			draw(ev.User, int64(ev.PrizeType), "Small")
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
 	}
 	for _, v := range waitInsertData {
 		txn.Create(v)
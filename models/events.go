@@ -0,0 +1,395 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
+)
+
+// typed contract events, struct name is the event name and fields are abi.ToCamelCase(input name)
+
+// TakedBack takeBack/TakeBackKton
+type TakedBack struct {
+	User  common.Address
+	Nonce *big.Int
+	Value *big.Int
+}
+
+// TakebackMaterial materialTakeBack
+type TakebackMaterial struct {
+	Account common.Address
+	Nonce   *big.Int
+	Id      *big.Int
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+// UpdateTesterRole rolesUpdater
+type UpdateTesterRole struct {
+	User           common.Address
+	Nonce          *big.Int
+	TesterCodeHash [32]byte
+}
+
+// AuctionCreated clockAuction/clockAuctionApostle, StartedAt is only emitted by clockAuctionApostle
+type AuctionCreated struct {
+	TokenId              *big.Int
+	Seller               common.Address
+	StartingPriceInToken *big.Int
+	EndingPriceInToken   *big.Int
+	Duration             *big.Int
+	Token                common.Address
+	StartedAt            *big.Int
+}
+
+type AuctionSuccessful struct {
+	TokenId    *big.Int
+	TotalPrice *big.Int
+	Winner     common.Address
+}
+
+type AuctionCancelled struct {
+	TokenId *big.Int
+}
+
+type NewBid struct {
+	TokenId            *big.Int
+	LastBidder         common.Address
+	LastReferer        common.Address
+	LastRecord         *big.Int
+	TokenAddress       common.Address
+	BidStartAt         *big.Int
+	ReturnToLastBidder *big.Int
+}
+
+// Transfer erc20 (Value) or erc721 (TokenId indexed), cryptoKitties indexes none of the inputs
+type Transfer struct {
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+	TokenId *big.Int
+}
+
+// tokenId 64 hex chars token id, in data when token id is not indexed
+func (ev *Transfer) tokenId() string {
+	if ev.TokenId != nil {
+		return eventTokenId(ev.TokenId)
+	}
+	return eventTokenId(ev.Value)
+}
+
+// RoleAdded userRoles
+type RoleAdded struct {
+	Operator common.Address
+	Role     string
+}
+
+// Birth apostle
+type Birth struct {
+	Owner          common.Address
+	ApostleTokenId *big.Int
+	MatronId       *big.Int
+	SireId         *big.Int
+	Genes          *big.Int
+	Talents        *big.Int
+	CoolDownIndex  *big.Int
+	Generation     *big.Int
+	BirthTime      *big.Int
+}
+
+// Pregnant apostle
+type Pregnant struct {
+	MatronId              *big.Int
+	MatronCoolDownEndTime *big.Int
+	MatronCoolDownIndex   *big.Int
+	SireId                *big.Int
+	SireCoolDownEndTime   *big.Int
+	SireCoolDownIndex     *big.Int
+}
+
+// Tied petBase
+type Tied struct {
+	ApostleTokenId  *big.Int
+	MirrorTokenId   *big.Int
+	EnhancedTalents *big.Int
+	Changed         bool
+	OriginNFT       common.Address
+	Owner           common.Address
+}
+
+type UnTied Tied
+
+// TakenBackNFT takeBackNFT
+type TakenBackNFT struct {
+	User    common.Address
+	Nonce   *big.Int
+	TokenId *big.Int
+}
+
+// OfferCreated tokenUse
+type OfferCreated struct {
+	TokenId          *big.Int
+	Duration         *big.Int
+	Price            *big.Int
+	AcceptedActivity common.Address
+	Owner            common.Address
+}
+
+type OfferCancelled struct {
+	TokenId *big.Int
+}
+
+type OfferTaken struct {
+	TokenId *big.Int
+	From    common.Address
+	Owner   common.Address
+	Now     *big.Int
+	EndTime *big.Int
+}
+
+type TokenUseRemoved struct {
+	TokenId  *big.Int
+	Owner    common.Address
+	User     common.Address
+	Activity common.Address
+}
+
+// StartMining landResource
+type StartMining struct {
+	MinerTokenId *big.Int
+	LandId       *big.Int
+	Resource     common.Address
+	Strength     *big.Int
+}
+
+type StopMining StartMining
+
+type ResourceClaimed struct {
+	Owner        common.Address
+	LandTokenId  *big.Int
+	GoldBalance  *big.Int
+	WoodBalance  *big.Int
+	WaterBalance *big.Int
+	FireBalance  *big.Int
+	SoilBalance  *big.Int
+}
+
+type UpdateMiningStrengthWhenStart struct {
+	ApostleTokenId *big.Int
+	LandId         *big.Int
+	Strength       *big.Int
+}
+
+// Equip the land bar equip of landResource, not the apostle equip of apostle
+type Equip struct {
+	TokenId  *big.Int
+	Resource common.Address
+	Index    *big.Int
+	Staker   common.Address
+	Token    common.Address
+	Id       *big.Int
+}
+
+type Divest Equip
+
+// GoldBoxSale DrillLuckyBox/luckyBag
+type GoldBoxSale struct {
+	Buyer  common.Address
+	Amount *big.Int
+	Price  *big.Int
+}
+
+type SilverBoxSale GoldBoxSale
+
+// OpenBox DrillTakeBack
+type OpenBox struct {
+	User    common.Address
+	BoxId   *big.Int
+	TokenId *big.Int
+	Value   *big.Int
+}
+
+// TakeBackDrill DrillTakeBack, Id is the member id
+type TakeBackDrill struct {
+	User    common.Address
+	Id      *big.Int
+	TokenId *big.Int
+}
+
+// ItemEnchanced Enchanced of ItemBase
+type ItemEnchanced struct {
+	User        common.Address
+	TokenId     *big.Int
+	Index       *big.Int
+	Rate        *big.Int
+	ObjClassExt uint16
+	Class       uint16
+	Grade       uint16
+	Prefer      uint16
+	Major       common.Address
+	Id          *big.Int
+	Minor       common.Address
+	Amount      *big.Int
+	Now         *big.Int
+}
+
+// ItemDisenchanted Disenchanted of ItemBase
+type ItemDisenchanted struct {
+	User    common.Address
+	TokenId *big.Int
+	Major   common.Address
+	Id      *big.Int
+	Minor   common.Address
+	Amount  *big.Int
+}
+
+// Crafted CraftBase
+type Crafted struct {
+	To        common.Address
+	TokenId   *big.Int
+	ObjId     *big.Int
+	Rarity    *big.Int
+	Prefer    *big.Int
+	Timestamp *big.Int
+}
+
+// Enchanced CraftBase
+type Enchanced struct {
+	Id        *big.Int
+	Class     uint8
+	Timestamp *big.Int
+}
+
+type Disenchanted Enchanced
+
+// TransferredChannelDividend dividendPool
+type TransferredChannelDividend struct {
+	Dest  common.Address
+	Value *big.Int
+}
+
+// LargeDraw GoldRaffle/WoodRaffle/WaterRaffle/FireRaffle/SoilRaffle, PrizeType is the asset type id, 0 means no prize
+type LargeDraw struct {
+	User      common.Address
+	Amount    *big.Int
+	PrizeType uint8
+}
+
+type SmallDraw LargeDraw
+
+// EVOHarbergerBuy lootBox
+type EVOHarbergerBuy struct {
+	Buyer   common.Address
+	TokenId *big.Int
+	Price   *big.Int
+	ChainId *big.Int
+}
+
+// SwapIn nftBridge, OriginContract is only emitted by the pet bridge
+type SwapIn struct {
+	OriginContract common.Address
+	OriginTokenId  *big.Int
+	MirrorTokenId  *big.Int
+	Owner          common.Address
+}
+
+type SwapOut SwapIn
+
+// RewardClaimedWithPoints pointsReward
+type RewardClaimedWithPoints struct {
+	User         common.Address
+	PointAmount  *big.Int
+	RewardAmount *big.Int
+}
+
+// RingBurndropTokens tokenBurnDrop
+type RingBurndropTokens struct {
+	Token  common.Address
+	Owner  common.Address
+	Amount *big.Int
+	Data   []byte
+}
+
+type KtonBurndropTokens RingBurndropTokens
+
+// TokenSwapped tokenSwap, To is the address on the other chain in the low 20 bytes
+type TokenSwapped struct {
+	SwapId     *big.Int
+	From       common.Address
+	To         [32]byte
+	Amount     *big.Int
+	Token      common.Address
+	Fee        *big.Int
+	SrcNetwork *big.Int
+	DstNetwork *big.Int
+}
+
+func init() {
+	// events without abi file
+	for _, signature := range []string{
+		"TakebackMaterial(address account, uint256 nonce, uint128 id, uint256 tokenId, uint256 amount)",
+		"OpenBox(address indexed user, uint256 indexed boxId, uint256 tokenId, uint256 value)",
+		"TakeBackDrill(address indexed user, uint256 indexed id, uint256 tokenId)",
+		"Disenchanted(uint256 id, uint8 class, uint256 timestamp)",
+		"LargeDraw(address user, uint256 amount, uint8 prizeType)",
+		"SmallDraw(address user, uint256 amount, uint8 prizeType)",
+		"EVOHarbergerBuy(address buyer, uint256 tokenId, uint256 price, uint256 chainId)",
+		"SwapIn(address originContract, uint256 originTokenId, uint256 mirrorTokenId, address owner)",
+		"SwapOut(address originContract, uint256 originTokenId, uint256 mirrorTokenId, address owner)",
+		"RingBurndropTokens(address indexed token, address indexed owner, uint256 amount, bytes data)",
+		"KtonBurndropTokens(address indexed token, address indexed owner, uint256 amount, bytes data)",
+		// the Transfer of cryptoKitties indexes none of the inputs, unlike its abi file
+		"Transfer(address from, address to, uint256 tokenId)",
+		// luckyBag.abi names the inputs _user, _amount, _price
+		"GoldBoxSale(address indexed buyer, uint256 amount, uint256 price)",
+		"SilverBoxSale(address indexed buyer, uint256 amount, uint256 price)",
+	} {
+		events.MustRegister(signature)
+	}
+	// overloads of the Enchanced/Disenchanted of CraftBase
+	events.MustRegisterAs("ItemEnchanced", "Enchanced(address indexed user, uint256 indexed tokenId, uint256 index, uint128 rate, uint16 objClassExt, "+
+		"uint16 class, uint16 grade, uint16 prefer, address major, uint256 id, address minor, uint256 amount, uint256 now)")
+	events.MustRegisterAs("ItemDisenchanted", "Disenchanted(address indexed user, uint256 tokenId, address major, uint256 id, address minor, uint256 amount)")
+}
+
+// eachEvent dispatch typed events emitted by contract address in receipt logs to handlers
+func (ec *EthTransactionCallback) eachEvent(address string, handlers ...events.Handler) error {
+	return ec.eachEventOf([]string{address}, handlers...)
+}
+
+// eachEventOf dispatch typed events emitted by any of contract addresses in receipt logs to handlers
+func (ec *EthTransactionCallback) eachEventOf(addresses []string, handlers ...events.Handler) error {
+	chain := ec.Receipt.ChainSource
+	for _, l := range ec.Receipt.Logs {
+		if len(l.Topics) == 0 || !containsAddress(addresses, util.AddHex(l.Address, chain)) {
+			continue
+		}
+		if err := events.Handle(l, handlers...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if strings.EqualFold(a, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventAddress address of event as stored, with chain prefix
+func eventAddress(address common.Address, chain string) string {
+	return util.AddHex(strings.ToLower(util.TrimHex(address.Hex())), chain)
+}
+
+// eventTokenId 64 hex chars token id
+func eventTokenId(tokenId *big.Int) string {
+	return fmt.Sprintf("%064x", tokenId)
+}
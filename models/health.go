@@ -0,0 +1,146 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/services/storage"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/gomodule/redigo/redis"
+)
+
+const daemonSuccessKey = "DaemonSuccess"
+
+type DependencyStatus struct {
+	Ok      bool   `json:"ok"`
+	Latency int64  `json:"latency"` // ms
+	Error   string `json:"error,omitempty"`
+}
+
+type ScannerStatus struct {
+	Chain       string `json:"chain"`
+	Head        uint64 `json:"head"`   // latest block of rpc
+	Cursor      uint64 `json:"cursor"` // WipeBlock, next block to scan
+	Behind      int64  `json:"behind"`
+	LastSuccess int64  `json:"last_success"`
+	Error       string `json:"error,omitempty"`
+}
+
+type QueueStatus struct {
+	Queue string `json:"queue"`
+	Depth int    `json:"depth"`
+	Error string `json:"error,omitempty"`
+}
+
+type ServiceStatus struct {
+	Ok       bool             `json:"ok"`
+	Time     int64            `json:"time"`
+	Database DependencyStatus `json:"database"`
+	Redis    DependencyStatus `json:"redis"`
+	Scanners []ScannerStatus  `json:"scanners"`
+	Queues   []QueueStatus    `json:"queues"`
+	// last success of daemons, 0 if it never succeeded since the record expired
+	Daemons map[string]int64 `json:"daemons"`
+}
+
+// MarkDaemonSuccess record the last success of daemon, shared by instances running it
+func MarkDaemonSuccess(ctx context.Context, name string) {
+	util.SetMap(ctx, daemonSuccessKey, name, time.Now().Unix())
+}
+
+func daemonSuccessAt(ctx context.Context) map[string]int64 {
+	at, _ := redis.Int64Map(util.SubPoolWithContextDo(ctx)("HGETALL", fmt.Sprintf("evo:%s", daemonSuccessKey)))
+	return at
+}
+
+func dependencyStatus(ping func() error) DependencyStatus {
+	start := time.Now()
+	err := ping()
+	status := DependencyStatus{Ok: err == nil, Latency: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func PingDatabase(ctx context.Context) DependencyStatus {
+	return dependencyStatus(func() error {
+		return util.WithContextDb(ctx).DB().PingContext(ctx)
+	})
+}
+
+func PingRedis(ctx context.Context) DependencyStatus {
+	return dependencyStatus(func() error {
+		_, err := util.SubPoolWithContextDo(ctx)("PING")
+		return err
+	})
+}
+
+// scannerStatus head of chain against the WipeBlock cursor of scanner, rpc slower than timeout reports an error
+func scannerStatus(ctx context.Context, chain string, timeout time.Duration) ScannerStatus {
+	status := ScannerStatus{Chain: chain}
+	status.Cursor, _ = redis.Uint64(util.SubPoolWithContextDo(ctx)("HGET", "WipeBlock", chain))
+	head := make(chan uint64, 1)
+	go func() {
+		defer util.Recover(fmt.Sprintf("%s head error", chain))
+		head <- storage.New(chain).BlockNumber()
+	}()
+	select {
+	case status.Head = <-head:
+		if status.Head == 0 {
+			status.Error = "rpc unavailable"
+			break
+		}
+		status.Behind = int64(status.Head) - int64(status.Cursor)
+	case <-time.After(timeout):
+		status.Error = "rpc timeout"
+	}
+	return status
+}
+
+// GetServiceStatus status of dependencies, scanners of util.Evo.WipeBlock, worker queues and daemons.
+// daemons lists the last success of expected daemons and any other recorded
+func GetServiceStatus(ctx context.Context, daemons []string) *ServiceStatus {
+	s := &ServiceStatus{Time: time.Now().Unix(), Database: PingDatabase(ctx), Redis: PingRedis(ctx), Daemons: make(map[string]int64)}
+	s.Ok = s.Database.Ok && s.Redis.Ok
+
+	successAt := daemonSuccessAt(ctx)
+	for _, name := range daemons {
+		s.Daemons[name] = successAt[name]
+	}
+	for name, at := range successAt {
+		s.Daemons[name] = at
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for chain := range util.Evo.WipeBlock {
+		wg.Add(1)
+		go func(chain string) {
+			defer wg.Done()
+			status := scannerStatus(ctx, chain, 3*time.Second)
+			status.LastSuccess = successAt[fmt.Sprintf("WipeBlock:%s", chain)]
+			mu.Lock()
+			s.Scanners = append(s.Scanners, status)
+			mu.Unlock()
+		}(chain)
+	}
+	wg.Wait()
+	sort.Slice(s.Scanners, func(i, j int) bool { return s.Scanners[i].Chain < s.Scanners[j].Chain })
+
+	for _, chain := range util.Evo.Networks {
+		q := QueueStatus{Queue: fmt.Sprintf("%sProcess", strings.ToLower(chain))}
+		var err error
+		if q.Depth, err = util.QueueDepth(ctx, q.Queue); err != nil {
+			q.Error = err.Error()
+		}
+		s.Queues = append(s.Queues, q)
+	}
+	return s
+}
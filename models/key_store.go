@@ -6,8 +6,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/evolutionlandorg/evo-backend/services"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/jinzhu/gorm"
 )
 
@@ -25,21 +26,13 @@ func (ec *EthTransactionCallback) RolesUpdaterCallback(ctx context.Context) (err
 	}
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(log.Address, util.GetContractAddress("rolesUpdater")) {
-			eventName := log.Topics[0]
-			switch eventName {
-			case services.AbiEncodingMethod("UpdateTesterRole(address,uint256,bytes32)"):
-				logSlice := util.LogAnalysis(log.Data)
-				address := util.AddHex(log.Topics[1][26:66])
-				keyHash := util.AddHex(logSlice[0])
-				member := GetMemberByAddress(ctx, address, EthChain)
-				if member != nil {
-					member.UploadPlayRoleByKey(ctx, db, keyHash)
-				}
-			}
+	err = ec.eachEvent(util.GetContractAddress("rolesUpdater"), events.On(func(ev *UpdateTesterRole) error {
+		member := GetMemberByAddress(ctx, eventAddress(ev.User, EthChain), EthChain)
+		if member != nil {
+			member.UploadPlayRoleByKey(ctx, db, util.AddHex(common.Bytes2Hex(ev.TesterCodeHash[:])))
 		}
-	}
+		return nil
+	}))
 
 	if err != nil {
 		return err
@@ -56,21 +49,24 @@ func (ec *EthTransactionCallback) RolesUpdaterCallback(ctx context.Context) (err
 func (ec *EthTransactionCallback) UserRolesCallback(ctx context.Context) (err error) {
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(log.Address, util.GetContractAddress("userRoles")) {
-			// 忽略rolesUpdater 使用keystore 的情况
-			if len(ec.Receipt.Logs) == 2 && strings.EqualFold(ec.Receipt.Logs[1].Address, util.GetContractAddress("rolesUpdater")) {
-				break
-			}
-			eventName := log.Topics[0]
-			switch eventName {
-			case services.AbiEncodingMethod("RoleAdded(address,string)"):
-				member := GetMemberByAddress(ctx, util.AddHex(log.Topics[1][26:66]), TronChain)
-				if member != nil {
-					member.PlayerRole = 1
-					_ = member.updateField(ctx, db)
-				}
+	// 忽略rolesUpdater 使用keystore 的情况
+	if len(ec.Receipt.Logs) == 2 && strings.EqualFold(ec.Receipt.Logs[1].Address, util.GetContractAddress("rolesUpdater")) {
+		return nil
+	}
+	for _, l := range ec.Receipt.Logs {
+		if len(l.Topics) == 0 || !strings.EqualFold(l.Address, util.GetContractAddress("userRoles")) {
+			continue
+		}
+		err = events.Handle(l, events.On(func(ev *RoleAdded) error {
+			member := GetMemberByAddress(ctx, util.AddHex(strings.ToLower(util.TrimHex(ev.Operator.Hex()))), TronChain)
+			if member != nil {
+				member.PlayerRole = 1
+				_ = member.updateField(ctx, db)
 			}
+			return nil
+		}))
+		if err != nil {
+			return err
 		}
 	}
 	db.DbCommit()
@@ -152,7 +152,7 @@ type LandQuery struct {
 		Flag    []string
 		Owner   []string
 	}
-	WhereInterface []string
+	WhereInterface util.Wheres
 	TokenId        []string
 	Filter         string
 	MyLastBid      []string
@@ -172,6 +172,9 @@ type LandQuery struct {
 
 var landOrder = []string{"price", "gold_rate", "wood_rate", "water_rate", "fire_rate", "soil_rate"}
 
+// landColumnOrder order fields of AllLands sorted by sql, price is sorted after query
+var landColumnOrder = []string{"token_index", "gold_rate", "wood_rate", "water_rate", "fire_rate", "soil_rate"}
+
 func GenerateLandTokenId(chain string, tokenIndex int) string {
 	locationIndex := fmt.Sprintf("%032s", fmt.Sprintf("%x", tokenIndex))
 	tokenIndexPrefix := "2a010001010001010000000000000001%s"
@@ -249,9 +252,11 @@ func (l *Land) getLandAttenuationAt() int {
 }
 
 func (l *Land) UnclaimedResource() map[string]decimal.Decimal {
-	zero := decimal.Zero
-	resources := map[string]decimal.Decimal{"gold": zero, "wood": zero, "water": zero, "fire": zero, "soil": zero}
-	chain := GetChainByTokenId(l.TokenId)
+	return LandsUnclaimedResource(GetChainByTokenId(l.TokenId), []string{l.TokenId})[0]
+}
+
+// LandsUnclaimedResource unclaimed resources of lands of chain, read in one batch
+func LandsUnclaimedResource(chain string, tokenIds []string) []map[string]decimal.Decimal {
 	resourceAddress := []string{
 		util.GetContractAddress("gold", chain),
 		util.GetContractAddress("wood", chain),
@@ -260,15 +265,21 @@ func (l *Land) UnclaimedResource() map[string]decimal.Decimal {
 		util.GetContractAddress("soil", chain),
 	}
 	sg := storage.New(chain)
-	result := sg.UnclaimedResource(l.TokenId, resourceAddress)
-	if len(result) >= 5 {
-		resources["gold"] = util.BigToDecimal(util.U256(result[0]))
-		resources["wood"] = util.BigToDecimal(util.U256(result[1]))
-		resources["water"] = util.BigToDecimal(util.U256(result[2]))
-		resources["fire"] = util.BigToDecimal(util.U256(result[3]))
-		resources["soil"] = util.BigToDecimal(util.U256(result[4]))
+	results := sg.BatchUnclaimedResource(tokenIds, resourceAddress)
+	lands := make([]map[string]decimal.Decimal, len(tokenIds))
+	for i := range lands {
+		zero := decimal.Zero
+		resources := map[string]decimal.Decimal{"gold": zero, "wood": zero, "water": zero, "fire": zero, "soil": zero}
+		if result := results[i]; len(result) >= 5 {
+			resources["gold"] = util.BigToDecimal(util.U256(result[0]))
+			resources["wood"] = util.BigToDecimal(util.U256(result[1]))
+			resources["water"] = util.BigToDecimal(util.U256(result[2]))
+			resources["fire"] = util.BigToDecimal(util.U256(result[3]))
+			resources["soil"] = util.BigToDecimal(util.U256(result[4]))
+		}
+		lands[i] = resources
 	}
-	return resources
+	return lands
 }
 
 func (lq *LandQuery) LandList(ctx context.Context) (*[]LandJson, int) {
@@ -285,14 +296,12 @@ func (lq *LandQuery) LandList(ctx context.Context) (*[]LandJson, int) {
 	} else {
 		ori = db.Table("lands").Where(lq.WhereQuery)
 	}
-	for _, q := range lq.WhereInterface {
-		ori = ori.Where(q)
-	}
+	ori = lq.WhereInterface.Apply(ori)
 	// order
 	if lq.OrderField == "" || lq.OrderField == "price" {
 		ori = ori.Order("trans_time desc")
 	} else {
-		ori = ori.Order(fmt.Sprintf("%s %s", "token_index", lq.Order))
+		ori = ori.Order("token_index " + util.SortDirection(lq.Order))
 	}
 	query := ori.Scan(&land)
 
@@ -389,12 +398,10 @@ func (lq *LandQuery) AllLands(ctx context.Context) (lands []SampleLand, count in
 	if len(lq.TokenId) > 0 {
 		ori = ori.Where("lands.token_id in (?)", lq.TokenId)
 	}
-	for _, q := range lq.WhereInterface {
-		ori = ori.Where(fmt.Sprintf("lands.%s", q))
-	}
+	ori = lq.WhereInterface.Apply(ori)
 
-	if lq.OrderField != "price" {
-		ori = ori.Order(fmt.Sprintf("%s %s", lq.OrderField, lq.Order))
+	if order := util.OrderBy(lq.OrderField, lq.Order, landColumnOrder); order != "" {
+		ori = ori.Order(order)
 	}
 	query := ori.Scan(&lands)
 	if query.Error != nil || query == nil || query.RecordNotFound() {
@@ -580,6 +587,14 @@ func GetLandByTokenId(ctx context.Context, tokenId string) *Land {
 	return &land
 }
 
+func GetLandById(ctx context.Context, id uint) *Land {
+	var land Land
+	if query := util.WithContextDb(ctx).First(&land, id); query.Error != nil {
+		return nil
+	}
+	return &land
+}
+
 func GetLandByCoord(ctx context.Context, x, y int) *Land {
 	db := util.WithContextDb(ctx)
 	var land Land
@@ -592,13 +607,22 @@ func GetLandByCoord(ctx context.Context, x, y int) *Land {
 
 func InitLandsFormChain(ctx context.Context, chain string) {
 	count := 2025
+	var (
+		tokenIndexes []int
+		tokenIds     []string
+	)
 	for tokenIndex := 0; tokenIndex < count; tokenIndex++ {
 		tokenId := GenerateLandTokenId(chain, tokenIndex+1)
 		if GetLandByTokenId(ctx, tokenId) != nil {
 			continue
 		}
-		if err := fillLand(ctx, tokenIndex, tokenId); err != nil {
-			log.Debug("fill land error. tokenIndex=%d. error: %s", tokenIndex, err)
+		tokenIndexes = append(tokenIndexes, tokenIndex)
+		tokenIds = append(tokenIds, tokenId)
+	}
+	owners := storage.New(chain).BatchOwnerOf(tokenIds)
+	for i, tokenId := range tokenIds {
+		if err := fillLand(ctx, tokenIndexes[i], tokenId, owners[i]); err != nil {
+			log.Debug("fill land error. tokenIndex=%d. error: %s", tokenIndexes[i], err)
 			break
 		}
 	}
@@ -614,9 +638,9 @@ func RefreshLands(ctx context.Context, chain string) {
 	log.Debug("Success fill lands")
 }
 
-func fillLand(ctx context.Context, tokenIndex int, tokenId string) error {
+func fillLand(ctx context.Context, tokenIndex int, tokenId, owner string) error {
 	db := util.DbBegin(ctx)
-	if err := createByTokenId(db, tokenId, tokenIndex); err != nil {
+	if err := createByTokenId(db, tokenId, tokenIndex, owner); err != nil {
 		db.Rollback()
 		return err
 	}
@@ -624,7 +648,7 @@ func fillLand(ctx context.Context, tokenIndex int, tokenId string) error {
 	return nil
 }
 
-func createByTokenId(db *util.GormDB, tokenId string, tokenIndex int) error {
+func createByTokenId(db *util.GormDB, tokenId string, tokenIndex int, owner string) error {
 	chain := GetChainByTokenId(tokenId)
 	sg := storage.New(chain)
 	lon, lat, err := sg.GetTokenLocationHM(tokenId)
@@ -632,7 +656,6 @@ func createByTokenId(db *util.GormDB, tokenId string, tokenIndex int) error {
 		return err
 	}
 
-	owner, _ := sg.OwnerOf(tokenId)
 	if owner == "" {
 		return errors.New("get land owner fail")
 	}
@@ -702,7 +725,7 @@ func (l *Land) Dapp(ctx context.Context, memberInfo *Member) *DappJson {
 	if l.DappId > 0 && (memberInfo == nil || int(memberInfo.ID) != l.MemberId) {
 		query = db.Table("dapps").Where("id = ?", l.DappId).Scan(&dapp)
 	} else {
-		query = db.Table("dapps").Where("land_id=?", l.ID).Order("id asc").Scan(&dapp)
+		query = db.Table("dapps").Where("land_id=?", l.ID).Order("id desc").Scan(&dapp)
 	}
 	if query.Error != nil || query == nil || query.RecordNotFound() {
 		return nil
@@ -3,6 +3,7 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,10 +11,19 @@ import (
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
 	"github.com/gin-gonic/gin"
+	mysqlDriver "github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
 	"github.com/shopspring/decimal"
 )
 
+var (
+	ErrNameExist   = errors.New("name exist")
+	ErrWalletExist = errors.New("wallet exist")
+	ErrEmailExist  = errors.New("email exist")
+	ErrEmailBound  = errors.New("your have bind email")
+	ErrItCode      = errors.New("invitation code not found")
+)
+
 type Member struct {
 	gorm.Model
 	Name        string `gorm:"size:255;unique_index" json:"name"`
@@ -51,10 +61,15 @@ type ValidateReg struct {
 	Chain     string `form:"chain" json:"chain"`
 	Challenge string `form:"challenge" json:"challenge"`
 	Name      string `form:"name" json:"name" binding:"required"`
-	IteringId uint   `json:"itering_id"`
 	ItCode    string `form:"itCode" json:"itCode"`
 }
 
+type MemberProfile struct {
+	Name        string `form:"name" json:"name"`
+	Region      string `form:"region" json:"region"`
+	RewardChain string `form:"reward_chain" json:"reward_chain"`
+}
+
 type MemberQueryField struct {
 	Id         uint   `json:"id"`
 	Wallet     string `form:"wallet" json:"wallet"`
@@ -63,7 +78,7 @@ type MemberQueryField struct {
 	Name       string `form:"name" json:"name" binding:"required"`
 	ItCode     string `form:"itCode" json:"itCode"`
 	Mobile     string `form:"mobile" json:"mobile"`
-	IteringId  uint   `json:"itering_id"`
+	IteringId  uint   `json:"-"`
 }
 
 type MemberJson struct {
@@ -92,19 +107,33 @@ func (m *Member) AfterCreate(tx *gorm.DB) (err error) {
 	return m.fillAccountMemberId(&util.GormDB{DB: tx})
 }
 
+type MemberLoginInfoJson struct {
+	Ip      string `json:"ip"`
+	Ua      string `json:"ua"`
+	FirstAt int64  `json:"first_at"`
+	LastAt  int64  `json:"last_at"`
+}
+
+// New record login history of each ip and ua, login again from them only refreshes the time
 func (m *MemberLoginInfo) New(ctx context.Context) error {
 	db := util.WithContextDb(ctx)
-	var mli = new(MemberLoginInfo)
-	if db.Where("member_id = ?", m.MemberId).First(&mli).RecordNotFound() {
+	var info MemberLoginInfo
+	if db.Where("member_id = ? AND ip = ? AND ua = ?", m.MemberId, m.Ip, m.Ua).First(&info).RecordNotFound() {
 		return db.Create(m).Error
 	}
-	if m.Ua == mli.Ua && m.Ip == mli.Ip {
-		return nil
-	}
-	return db.Model(new(MemberLoginInfo)).Where("id = ?", mli.ID).Updates(map[string]interface{}{
-		"ip": m.Ip,
-		"ua": m.Ua,
-	}).Error
+	return db.Model(&info).Update("updated_at", time.Now()).Error
+}
+
+func (m *MemberLoginInfo) AsJson() MemberLoginInfoJson {
+	return MemberLoginInfoJson{Ip: m.Ip, Ua: m.Ua, FirstAt: m.CreatedAt.Unix(), LastAt: m.UpdatedAt.Unix()}
+}
+
+// LoginHistory login history of member, latest first
+func (m *Member) LoginHistory(ctx context.Context, page, row int) (list []MemberLoginInfo, count int) {
+	db := util.WithContextDb(ctx).Model(MemberLoginInfo{}).Where("member_id = ?", m.ID)
+	db.Count(&count)
+	db.Order("updated_at desc").Offset(page * row).Limit(row).Find(&list)
+	return
 }
 
 func NewMember(ctx context.Context, chain, name, wallet string, iteringId uint) (uint, error) {
@@ -233,12 +262,21 @@ func (m *Member) updateField(ctx context.Context, db *util.GormDB) error {
 	return query.Error
 }
 
-func (m *Member) BindEmail(ctx context.Context, email string) {
+func (m *Member) BindEmail(ctx context.Context, email string) error {
+	if m.Email != "" {
+		return ErrEmailBound
+	}
+	if exist := (&MemberQueryField{Email: email}).GetMemberBy(ctx, "Email"); exist != nil {
+		return ErrEmailExist
+	}
 	db := util.DbBegin(ctx)
 	defer db.DbRollback()
 	m.Email = email
-	_ = m.updateField(ctx, db)
+	if err := m.updateField(ctx, db); err != nil {
+		return err
+	}
 	db.DbCommit()
+	return db.Error
 }
 
 func (m *Member) TouchAccount(ctx context.Context, currency, wallet string, chain string, noMemberId ...bool) *Account {
@@ -311,14 +349,19 @@ func (m *MemberQueryField) GetMemberBy(ctx context.Context, field string) *Membe
 	return &member
 }
 
+// AuthOwner member of the wallet verified by session token, force returns an unregistered member holding the wallet.
+// Tron session is not valid on evm chains and vice versa
 func AuthOwner(c *gin.Context, force ...bool) *Member {
-	if owner := c.Query("owner"); owner != "" {
-		if member := GetMemberByAddress(util.GetContextByGin(c), owner, c.GetString("EvoNetwork")); member != nil {
-			return member
-		}
-		if len(force) != 0 && force[0] {
-			return new(Member).SetUseAddress(c.GetString("EvoNetwork"), owner)
-		}
+	chain := c.GetString("EvoNetwork")
+	owner := c.GetString(SessionWalletKey)
+	if owner == "" || (c.GetString(SessionChainKey) == TronChain) != (chain == TronChain) {
+		return nil
+	}
+	if member := GetMemberByAddress(util.GetContextByGin(c), owner, chain); member != nil {
+		return member
+	}
+	if len(force) != 0 && force[0] {
+		return new(Member).SetUseAddress(chain, owner)
 	}
 	return nil
 }
@@ -405,3 +448,120 @@ func GetMemberActive7D(ctx context.Context, chain string) uint64 {
 	}
 	return count
 }
+
+const itCodeRetry = 5
+
+// Register consume the signed challenge and register wallet as member, then login.
+// Member created for the wallet when scanning transfers is taken over by its registration,
+// its invitation code and referrer are kept
+func (v *ValidateReg) Register(ctx context.Context, ip, ua string) (*Session, error) {
+	if err := verifyChallenge(ctx, v.Chain, v.Wallet, v.Challenge, v.Sign); err != nil {
+		return nil, err
+	}
+	if exist := (&MemberQueryField{Name: v.Name}).GetMemberBy(ctx, "Name"); exist != nil {
+		return nil, ErrNameExist
+	}
+	member := GetMemberByAddress(ctx, v.Wallet, v.Chain)
+	if member != nil && !strings.EqualFold(member.Name, member.GetUseAddress(v.Chain)) {
+		return nil, ErrWalletExist
+	}
+	var ancestry string
+	if v.ItCode != "" {
+		referrer := (&MemberQueryField{ItCode: v.ItCode}).GetMemberBy(ctx, "ItCode")
+		if referrer == nil {
+			return nil, ErrItCode
+		}
+		ancestry = referrer.childAncestry()
+	}
+
+	if member == nil {
+		// same member as scanning transfers creates, taken over below
+		if _, err := NewMember(ctx, v.Chain, v.Wallet, v.Wallet, 0); err != nil {
+			return nil, err
+		}
+		if member = GetMemberByAddress(ctx, v.Wallet, v.Chain); member == nil {
+			return nil, ErrWalletExist
+		}
+	}
+
+	db := util.DbBegin(ctx)
+	defer db.DbRollback()
+	if member.ItCode == "" {
+		if err := member.assignItCode(db); err != nil {
+			return nil, err
+		}
+	}
+	if member.Ancestry == "" {
+		member.Ancestry = ancestry
+	}
+	member.Name = v.Name
+	if err := member.updateField(ctx, db); err != nil {
+		return nil, err
+	}
+	db.DbCommit()
+	if db.Error != nil {
+		return nil, db.Error
+	}
+	return newSession(ctx, v.Chain, v.Wallet, ip, ua)
+}
+
+// assignItCode give member a random invitation code, codes already taken are retried
+func (m *Member) assignItCode(db *util.GormDB) (err error) {
+	for i := 0; i < itCodeRetry; i++ {
+		code := util.RandStr(4)
+		if err = db.Model(m).UpdateColumn("it_code", code).Error; err == nil {
+			m.ItCode = code
+			return nil
+		}
+		if mysqlErr, ok := err.(*mysqlDriver.MySQLError); !ok || mysqlErr.Number != 1062 {
+			return err
+		}
+	}
+	return err
+}
+
+// UpdateProfile update non-empty fields of profile
+func (m *Member) UpdateProfile(ctx context.Context, p *MemberProfile) error {
+	if p.Name != "" && p.Name != m.Name {
+		if exist := (&MemberQueryField{Name: p.Name}).GetMemberBy(ctx, "Name"); exist != nil {
+			return ErrNameExist
+		}
+		m.Name = p.Name
+	}
+	if p.Region != "" {
+		m.Region = p.Region
+	}
+	if p.RewardChain != "" {
+		m.RewardChain = p.RewardChain
+	}
+	db := util.DbBegin(ctx)
+	defer db.DbRollback()
+	if err := m.updateField(ctx, db); err != nil {
+		return err
+	}
+	db.DbCommit()
+	return db.Error
+}
+
+func (m *Member) AsJson(ctx context.Context, chain string) *MemberJson {
+	wallet := m.GetUseAddress(chain)
+	return &MemberJson{
+		Id:                 m.ID,
+		Email:              m.Email,
+		Wallet:             wallet,
+		TronWallet:         m.TronWallet,
+		EthWallet:          m.Wallet,
+		Name:               m.Name,
+		Mobile:             m.Mobile,
+		IsActive:           1,
+		ItCode:             m.ItCode,
+		Balance:            m.GetBalance(ctx, currencyRing, wallet, chain),
+		KtonBalance:        m.GetBalance(ctx, currencyKton, wallet, chain),
+		RingWithdrawStatus: m.GetWithdrawStatus(ctx, chain, currencyRing),
+		IsInternal:         m.PlayerRole != 0,
+		PlayerRole:         m.PlayerRole,
+		RegTime:            int(m.CreatedAt.Unix()),
+		Newbie:             m.Newbie,
+		RewardChain:        m.RewardChain,
+	}
+}
@@ -66,6 +66,7 @@ func MigrationDbTable() error {
 			&Equipment{},
 			&ParseTxError{},
 			&MemberLoginInfo{},
+			&ScanBlock{},
 		)
 
 	db.Model(Account{}).AddIndex("member_currency", "member_id", "currency")
@@ -173,11 +174,16 @@ func MigrationDbTable() error {
 	db.Model(Equipment{}).AddIndex("apostle_token_id", "apostle_token_id")
 
 	db.Model(ParseTxError{}).AddUniqueIndex("tx_chain_parse_func", "tx", "chain", "parse_func")
+	db.Model(ParseTxError{}).AddIndex("status_next_retry_at", "status", "next_retry_at")
+	if err := markLegacyParseTxErrors(db); err != nil {
+		return err
+	}
 
 	db.Model(MemberLoginInfo{}).AddUniqueIndex("member_id__ip_ua", "member_id", "ip", "ua")
 
 	db.Model(TransactionScan{}).AddUniqueIndex("chain_tx", "chain", "tx")
 	db.Model(TransactionScan{}).AddIndex("chain_block_number", "chain", "block_number")
+	db.Model(ScanBlock{}).AddUniqueIndex("chain_block_number", "chain", "block_number")
 
 	db.Model(ElementRaffle{}).AddIndex("owner_chain", "owner", "chain")
 	db.Model(ElementRaffle{}).AddIndex("tx_owner_chain_element", "tx", "owner", "element")
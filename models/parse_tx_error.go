@@ -1,12 +1,232 @@
 package models
 
-import "github.com/jinzhu/gorm"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/jinzhu/gorm"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/spf13/cast"
+)
+
+const (
+	ParseTxErrorPending   = "pending"   // wait for retry
+	ParseTxErrorResolved  = "resolved"  // callback succeeded on retry
+	ParseTxErrorDiscarded = "discarded" // discarded by admin
+	ParseTxErrorDead      = "dead"      // max attempts exceeded
+	ParseTxErrorLegacy    = "legacy"    // recorded before retrying, retried by admin only
+
+	parseTxErrorBaseBackoff = time.Minute
+	parseTxErrorMaxBackoff  = time.Hour * 24
+)
+
+// ErrRetryInProgress the entry is being retried by another instance
+var ErrRetryInProgress = errors.New("retry in progress")
+
+// ParseTxError dead-letter queue of failed callbacks
 type ParseTxError struct {
 	gorm.Model
-	Tx        string `json:"tx"`
-	Chain     string `json:"chain"`
-	Error     string `json:"error"`
-	ParseFunc string `json:"parse_func"`
-	Receipts  string `json:"receipts" sql:"type:text;"`
+	Tx             string `json:"tx"`
+	Chain          string `json:"chain"`
+	Error          string `json:"error"`
+	ParseFunc      string `json:"parse_func"`
+	Receipts       string `json:"receipts" sql:"type:text;"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	Status         string `json:"status" sql:"type:varchar(16);default:'pending'"`
+	Attempts       int    `json:"attempts" sql:"not null;default:0"`
+	NextRetryAt    int64  `json:"next_retry_at" sql:"not null;default:0"`
+}
+
+type ParseTxErrorJson struct {
+	ID             uint               `json:"id"`
+	Tx             string             `json:"tx"`
+	Chain          string             `json:"chain"`
+	Error          string             `json:"error"`
+	Callback       string             `json:"callback"`
+	Receipts       *services.Receipts `json:"receipts"`
+	BlockTimestamp int64              `json:"block_timestamp"`
+	Status         string             `json:"status"`
+	Attempts       int                `json:"attempts"`
+	NextRetryAt    int64              `json:"next_retry_at"`
+	CreatedAt      int64              `json:"created_at"`
+	UpdatedAt      int64              `json:"updated_at"`
+}
+
+type ParseTxErrorQuery struct {
+	Status string
+	Chain  string
+	Page   int
+	Row    int
+}
+
+func parseTxErrorMaxAttempts() int {
+	if n := cast.ToInt(util.GetEnv("PARSE_TX_ERROR_MAX_ATTEMPTS", "8")); n > 0 {
+		return n
+	}
+	return 8
+}
+
+// parseTxErrorBackoff delay before the next retry after attempts
+func parseTxErrorBackoff(attempts int) time.Duration {
+	backoff := parseTxErrorBaseBackoff
+	for i := 0; i < attempts && backoff < parseTxErrorMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > parseTxErrorMaxBackoff {
+		backoff = parseTxErrorMaxBackoff
+	}
+	return backoff
+}
+
+// RecordParseTxError add failed callback to dead-letter queue, a failed entry already queued is rescheduled
+func RecordParseTxError(ctx context.Context, tx, chain, parseFunc string, blockTimestamp int64, receipts *services.Receipts, callbackErr error) error {
+	db := util.WithContextDb(ctx)
+	var pe ParseTxError
+	query := db.Where("tx = ? AND chain = ? AND parse_func = ?", tx, chain, parseFunc).First(&pe)
+	if query.RecordNotFound() {
+		pe = ParseTxError{
+			Tx:             tx,
+			Chain:          chain,
+			Error:          callbackErr.Error(),
+			ParseFunc:      parseFunc,
+			Receipts:       util.ToString(receipts),
+			BlockTimestamp: blockTimestamp,
+			Status:         ParseTxErrorPending,
+			NextRetryAt:    time.Now().Add(parseTxErrorBackoff(0)).Unix(),
+		}
+		return db.Create(&pe).Error
+	}
+	if query.Error != nil {
+		return query.Error
+	}
+	return pe.failed(ctx, callbackErr)
+}
+
+// failed record the failure, only pending entries are rescheduled, resolved, discarded, dead and legacy ones keep
+// their status
+func (pe *ParseTxError) failed(ctx context.Context, callbackErr error) error {
+	attempts := pe.Attempts + 1
+	update := map[string]interface{}{
+		"error":    callbackErr.Error(),
+		"attempts": attempts,
+	}
+	db := util.WithContextDb(ctx).Model(pe)
+	if pe.Status != ParseTxErrorPending {
+		return db.UpdateColumns(update).Error
+	}
+	update["next_retry_at"] = time.Now().Add(parseTxErrorBackoff(attempts)).Unix()
+	if attempts >= parseTxErrorMaxAttempts() {
+		update["status"] = ParseTxErrorDead
+	}
+	return db.Where("status = ?", ParseTxErrorPending).UpdateColumns(update).Error
+}
+
+func (pe *ParseTxError) setStatus(ctx context.Context, status string) error {
+	return util.WithContextDb(ctx).Model(pe).UpdateColumns(map[string]interface{}{"status": status, "next_retry_at": 0}).Error
+}
+
+// ContractName contract of the failed callback
+func (pe *ParseTxError) ContractName() string {
+	return strings.TrimSuffix(pe.ParseFunc, "Callback")
+}
+
+func (pe *ParseTxError) Receipt() *services.Receipts {
+	var receipt services.Receipts
+	if err := jsoniter.UnmarshalFromString(pe.Receipts, &receipt); err != nil {
+		return nil
+	}
+	return &receipt
+}
+
+// Retry call the callback again, the entry is resolved when callback succeeds or tx has been handled
+func (pe *ParseTxError) Retry(ctx context.Context) error {
+	receipt := pe.Receipt()
+	if receipt == nil {
+		return pe.failed(ctx, fmt.Errorf("invalid receipts of tx %s", pe.Tx))
+	}
+	var (
+		err     error
+		retried bool
+	)
+	util.OnceTask(ctx, fmt.Sprintf("parseTxError:%d", pe.ID), 60, func() {
+		ec := EthTransactionCallback{Tx: pe.Tx, Receipt: receipt, BlockTimestamp: pe.BlockTimestamp}
+		if err = ec.Dispatch(ctx, pe.ContractName()); err == nil {
+			ec.PublishCommitted(ctx, pe.ContractName())
+		}
+		retried = true
+	})
+	if !retried {
+		return ErrRetryInProgress
+	}
+	if err == nil || strings.EqualFold(err.Error(), "tx exist") {
+		return pe.setStatus(ctx, ParseTxErrorResolved)
+	}
+	log.Warn("retry parse tx error %d fail. chain %s tx %s call %s, err: %s", pe.ID, pe.Chain, pe.Tx, pe.ParseFunc, err)
+	if ferr := pe.failed(ctx, err); ferr != nil {
+		return ferr
+	}
+	return err
+}
+
+func (pe *ParseTxError) Discard(ctx context.Context) error {
+	return pe.setStatus(ctx, ParseTxErrorDiscarded)
+}
+
+func (pe *ParseTxError) AsJson() ParseTxErrorJson {
+	return ParseTxErrorJson{
+		ID:             pe.ID,
+		Tx:             pe.Tx,
+		Chain:          pe.Chain,
+		Error:          pe.Error,
+		Callback:       pe.ParseFunc,
+		Receipts:       pe.Receipt(),
+		BlockTimestamp: pe.BlockTimestamp,
+		Status:         pe.Status,
+		Attempts:       pe.Attempts,
+		NextRetryAt:    pe.NextRetryAt,
+		CreatedAt:      pe.CreatedAt.Unix(),
+		UpdatedAt:      pe.UpdatedAt.Unix(),
+	}
+}
+
+func GetParseTxError(ctx context.Context, id uint) *ParseTxError {
+	var pe ParseTxError
+	query := util.WithContextDb(ctx).Where("id = ?", id).First(&pe)
+	if query.Error != nil || query == nil || query.RecordNotFound() {
+		return nil
+	}
+	return &pe
+}
+
+func (q *ParseTxErrorQuery) List(ctx context.Context) (list []ParseTxError, count int) {
+	db := util.WithContextDb(ctx).Model(ParseTxError{})
+	if q.Status != "" {
+		db = db.Where("status = ?", q.Status)
+	}
+	if q.Chain != "" {
+		db = db.Where("chain = ?", q.Chain)
+	}
+	db.Count(&count)
+	db.Order("id desc").Offset(q.Page * q.Row).Limit(q.Row).Find(&list)
+	return
+}
+
+// markLegacyParseTxErrors entries recorded before the retrying queue got status pending and next_retry_at 0 (or NULL
+// if the column was added nullable) from the migration, mark them legacy rather than retrying all of them at once
+func markLegacyParseTxErrors(db *gorm.DB) error {
+	return db.Model(ParseTxError{}).Where("status = ? AND (next_retry_at IS NULL OR next_retry_at = 0)", ParseTxErrorPending).
+		UpdateColumn("status", ParseTxErrorLegacy).Error
+}
+
+// DueParseTxErrors pending entries whose next retry time is up
+func DueParseTxErrors(ctx context.Context, limit int) (list []ParseTxError) {
+	util.WithContextDb(ctx).Where("status = ? AND next_retry_at <= ?", ParseTxErrorPending, time.Now().Unix()).
+		Order("next_retry_at asc").Limit(limit).Find(&list)
+	return
 }
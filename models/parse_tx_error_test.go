@@ -0,0 +1,49 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/evolutionlandorg/evo-backend/config"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecordParseTxError(t *testing.T) {
+	config.InitApplication()
+	util.Panic(util.InitMysql())
+	util.Panic(MigrationDbTable())
+
+	var (
+		ctx = context.Background()
+		db  = util.WithContextDb(ctx)
+		tx  = "0x" + util.RandStr(32)
+	)
+	record := func() ParseTxError {
+		assert.NoError(t, RecordParseTxError(ctx, tx, EthChain, "ClockAuctionCallback", 0, nil, errors.New("fail")))
+		var pe ParseTxError
+		db.Where("tx = ?", tx).First(&pe)
+		return pe
+	}
+	defer db.Unscoped().Where("tx = ?", tx).Delete(ParseTxError{})
+
+	pe := record()
+	assert.Equal(t, ParseTxErrorPending, pe.Status)
+	assert.Zero(t, pe.Attempts)
+	assert.NotZero(t, pe.NextRetryAt)
+
+	// failing again reschedules the pending entry
+	pe = record()
+	assert.Equal(t, ParseTxErrorPending, pe.Status)
+	assert.Equal(t, 1, pe.Attempts)
+
+	// a discarded entry is not brought back by a later failure
+	assert.NoError(t, pe.Discard(ctx))
+	pe = record()
+	assert.Equal(t, ParseTxErrorDiscarded, pe.Status)
+	assert.Equal(t, 2, pe.Attempts)
+	for _, due := range DueParseTxErrors(ctx, 500) {
+		assert.NotEqual(t, pe.ID, due.ID)
+	}
+}
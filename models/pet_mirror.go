@@ -3,10 +3,9 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/jinzhu/gorm"
 )
 
@@ -34,38 +33,28 @@ func (ec *EthTransactionCallback) NftBridgeCallback(ctx context.Context) (err er
 	txn := util.DbBegin(ctx)
 	defer txn.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("nftBridge", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			// SwapIn(address originContract, uint256 originTokenId, uint256 mirrorTokenId, address owner)
-			case services.AbiEncodingMethod("SwapIn(address,uint256,uint256,address)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				tokenId := util.U256(dataSlice[1]).String()
-				mirrorTokenId := dataSlice[2]
-				pet := getNfTokenByTokenId(ctx, tokenId, chain, util.AddHex(dataSlice[0][24:64], chain))
-				if pet == nil {
-					return errors.New("nft not found")
-				}
-				owner := util.AddHex(dataSlice[3][24:64], chain)
-				var record ApostlePet
-				if q := txn.Model(ApostlePet{}).Where("mirror_token_id = ?", dataSlice[2]).First(&record); q.RecordNotFound() {
-					ap := ApostlePet{Chain: chain, PetType: pet.PetType, TokenId: tokenId, MirrorTokenId: mirrorTokenId, Name: pet.Name, ImageUrl: pet.ImageUrlPng, Owner: owner}
-					if err := ap.New(txn); err != nil {
-						txn.DbRollback()
-						return err
-					}
-				} else {
-					txn.Model(ApostlePet{}).Where("mirror_token_id = ?", dataSlice[2]).Update("owner", owner)
-				}
-				// SwapOut(address originContract, uint256 originTokenId, uint256 mirrorTokenId, address owner)
-			case services.AbiEncodingMethod("SwapOut(address,uint256,uint256,address)"):
-				dataSlice := util.LogAnalysis(log.Data)
-				txn.Model(ApostlePet{}).Where("mirror_token_id = ?", dataSlice[2]).Update("owner", util.GetContractAddress("nftBridge", chain))
+	err = ec.eachEvent(util.GetContractAddress("nftBridge", chain),
+		events.On(func(ev *SwapIn) error {
+			tokenId := ev.OriginTokenId.String()
+			mirrorTokenId := eventTokenId(ev.MirrorTokenId)
+			pet := getNfTokenByTokenId(ctx, tokenId, chain, eventAddress(ev.OriginContract, chain))
+			if pet == nil {
+				return errors.New("nft not found")
 			}
-		}
-	}
-
+			owner := eventAddress(ev.Owner, chain)
+			var record ApostlePet
+			if q := txn.Model(ApostlePet{}).Where("mirror_token_id = ?", mirrorTokenId).First(&record); q.RecordNotFound() {
+				ap := ApostlePet{Chain: chain, PetType: pet.PetType, TokenId: tokenId, MirrorTokenId: mirrorTokenId, Name: pet.Name, ImageUrl: pet.ImageUrlPng, Owner: owner}
+				return ap.New(txn)
+			}
+			txn.Model(ApostlePet{}).Where("mirror_token_id = ?", mirrorTokenId).Update("owner", owner)
+			return nil
+		}),
+		events.On(func(ev *SwapOut) error {
+			txn.Model(ApostlePet{}).Where("mirror_token_id = ?", eventTokenId(ev.MirrorTokenId)).Update("owner", util.GetContractAddress("nftBridge", chain))
+			return nil
+		}),
+	)
 	if err != nil {
 		return err
 	}
@@ -4,13 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/services/storage"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/orcaman/concurrent-map"
 	"github.com/satori/go.uuid"
 	"github.com/shopspring/decimal"
@@ -27,28 +26,21 @@ func (ec *EthTransactionCallback) PointsRewardCallback(ctx context.Context) (err
 		jackpotWin int64
 	)
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("pointsReward", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("RewardClaimedWithPoints(address,uint256,uint256)"):
-				address = util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				logSlice := util.LogAnalysis(log.Data)
-				pointAmount := util.BigToDecimal(util.U256(logSlice[0]), util.GetTokenDecimals(chain))
-				rewardAmount := util.BigToDecimal(util.U256(logSlice[1]), util.GetTokenDecimals(chain))
-				jackpotWin = util.BigToDecimal(util.U256(logSlice[1]), util.GetTokenDecimals(chain)).IntPart()
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, Action: TransactionHistoryTickets,
-					Currency: currencyRing, BalanceChange: rewardAmount.Round(8),
-					Extra: "smallTickets",
-				}
-				if pointAmount.String() == "100" {
-					th.Extra = "largeTickets"
-				}
-				_ = th.New(db)
-			}
+	err = ec.eachEvent(util.GetContractAddress("pointsReward", chain), events.On(func(ev *RewardClaimedWithPoints) error {
+		address = eventAddress(ev.User, chain)
+		pointAmount := util.BigToDecimal(ev.PointAmount, util.GetTokenDecimals(chain))
+		rewardAmount := util.BigToDecimal(ev.RewardAmount, util.GetTokenDecimals(chain))
+		jackpotWin = rewardAmount.IntPart()
+		th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, Action: TransactionHistoryTickets,
+			Currency: currencyRing, BalanceChange: rewardAmount.Round(8),
+			Extra: "smallTickets",
 		}
-	}
-
+		if pointAmount.String() == "100" {
+			th.Extra = "largeTickets"
+		}
+		_ = th.New(db)
+		return nil
+	}))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,302 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// DiscrepancyBalance Account.Balance differs from the sum of its AccountVersion
+	DiscrepancyBalance = "balance"
+	// DiscrepancyWithdrawNoEvent Withdraw without TakedBack event in the stored logs of its tx
+	DiscrepancyWithdrawNoEvent = "withdraw_without_event"
+	// DiscrepancyWithdrawMismatch Withdraw differs from the TakedBack event of its tx
+	DiscrepancyWithdrawMismatch = "withdraw_mismatch"
+	// DiscrepancyEventNoWithdraw TakedBack event without Withdraw
+	DiscrepancyEventNoWithdraw = "event_without_withdraw"
+)
+
+type ReconcileOpt struct {
+	Chain     string // empty means all chains
+	BatchSize int
+}
+
+type LedgerDiscrepancy struct {
+	Kind      string          `json:"kind"`
+	AccountId uint            `json:"account_id,omitempty"`
+	Chain     string          `json:"chain"`
+	Wallet    string          `json:"wallet"`
+	Currency  string          `json:"currency"`
+	Tx        string          `json:"tx,omitempty"`
+	Expected  decimal.Decimal `json:"expected"` // ledger sum of balance, amount of event
+	Actual    decimal.Decimal `json:"actual"`   // account balance, amount of withdraw
+	Detail    string          `json:"detail,omitempty"`
+}
+
+type ReconcileReport struct {
+	StartedAt     int64               `json:"started_at"`
+	FinishedAt    int64               `json:"finished_at"`
+	Accounts      int                 `json:"accounts"`
+	Withdraws     int                 `json:"withdraws"`
+	Events        int                 `json:"events"`
+	Discrepancies []LedgerDiscrepancy `json:"discrepancies"`
+}
+
+type takedBackRecord struct {
+	chain    string
+	currency string
+	wallet   string
+	amount   decimal.Decimal
+	matched  bool
+}
+
+// ReconcileLedger recompute every account from its version history and cross-check Withdraw with
+// the TakedBack events of stored TransactionScan logs. It only reports, see ReconcileReport.Repair
+func ReconcileLedger(ctx context.Context, opt ReconcileOpt) (*ReconcileReport, error) {
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 500
+	}
+	report := &ReconcileReport{StartedAt: time.Now().Unix(), Discrepancies: []LedgerDiscrepancy{}}
+	if err := reconcileBalances(ctx, opt, report); err != nil {
+		return report, err
+	}
+	takedBacks, err := scanTakedBacks(ctx, opt)
+	if err != nil {
+		return report, err
+	}
+	if err = reconcileWithdraws(ctx, opt, takedBacks, report); err != nil {
+		return report, err
+	}
+	report.FinishedAt = time.Now().Unix()
+	return report, nil
+}
+
+// reconcileBalances compare balance of accounts of opt.Chain with their version history, batch by batch
+func reconcileBalances(ctx context.Context, opt ReconcileOpt, report *ReconcileReport) error {
+	var lastId uint
+	for {
+		query := util.WithContextDb(ctx).Where("id > ?", lastId)
+		if opt.Chain != "" {
+			query = query.Where("chain = ?", opt.Chain)
+		}
+		var accounts []Account
+		if err := query.Order("id asc").Limit(opt.BatchSize).Find(&accounts).Error; err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			return nil
+		}
+		ids := make([]uint, 0, len(accounts))
+		for _, account := range accounts {
+			ids = append(ids, account.ID)
+		}
+		var sums []struct {
+			AccountId uint
+			Total     decimal.Decimal
+		}
+		if err := util.WithContextDb(ctx).Table("account_versions").Select("account_id, SUM(balance) AS total").
+			Where("account_id in (?)", ids).Group("account_id").Scan(&sums).Error; err != nil {
+			return err
+		}
+		ledger := make(map[uint]decimal.Decimal)
+		for _, sum := range sums {
+			ledger[sum.AccountId] = sum.Total
+		}
+		for _, account := range accounts {
+			report.Accounts++
+			if expected := ledger[account.ID]; !expected.Equal(account.Balance) {
+				report.Discrepancies = append(report.Discrepancies, LedgerDiscrepancy{
+					Kind: DiscrepancyBalance, AccountId: account.ID, Chain: account.Chain, Wallet: account.Wallet,
+					Currency: account.Currency, Expected: expected, Actual: account.Balance,
+				})
+			}
+		}
+		lastId = accounts[len(accounts)-1].ID
+	}
+}
+
+// scanTakedBacks TakedBack events of takeBack contracts in stored logs, by tx.
+// Only the rows whose logs contain the TakedBack topic are loaded
+func scanTakedBacks(ctx context.Context, opt ReconcileOpt) (map[string][]*takedBackRecord, error) {
+	takedBacks := make(map[string][]*takedBackRecord)
+	var (
+		conditions []string
+		topics     []interface{}
+	)
+	for _, topic := range events.Default().Topics("TakedBack") {
+		conditions = append(conditions, "logs LIKE ?")
+		topics = append(topics, "%"+util.TrimHex(topic.Hex())+"%")
+	}
+	if len(conditions) == 0 {
+		return takedBacks, nil
+	}
+	for _, chain := range util.Evo.Networks {
+		if opt.Chain != "" && opt.Chain != chain {
+			continue
+		}
+		contracts := map[string]string{currencyRing: util.GetContractAddress("takeBack", chain), currencyKton: util.GetContractAddress("takeBackKton", chain)}
+		var lastId uint
+		for {
+			var scans []TransactionScan
+			query := util.WithContextDb(ctx).Where("chain = ? AND id > ?", chain, lastId).Where(strings.Join(conditions, " OR "), topics...)
+			if err := query.Order("id asc").Limit(opt.BatchSize).Find(&scans).Error; err != nil {
+				return nil, err
+			}
+			if len(scans) == 0 {
+				break
+			}
+			for _, scan := range scans {
+				lastId = scan.ID
+				var logs []services.Log
+				if err := json.Unmarshal([]byte(scan.Logs), &logs); err != nil {
+					log.Warn("reconcile ledger unmarshal logs of %s %s error: %s", chain, scan.Tx, err)
+					continue
+				}
+				ec := EthTransactionCallback{Tx: scan.Tx, Receipt: &services.Receipts{Logs: logs, ChainSource: chain}}
+				for currency, address := range contracts {
+					if address == "" {
+						continue
+					}
+					decimals := []int32{18}
+					if currency == currencyRing {
+						decimals = []int32{util.GetTokenDecimals(chain)}
+					}
+					currency := currency
+					err := ec.eachEvent(address, events.On(func(ev *TakedBack) error {
+						takedBacks[scan.Tx] = append(takedBacks[scan.Tx], &takedBackRecord{
+							chain: chain, currency: currency, wallet: eventAddress(ev.User, chain), amount: util.BigToDecimal(ev.Value, decimals...),
+						})
+						return nil
+					}))
+					if err != nil {
+						log.Warn("reconcile ledger decode logs of %s %s error: %s", chain, scan.Tx, err)
+					}
+				}
+			}
+		}
+	}
+	return takedBacks, nil
+}
+
+func reconcileWithdraws(ctx context.Context, opt ReconcileOpt, takedBacks map[string][]*takedBackRecord, report *ReconcileReport) error {
+	var lastId uint
+	for {
+		var withdraws []Withdraw
+		if err := util.WithContextDb(ctx).Where("id > ?", lastId).Order("id asc").Limit(opt.BatchSize).Find(&withdraws).Error; err != nil {
+			return err
+		}
+		if len(withdraws) == 0 {
+			break
+		}
+		accounts, err := withdrawAccounts(ctx, opt, withdraws)
+		if err != nil {
+			return err
+		}
+		for _, w := range withdraws {
+			lastId = w.ID
+			account, ok := accounts[w.AccountId]
+			if !ok {
+				continue // account of other chain
+			}
+			report.Withdraws++
+			d := LedgerDiscrepancy{AccountId: account.ID, Chain: account.Chain, Wallet: account.Wallet, Currency: w.Currency, Tx: w.TxId, Actual: w.Amount}
+			var event *takedBackRecord
+			for _, record := range takedBacks[w.TxId] {
+				if !record.matched && record.currency == w.Currency {
+					event = record
+					break
+				}
+			}
+			switch {
+			case event == nil:
+				d.Kind = DiscrepancyWithdrawNoEvent
+				report.Discrepancies = append(report.Discrepancies, d)
+				continue
+			case !event.amount.Equal(w.Amount) || !strings.EqualFold(event.wallet, account.Wallet):
+				d.Kind, d.Expected = DiscrepancyWithdrawMismatch, event.amount
+				d.Detail = fmt.Sprintf("event user %s", event.wallet)
+				report.Discrepancies = append(report.Discrepancies, d)
+			}
+			event.matched = true
+		}
+	}
+	for tx, records := range takedBacks {
+		for _, record := range records {
+			report.Events++
+			if record.matched {
+				continue
+			}
+			report.Discrepancies = append(report.Discrepancies, LedgerDiscrepancy{
+				Kind: DiscrepancyEventNoWithdraw, Chain: record.chain, Wallet: record.wallet, Currency: record.currency, Tx: tx, Expected: record.amount,
+			})
+		}
+	}
+	return nil
+}
+
+// withdrawAccounts accounts of opt.Chain of withdraws by id
+func withdrawAccounts(ctx context.Context, opt ReconcileOpt, withdraws []Withdraw) (map[uint]Account, error) {
+	ids := make([]uint, 0, len(withdraws))
+	for _, w := range withdraws {
+		ids = append(ids, w.AccountId)
+	}
+	query := util.WithContextDb(ctx).Where("id in (?)", ids)
+	if opt.Chain != "" {
+		query = query.Where("chain = ?", opt.Chain)
+	}
+	var list []Account
+	if err := query.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	accounts := make(map[uint]Account, len(list))
+	for _, account := range list {
+		accounts[account.ID] = account
+	}
+	return accounts, nil
+}
+
+// Repair set balance of accounts in balance discrepancies to the sum of their version history,
+// accounts changed since the report are skipped. Withdraw discrepancies need manual handling
+func (r *ReconcileReport) Repair(ctx context.Context) (repaired int, err error) {
+	for _, d := range r.Discrepancies {
+		if d.Kind != DiscrepancyBalance {
+			continue
+		}
+		db := util.DbBegin(ctx)
+		query := db.Model(&Account{}).Where("id = ? AND balance = ?", d.AccountId, d.Actual).UpdateColumn("balance", d.Expected)
+		if query.Error != nil {
+			db.DbRollback()
+			return repaired, query.Error
+		}
+		if query.RowsAffected == 0 {
+			db.DbRollback()
+			log.Warn("reconcile ledger skip account %d, balance changed since report", d.AccountId)
+			continue
+		}
+		db.DbCommit()
+		if db.Error != nil {
+			return repaired, db.Error
+		}
+		log.Info("reconcile ledger repaired account %d balance %s -> %s", d.AccountId, d.Actual, d.Expected)
+		repaired++
+	}
+	return repaired, nil
+}
+
+// Count discrepancies by kind
+func (r *ReconcileReport) Count() map[string]int {
+	count := make(map[string]int)
+	for _, d := range r.Discrepancies {
+		count[d.Kind]++
+	}
+	return count
+}
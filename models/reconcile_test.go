@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/evolutionlandorg/evo-backend/config"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReconcileLedger(t *testing.T) {
+	_ = os.Setenv("ABI_PATH", "../")
+	config.InitApplication()
+	util.Panic(util.InitMysql())
+	util.Panic(MigrationDbTable())
+
+	var (
+		ctx    = context.Background()
+		db     = util.WithContextDb(ctx)
+		chain  = EthChain
+		wallet = "0x" + util.RandStr(20)
+	)
+	newTx := func() string { return "0x" + util.RandStr(32) }
+	takedBackLog := func(user string, value *big.Int) string {
+		logs, _ := json.Marshal([]services.Log{{
+			Address: util.GetContractAddress("takeBack", chain),
+			Topics: []string{
+				crypto.Keccak256Hash([]byte("TakedBack(address,uint256,uint256)")).Hex(),
+				common.BytesToHash(common.HexToAddress(user).Bytes()).Hex(),
+				common.BigToHash(big.NewInt(1)).Hex(),
+			},
+			Data: fmt.Sprintf("0x%064x", value),
+		}})
+		return string(logs)
+	}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	// ledger of account sums to 7, balance is 10
+	account := Account{Chain: chain, Currency: currencyRing, Wallet: wallet, Balance: decimal.NewFromInt(10)}
+	assert.NoError(t, db.Create(&account).Error)
+	defer db.Unscoped().Where("account_id = ?", account.ID).Delete(AccountVersion{})
+	defer db.Unscoped().Where("account_id = ?", account.ID).Delete(Withdraw{})
+	defer db.Unscoped().Delete(&account)
+	for _, v := range []int64{10, -3} {
+		assert.NoError(t, db.Create(&AccountVersion{AccountId: account.ID, Currency: currencyRing, Balance: decimal.NewFromInt(v)}).Error)
+	}
+
+	matchedTx, withdrawOnlyTx, eventOnlyTx := newTx(), newTx(), newTx()
+	for tx, amount := range map[string]int64{matchedTx: 2, withdrawOnlyTx: 1} {
+		assert.NoError(t, db.Create(&Withdraw{TxId: tx, Currency: currencyRing, AccountId: account.ID, Amount: decimal.NewFromInt(amount)}).Error)
+	}
+	for tx, amount := range map[string]int64{matchedTx: 2, eventOnlyTx: 5} {
+		scan := TransactionScan{Tx: tx, Chain: chain, Logs: takedBackLog(wallet, ether(amount))}
+		assert.NoError(t, db.Create(&scan).Error)
+		defer db.Unscoped().Delete(&scan)
+	}
+
+	report, err := ReconcileLedger(ctx, ReconcileOpt{Chain: chain, BatchSize: 2})
+	assert.NoError(t, err)
+	found := make(map[string]LedgerDiscrepancy)
+	for _, d := range report.Discrepancies {
+		if d.AccountId == account.ID || d.Tx == eventOnlyTx {
+			found[d.Kind] = d
+		}
+	}
+	assert.Len(t, found, 3)
+	assert.True(t, found[DiscrepancyBalance].Expected.Equal(decimal.NewFromInt(7)))
+	assert.True(t, found[DiscrepancyBalance].Actual.Equal(decimal.NewFromInt(10)))
+	assert.Equal(t, withdrawOnlyTx, found[DiscrepancyWithdrawNoEvent].Tx)
+	assert.True(t, found[DiscrepancyEventNoWithdraw].Expected.Equal(decimal.NewFromInt(5)))
+	assert.Equal(t, eventOnlyTx, found[DiscrepancyEventNoWithdraw].Tx)
+
+	// repair only the balance discrepancies of the report
+	stale := &ReconcileReport{Discrepancies: []LedgerDiscrepancy{found[DiscrepancyBalance], found[DiscrepancyWithdrawNoEvent]}}
+	repaired, err := stale.Repair(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repaired)
+	db.First(&account, account.ID)
+	assert.True(t, account.Balance.Equal(decimal.NewFromInt(7)))
+
+	// account changed since the report is skipped
+	repaired, err = stale.Repair(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, repaired)
+}
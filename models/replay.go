@@ -0,0 +1,196 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/evolutionlandorg/evo-backend/services/storage"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+)
+
+var ErrCallbackNotFound = errors.New("callback not found")
+
+// CallbackMethodName EthTransactionCallback method handling events of contract
+func CallbackMethodName(contractName string) string {
+	return fmt.Sprintf("%sCallback", contractName)
+}
+
+// Dispatch call the callback method of contract with ctx, callers of live txs publish the changes with PublishCommitted
+func (ec *EthTransactionCallback) Dispatch(ctx context.Context, contractName string) error {
+	methodFunc := reflect.ValueOf(ec).MethodByName(CallbackMethodName(contractName))
+	if !methodFunc.IsValid() {
+		return ErrCallbackNotFound
+	}
+	res := methodFunc.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	err, _ := res[0].Interface().(error)
+	return err
+}
+
+// ListenContractName name of the listened contract (as in application.json contracts) deployed at address
+func ListenContractName(chain, address string) string {
+	contracts := util.GetContractsMap(chain)
+	name, ok := contracts[strings.ToLower(address)]
+	if !ok {
+		name, ok = contracts[strings.ToLower(util.AddHex(util.TrimHex(address), chain))]
+	}
+	if !ok {
+		return ""
+	}
+	var listen string
+	for _, v := range util.Evo.ContractsListen {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(v)) && len(v) > len(listen) {
+			listen = v
+		}
+	}
+	return listen
+}
+
+// ListenContractNames listened contracts emitting the logs of receipt, in the order of their first logs
+func ListenContractNames(chain string, receipt *services.Receipts) (names []string) {
+	for _, l := range receipt.Logs {
+		name := ListenContractName(chain, l.Address)
+		if name == "" || util.StringInSlice(name, names) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+type ReplayOpt struct {
+	Chain         string
+	FromBlock     int64
+	ToBlock       int64 // 0 means the latest scanned block
+	ContractNames []string
+	DryRun        bool
+	BatchSize     int
+	Progress      func(p ReplayProgress)
+}
+
+type ReplayProgress struct {
+	Total      int   `json:"total"`
+	Scanned    int   `json:"scanned"`
+	Dispatched int   `json:"dispatched"`
+	Skipped    int   `json:"skipped"`
+	Failed     int   `json:"failed"`
+	Block      int64 `json:"block"`
+}
+
+// ReplayEvents re-dispatch the logs stored in TransactionScan through callbacks in block order without RPC, the
+// storage of chain is offline while replaying and a callback reading the chain fails its tx (counted in Failed).
+// Transactions already handled by a callback are skipped by the callback itself ("tx exist")
+func ReplayEvents(ctx context.Context, opt ReplayOpt) (*ReplayProgress, error) {
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 500
+	}
+	storage.Offline(opt.Chain)
+	defer storage.Online(opt.Chain)
+	var contractNames []string
+	for _, v := range opt.ContractNames {
+		contractNames = append(contractNames, strings.ToLower(v))
+	}
+
+	query := util.WithContextDb(ctx).Model(TransactionScan{}).Where("chain = ? AND block_number >= ?", opt.Chain, opt.FromBlock)
+	if opt.ToBlock > 0 {
+		query = query.Where("block_number <= ?", opt.ToBlock)
+	}
+	var progress ReplayProgress
+	if err := query.Count(&progress.Total).Error; err != nil {
+		return nil, err
+	}
+
+	// the scanner saves the txs of a block in their order, so id keeps the tx order inside a block
+	var (
+		lastBlock int64 = -1
+		lastId    uint
+	)
+	for {
+		var scans []TransactionScan
+		if err := query.Where("block_number > ? OR (block_number = ? AND id > ?)", lastBlock, lastBlock, lastId).
+			Order("block_number asc, id asc").Limit(opt.BatchSize).Find(&scans).Error; err != nil {
+			return &progress, err
+		}
+		if len(scans) == 0 {
+			break
+		}
+		for _, scan := range scans {
+			select {
+			case <-ctx.Done():
+				return &progress, ctx.Err()
+			default:
+			}
+			lastBlock, lastId = scan.BlockNumber, scan.ID
+			progress.Scanned++
+			progress.Block = scan.BlockNumber
+			replayTransactionScan(ctx, &scan, contractNames, opt.DryRun, &progress)
+		}
+		if opt.Progress != nil {
+			opt.Progress(progress)
+		}
+	}
+	return &progress, nil
+}
+
+func replayTransactionScan(ctx context.Context, scan *TransactionScan, contractNames []string, dryRun bool, progress *ReplayProgress) {
+	receipt := scan.Receipt()
+	var names []string
+	for _, name := range ListenContractNames(scan.Chain, receipt) {
+		if len(contractNames) > 0 && !util.StringInSlice(strings.ToLower(name), contractNames) {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		progress.Skipped++
+		return
+	}
+	for _, name := range names {
+		if dryRun {
+			log.Info("replay %s block %d tx %s call %s (dry run)", scan.Chain, scan.BlockNumber, scan.Tx, CallbackMethodName(name))
+			progress.Dispatched++
+			continue
+		}
+		ec := EthTransactionCallback{Tx: scan.Tx, Receipt: receipt, BlockTimestamp: scan.BlockTimestamp}
+		switch err := dispatchOffline(ctx, &ec, name); {
+		case err == nil:
+			progress.Dispatched++
+		case errors.Is(err, ErrCallbackNotFound), strings.EqualFold(err.Error(), "tx exist"):
+			progress.Skipped++
+		default:
+			progress.Failed++
+			log.Error("replay %s tx %s call %s error: %s", scan.Chain, scan.Tx, CallbackMethodName(name), err)
+		}
+	}
+}
+
+// dispatchOffline Dispatch while storage of the chain is offline, the callback reading the chain is rolled back
+// by its deferred DbRollback and fails with storage.ErrOffline
+func dispatchOffline(ctx context.Context, ec *EthTransactionCallback, contractName string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != storage.ErrOffline {
+				panic(r)
+			}
+			err = storage.ErrOffline
+		}
+	}()
+	return ec.Dispatch(ctx, contractName)
+}
+
+// Receipt rebuild the receipt of stored logs
+func (t *TransactionScan) Receipt() *services.Receipts {
+	receipt := services.Receipts{
+		BlockNumber: util.IntToHex(t.BlockNumber),
+		Status:      "0x1",
+		ChainSource: t.Chain,
+		Solidity:    true,
+	}
+	_ = json.Unmarshal([]byte(t.Logs), &receipt.Logs)
+	return &receipt
+}
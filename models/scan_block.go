@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/evolutionlandorg/evo-backend/services/storage"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/jinzhu/gorm"
+	"github.com/shopspring/decimal"
+)
+
+// ScanBlock hash of a scanned block, compared with the chain to detect reorgs
+type ScanBlock struct {
+	ID          uint `gorm:"primary_key" json:"id"`
+	CreatedAt   time.Time
+	Chain       string `json:"chain" gorm:"varchar(24)"`
+	BlockNumber int64  `json:"block_number"`
+	BlockHash   string `json:"block_hash" gorm:"varchar(66)"`
+}
+
+func SaveScanBlock(ctx context.Context, chain string, blockNumber int64, hash string) error {
+	return util.WithContextDb(ctx).Exec("INSERT INTO scan_blocks (created_at, chain, block_number, block_hash) VALUES (?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE block_hash = VALUES(block_hash)", time.Now(), chain, blockNumber, hash).Error
+}
+
+// GetScanBlocks scanned blocks of chain from block number, order by block number
+func GetScanBlocks(ctx context.Context, chain string, from int64) (blocks []ScanBlock) {
+	util.WithContextDb(ctx).Where("chain = ? AND block_number >= ?", chain, from).Order("block_number asc").Find(&blocks)
+	return
+}
+
+// PruneScanBlocks remove hashes of finalized blocks
+func PruneScanBlocks(ctx context.Context, chain string, before int64) error {
+	return util.WithContextDb(ctx).Where("chain = ? AND block_number < ?", chain, before).Delete(ScanBlock{}).Error
+}
+
+// RollbackOrphanedBlocks roll back TransactionScan rows of chain from block number and the state derived from them.
+// Land and apostle owners are re-read from sg, which must already follow the canonical chain
+func RollbackOrphanedBlocks(ctx context.Context, chain string, from int64, sg storage.IStorage) ([]string, error) {
+	db := util.DbBegin(ctx)
+	defer db.DbRollback()
+
+	var scans []TransactionScan
+	if err := db.Where("chain = ? AND block_number >= ?", chain, from).Find(&scans).Error; err != nil {
+		return nil, err
+	}
+	if len(scans) == 0 {
+		if err := db.Where("chain = ? AND block_number >= ?", chain, from).Delete(ScanBlock{}).Error; err != nil {
+			return nil, err
+		}
+		db.DbCommit()
+		return nil, db.Error
+	}
+
+	var (
+		txs         []string
+		tokenIds    = hashset.New()
+		transferred = events.On(func(ev *Transfer) error {
+			tokenIds.Add(ev.tokenId())
+			return nil
+		})
+	)
+	for _, scan := range scans {
+		txs = append(txs, scan.Tx)
+		for _, l := range scan.Receipt().Logs {
+			if len(l.Topics) == 0 || !strings.EqualFold(util.AddHex(l.Address, chain), util.GetContractAddress("objectOwnership", chain)) {
+				continue
+			}
+			if err := events.Handle(l, transferred); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var histories []AuctionHistory
+	if err := db.Where("tx_id IN (?)", txs).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	for _, query := range []*gorm.DB{
+		db.Unscoped().Where("tx_id IN (?)", txs).Delete(AuctionHistory{}),
+		db.Unscoped().Where("create_tx IN (?)", txs).Delete(Auction{}),
+		db.Unscoped().Where("create_tx IN (?)", txs).Delete(AuctionApostle{}),
+		db.Where("tx IN (?) AND chain = ?", txs, chain).Delete(TransactionHistory{}),
+		db.Where("tx IN (?)", txs).Delete(UniqueTransaction{}),
+		db.Unscoped().Where("chain = ? AND block_number >= ?", chain, from).Delete(TransactionScan{}),
+		db.Where("chain = ? AND block_number >= ?", chain, from).Delete(ScanBlock{}),
+	} {
+		if query.Error != nil {
+			return nil, query.Error
+		}
+	}
+	for _, h := range histories {
+		if err := resetAuctionLastBid(db, h.AuctionId, h.AssetType); err != nil {
+			return nil, err
+		}
+	}
+	var resetTokenIds []string
+	for _, v := range tokenIds.Values() {
+		resetTokenIds = append(resetTokenIds, v.(string))
+	}
+	owners := sg.BatchOwnerOf(resetTokenIds)
+	for i, tokenId := range resetTokenIds {
+		if err := resetTokenOwner(ctx, db, tokenId, owners[i], chain); err != nil {
+			return nil, err
+		}
+	}
+	db.DbCommit()
+	return txs, db.Error
+}
+
+// resetAuctionLastBid set last bid of auction to the latest bid left
+func resetAuctionLastBid(db *util.GormDB, auctionId uint, assetType string) error {
+	var last AuctionHistory
+	update := map[string]interface{}{"last_bidder": "", "last_price": decimal.Zero, "last_bid_start": 0}
+	if query := db.Where("auction_id = ? AND asset_type = ?", auctionId, assetType).Order("id desc").First(&last); !query.RecordNotFound() {
+		update = map[string]interface{}{"last_bidder": last.Buyer, "last_price": last.BidPrice, "last_bid_start": last.StartAt}
+	}
+	if assetType == AssetApostle {
+		return db.Model(AuctionApostle{}).Where("id = ?", auctionId).UpdateColumn(update).Error
+	}
+	return db.Model(Auction{}).Where("id = ?", auctionId).UpdateColumn(update).Error
+}
+
+// resetTokenOwner re-derive land/apostle owner and auction status from chain
+func resetTokenOwner(ctx context.Context, db *util.GormDB, tokenId, owner, chain string) error {
+	if owner == "" {
+		log.Warn("reorg reset owner of %s fail", tokenId)
+		return nil
+	}
+	owner = util.AddHex(util.TrimHex(owner), chain)
+	switch getAssetTypeByTokenId(tokenId) {
+	case AssetLand:
+		var auc Auction
+		if strings.EqualFold(owner, util.GetContractAddress("clockAuction", chain)) &&
+			!db.Where("token_id = ?", tokenId).Order("id desc").First(&auc).RecordNotFound() && auc.Status != AuctionGoing {
+			query := db.Model(&auc).UpdateColumn(map[string]interface{}{"status": AuctionGoing, "winner": "", "claim_time": 0})
+			if query.Error != nil {
+				return query.Error
+			}
+		}
+		return updateLandOwner(ctx, db, tokenId, owner)
+	case AssetApostle:
+		apostle := GetApostleByTokenId(ctx, tokenId)
+		if apostle == nil || strings.EqualFold(apostle.Owner, owner) {
+			return nil
+		}
+		if !strings.EqualFold(owner, util.GetContractAddress("clockAuctionApostle", chain)) {
+			return apostle.TransferOwner(db, owner, apostleFresh, "", 0, chain)
+		}
+		var auc AuctionApostle
+		if db.Where("token_id = ?", tokenId).Order("id desc").First(&auc).RecordNotFound() {
+			return apostle.TransferOwner(db, owner, apostleOnsell, "", 0, chain)
+		}
+		if auc.Status != AuctionGoing {
+			query := db.Model(&auc).UpdateColumn(map[string]interface{}{"status": AuctionGoing, "winner": "", "claim_time": 0})
+			if query.Error != nil {
+				return query.Error
+			}
+		}
+		var originId uint
+		if member := GetMemberByAddress(ctx, auc.Seller, chain); member != nil {
+			originId = member.ID
+		}
+		return apostle.TransferOwner(db, owner, apostleOnsell, auc.Seller, originId, chain)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/evolutionlandorg/evo-backend/services"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/spf13/cast"
+)
+
+const (
+	// SessionWalletKey gin context key of the wallet verified by session token
+	SessionWalletKey = "SessionWallet"
+	// SessionChainKey gin context key of the chain the session wallet signed in
+	SessionChainKey = "SessionChain"
+
+	challengeTTL = 300
+)
+
+var (
+	ErrSessionDisabled  = errors.New("session is disabled, JWT_SECRET is not set")
+	ErrChallengeExpired = errors.New("challenge not found or expired")
+	ErrSignature        = errors.New("verify signature error")
+	ErrSessionToken     = errors.New("invalid session token")
+)
+
+type SessionClaims struct {
+	Wallet string `json:"wallet"`
+	Chain  string `json:"chain"`
+	jwt.StandardClaims
+}
+
+type Session struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+	Wallet    string `json:"wallet"`
+	Chain     string `json:"chain"`
+}
+
+func sessionSecret() []byte {
+	return []byte(util.GetEnv("JWT_SECRET", ""))
+}
+
+func challengeKey(chain, wallet string) string {
+	return fmt.Sprintf("AuthChallenge:%s:%s", chain, strings.ToLower(wallet))
+}
+
+// NewChallenge random challenge the wallet signs to login, valid for challengeTTL seconds and used once
+func NewChallenge(ctx context.Context, chain, wallet string) (string, error) {
+	challenge := util.RandStr(16)
+	if err := util.SetCache(ctx, challengeKey(chain, wallet), []byte(challenge), challengeTTL); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// Login consume the challenge of wallet, verify its signature then issue session token
+func (v *ValidateLogin) Login(ctx context.Context, ip, ua string) (*Session, error) {
+	if err := verifyChallenge(ctx, v.Chain, v.Wallet, v.Challenge, v.Sign); err != nil {
+		return nil, err
+	}
+	return newSession(ctx, v.Chain, v.Wallet, ip, ua)
+}
+
+// verifyChallenge the challenge is consumed even if the signature is wrong
+func verifyChallenge(ctx context.Context, chain, wallet, challenge, sign string) error {
+	if len(sessionSecret()) == 0 {
+		return ErrSessionDisabled
+	}
+	if stored := util.PopCache(ctx, challengeKey(chain, wallet)); stored == nil || string(stored) != challenge {
+		return ErrChallengeExpired
+	}
+	verify := services.VerifySign
+	if chain == TronChain {
+		verify = services.VerifyTronSign
+	}
+	if !verify(sign, wallet, challenge) {
+		return ErrSignature
+	}
+	return nil
+}
+
+func newSession(ctx context.Context, chain, wallet, ip, ua string) (*Session, error) {
+	if chain != TronChain {
+		wallet = strings.ToLower(wallet)
+	}
+	now := time.Now()
+	ttl := cast.ToInt64(util.GetEnv("SESSION_TTL", "86400"))
+	claims := SessionClaims{
+		Wallet: wallet,
+		Chain:  chain,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   wallet,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Unix() + ttl,
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(sessionSecret())
+	if err != nil {
+		return nil, err
+	}
+
+	if member := GetMemberByAddress(ctx, wallet, chain); member != nil {
+		member.RefreshMemberLoginTime(ctx)
+		_ = (&MemberLoginInfo{MemberId: member.ID, Ip: ip, Ua: ua}).New(ctx)
+	}
+	return &Session{Token: token, ExpiresAt: claims.ExpiresAt, Wallet: claims.Wallet, Chain: claims.Chain}, nil
+}
+
+// ParseSession verify session token signed by Login
+func ParseSession(token string) (*SessionClaims, error) {
+	if len(sessionSecret()) == 0 {
+		return nil, ErrSessionDisabled
+	}
+	claims := new(SessionClaims)
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return sessionSecret(), nil
+	})
+	if err != nil || !parsed.Valid || claims.Wallet == "" {
+		return nil, ErrSessionToken
+	}
+	return claims, nil
+}
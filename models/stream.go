@@ -0,0 +1,177 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+)
+
+// StreamChannel redis channel of change events, every api instance fans them out to its stream clients
+const StreamChannel = "evo-stream"
+
+const (
+	StreamTxStatus  = "tx_status"
+	StreamBroadcast = "broadcast"
+	StreamAuction   = "auction"
+	// events of tokens are typed by asset type, e.g. land, apostle, drill
+
+	StreamTxSuccess = "success"
+	StreamTxFail    = "fail"
+)
+
+type StreamEvent struct {
+	Type     string      `json:"type"`
+	Chain    string      `json:"chain,omitempty"`
+	Tx       string      `json:"tx,omitempty"`
+	Contract string      `json:"contract,omitempty"`
+	TokenId  string      `json:"token_id,omitempty"`
+	District int         `json:"district,omitempty"`
+	Wallets  []string    `json:"wallets,omitempty"`
+	Actions  []string    `json:"actions,omitempty"`
+	Status   string      `json:"status,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Time     int64       `json:"time"`
+	Topics   []string    `json:"topics"`
+}
+
+// ChangeTag normalized tag of changed objects, e.g. wallet:<address>, token:<token id>, district:<district>,
+// auction:<district>, tx:<hash> or broadcast. It is the topic of stream and the tag of response cache
+func ChangeTag(kind string, value ...interface{}) string {
+	topic := kind
+	if len(value) > 0 {
+		topic = fmt.Sprintf("%s:%v", kind, value[0])
+	}
+	return strings.ToLower(topic)
+}
+
+func (e *StreamEvent) topics() []string {
+	var topics []string
+	switch e.Type {
+	case StreamBroadcast:
+		topics = append(topics, ChangeTag(StreamBroadcast))
+	case StreamTxStatus:
+		topics = append(topics, ChangeTag("tx", e.Tx))
+	case StreamAuction:
+		topics = append(topics, ChangeTag("auction", e.District))
+	}
+	if e.TokenId != "" {
+		topics = append(topics, ChangeTag("token", e.TokenId))
+	}
+	for _, wallet := range e.Wallets {
+		topics = append(topics, ChangeTag("wallet", wallet))
+	}
+	return topics
+}
+
+// PublishStreamEvent publish event to stream clients subscribing any of its topics
+func PublishStreamEvent(ctx context.Context, e *StreamEvent) {
+	if e.Time == 0 {
+		e.Time = time.Now().Unix()
+	}
+	e.Topics = e.topics()
+	b, _ := json.Marshal(e)
+	if _, err := util.SubPoolWithContextDo(ctx)("publish", StreamChannel, string(b)); err != nil {
+		log.Warn("publish stream event %s of %s error: %s", e.Type, e.Tx, err)
+	}
+}
+
+// PublishTxFail publish the failure of callback to subscribers of tx
+func (ec *EthTransactionCallback) PublishTxFail(ctx context.Context, contractName string) {
+	PublishStreamEvent(ctx, &StreamEvent{Type: StreamTxStatus, Chain: ec.Receipt.ChainSource, Tx: ec.Tx, Contract: contractName, Status: StreamTxFail})
+}
+
+// PublishCommitted invalidate response cache of the changes of committed callback and publish them.
+// Only live dispatches publish, events replayed from TransactionScan do not
+func (ec *EthTransactionCallback) PublishCommitted(ctx context.Context, contractName string) {
+	changes := ec.changes(ctx, contractName)
+	var tags []string
+	for _, e := range changes {
+		for _, tag := range e.topics() {
+			if !strings.HasPrefix(tag, "tx:") && !util.StringInSlice(tag, tags) {
+				tags = append(tags, tag)
+			}
+		}
+		if e.TokenId != "" {
+			if tag := ChangeTag("district", getNFTDistrict(e.TokenId)); !util.StringInSlice(tag, tags) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	util.InvalidateCacheTags(ctx, tags...)
+	for _, e := range changes {
+		PublishStreamEvent(ctx, e)
+	}
+}
+
+// changes the status of tx and a token event for every token transferred or recorded in transaction history,
+// auctions are also published to their district
+func (ec *EthTransactionCallback) changes(ctx context.Context, contractName string) []*StreamEvent {
+	chain := ec.Receipt.ChainSource
+	status := &StreamEvent{Type: StreamTxStatus, Chain: chain, Tx: ec.Tx, Contract: contractName, Status: StreamTxSuccess}
+	changes := []*StreamEvent{status}
+	tokens := make(map[string]*StreamEvent)
+	token := func(tokenId string) *StreamEvent {
+		if e, ok := tokens[tokenId]; ok {
+			return e
+		}
+		e := &StreamEvent{Chain: chain, Tx: ec.Tx, Contract: contractName, TokenId: tokenId, Type: getAssetTypeByTokenId(tokenId)}
+		if e.Type == "" {
+			e.Type = "token"
+		}
+		if strings.Contains(strings.ToLower(contractName), StreamAuction) {
+			e.Type, e.District = StreamAuction, getNFTDistrict(tokenId)
+		}
+		tokens[tokenId] = e
+		changes = append(changes, e)
+		return e
+	}
+	transferred := events.On(func(ev *Transfer) error {
+		e := token(eventTokenId(ev.TokenId))
+		for _, address := range []common.Address{ev.From, ev.To} {
+			// mint and burn transfer from or to zero address
+			if address != (common.Address{}) {
+				e.Wallets = appendStreamWallet(e.Wallets, eventAddress(address, chain))
+			}
+		}
+		return nil
+	})
+	for _, l := range ec.Receipt.Logs {
+		if len(l.Topics) != 4 { // erc721 Transfer indexes the token id
+			continue
+		}
+		_ = events.Handle(l, transferred)
+	}
+	var histories []TransactionHistory
+	util.WithContextDb(ctx).Where("tx = ?", ec.Tx).Find(&histories)
+	for _, th := range histories {
+		status.Wallets = appendStreamWallet(status.Wallets, th.BalanceAddress)
+		if th.TokenId == "" {
+			continue
+		}
+		e := token(th.TokenId)
+		e.Wallets = appendStreamWallet(e.Wallets, th.BalanceAddress)
+		if th.Action != "" && !util.StringInSlice(th.Action, e.Actions) {
+			e.Actions = append(e.Actions, th.Action)
+		}
+	}
+	return changes
+}
+
+func appendStreamWallet(wallets []string, wallet string) []string {
+	if wallet == "" {
+		return wallets
+	}
+	for _, w := range wallets {
+		if strings.EqualFold(w, wallet) {
+			return wallets
+		}
+	}
+	return append(wallets, wallet)
+}
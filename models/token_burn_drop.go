@@ -3,10 +3,9 @@ package models
 import (
 	"context"
 	"errors"
-	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 )
 
 func (ec *EthTransactionCallback) TokenBurnDropCallback(ctx context.Context) (err error) {
@@ -18,30 +17,23 @@ func (ec *EthTransactionCallback) TokenBurnDropCallback(ctx context.Context) (er
 	defer db.DbRollback()
 
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("tokenBurnDrop", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("RingBurndropTokens(address,address,uint256,bytes)"):
-				fallthrough
-			case services.AbiEncodingMethod("KtonBurndropTokens(address,address,uint256,bytes)"):
-				// send SendReceiptsProofToDarwinia
-				logSlice := util.LogAnalysis(log.Data)
-				address := util.AddHex(util.TrimHex(log.Topics[2])[24:64], chain)
-				token := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				mount := util.BigToDecimal(util.U256(logSlice[0]))
-
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: address, Action: TransactionHistoryKtonMapping, BalanceChange: mount, Currency: currencyKton}
-				if token == util.GetContractAddress("ring", chain) {
-					th.Action = TransactionHistoryRingMapping
-					th.Currency = currencyRing
-				}
-				// tokenMapToDarwinia(ec.Tx, th.Currency)
-				_ = th.New(db)
-			}
+	// send SendReceiptsProofToDarwinia
+	var burndrop = func(ev *RingBurndropTokens) error {
+		th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: eventAddress(ev.Owner, chain), Action: TransactionHistoryKtonMapping, BalanceChange: util.BigToDecimal(ev.Amount), Currency: currencyKton}
+		if eventAddress(ev.Token, chain) == util.GetContractAddress("ring", chain) {
+			th.Action = TransactionHistoryRingMapping
+			th.Currency = currencyRing
 		}
+		// tokenMapToDarwinia(ec.Tx, th.Currency)
+		_ = th.New(db)
+		return nil
 	}
-
+	err = ec.eachEvent(util.GetContractAddress("tokenBurnDrop", chain),
+		events.On(burndrop),
+		events.On(func(ev *KtonBurndropTokens) error {
+			return burndrop((*RingBurndropTokens)(ev))
+		}),
+	)
 	if err != nil {
 		return err
 	}
@@ -6,8 +6,8 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	log1 "github.com/evolutionlandorg/evo-backend/util/log"
 	"github.com/jinzhu/gorm"
 	"github.com/shopspring/decimal"
@@ -71,45 +71,42 @@ func (ec *EthTransactionCallback) TokenSwapCallback(ctx context.Context) (err er
 	txn := util.DbBegin(ctx)
 	defer txn.DbRollback()
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("tokenSwap", chain)) {
-			eventName := util.AddHex(log.Topics[0])
-			switch eventName {
-			case services.AbiEncodingMethod("TokenSwapped(uint256,address,bytes32,uint256,address,uint256,uint256,uint256)"):
-				chainPair := swapChainPair[chain]
-				sliceData := util.LogAnalysis(log.Data)
-				from := util.AddHex(sliceData[0][24:64], chain)
-				var to string
-				if chain == "Eth" {
-					to = util.AddHex(sliceData[1][24:64], "Tron")
-				} else {
-					to = util.AddHex(sliceData[1][24:64], "Eth")
-				}
-				token := util.AddHex(sliceData[3][24:64], chain)
+	errCurrency := errors.New("not support currency swap")
+	err = ec.eachEvent(util.GetContractAddress("tokenSwap", chain), events.On(func(ev *TokenSwapped) error {
+		chainPair := swapChainPair[chain]
+		from := eventAddress(ev.From, chain)
+		to := util.BytesToHex(ev.To[12:])
+		if chain == "Eth" {
+			to = util.AddHex(to, "Tron")
+		} else {
+			to = util.AddHex(to, "Eth")
+		}
+		token := eventAddress(ev.Token, chain)
 
-				var currency string
-				if strings.EqualFold(token, util.GetContractAddress(currencyKton, chain)) {
-					currency = currencyKton
-				} else if strings.EqualFold(token, util.GetContractAddress(currencyRing, chain)) {
-					currency = currencyRing
-				} else {
-					log1.Debug("not support currency swap. chain=%s. token=%s. currencyKton=%s. currencyRing=%s",
-						chain, token, currencyKton, currencyRing)
-					return
-				}
-				amount := util.BigToDecimal(util.U256(sliceData[2]))
-				fee := util.BigToDecimal(util.U256(sliceData[4]))
-				ts := TokenSwap{From: from, To: to, Amount: amount, Fee: fee, Confirmations: 0,
-					SwapContract: util.GetContractAddress("tokenSwap", chain), SwapTx: ec.Tx, Status: tokenSwapConfirmed, ChainPair: chainPair, Currency: currency}
-				_ = ts.new(txn)
-				extra := map[string]interface{}{"to": to, "confirmations": 0, "max_confirmations": 10}
-				bExtra, _ := json.Marshal(extra)
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: from, Action: TransactionHistorySwap, BalanceChange: amount.Neg(), Currency: currency, Extra: string(bExtra)}
-				_ = th.New(txn)
-			}
+		var currency string
+		if strings.EqualFold(token, util.GetContractAddress(currencyKton, chain)) {
+			currency = currencyKton
+		} else if strings.EqualFold(token, util.GetContractAddress(currencyRing, chain)) {
+			currency = currencyRing
+		} else {
+			log1.Debug("not support currency swap. chain=%s. token=%s. currencyKton=%s. currencyRing=%s",
+				chain, token, currencyKton, currencyRing)
+			return errCurrency
 		}
+		amount := util.BigToDecimal(ev.Amount)
+		fee := util.BigToDecimal(ev.Fee)
+		ts := TokenSwap{From: from, To: to, Amount: amount, Fee: fee, Confirmations: 0,
+			SwapContract: util.GetContractAddress("tokenSwap", chain), SwapTx: ec.Tx, Status: tokenSwapConfirmed, ChainPair: chainPair, Currency: currency}
+		_ = ts.new(txn)
+		extra := map[string]interface{}{"to": to, "confirmations": 0, "max_confirmations": 10}
+		bExtra, _ := json.Marshal(extra)
+		th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: from, Action: TransactionHistorySwap, BalanceChange: amount.Neg(), Currency: currency, Extra: string(bExtra)}
+		_ = th.New(txn)
+		return nil
+	}))
+	if errors.Is(err, errCurrency) {
+		return nil
 	}
-
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/jinzhu/gorm"
 
 	"github.com/shopspring/decimal"
 )
@@ -35,6 +36,7 @@ type TransactionHistoryJson struct {
 	AddTime        int             `json:"add_time"`
 	Coordinate     string          `json:"coordinate"`
 	Extra          string          `json:"extra"`
+	Chain          string          `json:"chain"`
 }
 
 type EthTransactionQuery struct {
@@ -42,11 +44,18 @@ type EthTransactionQuery struct {
 		BalanceAddress string
 		Action         string
 		Chain          string
+		Currency       string
+		TokenId        string
 	}
-	Row  int
-	Page int
+	StartTime int // add_time range, 0 means unlimited
+	EndTime   int
+	Row       int
+	Page      int
 }
 
+// transactionHistoryExportBatch rows read from database at a time when exporting
+const transactionHistoryExportBatch = 1000
+
 func (th *TransactionHistory) New(db *util.GormDB) error {
 	th.ID = 0
 	th.AddTime = int(time.Now().Unix())
@@ -54,22 +63,71 @@ func (th *TransactionHistory) New(db *util.GormDB) error {
 	return result.Error
 }
 
+func (th *TransactionHistory) AsJson() *TransactionHistoryJson {
+	return &TransactionHistoryJson{
+		Tx:             th.Tx,
+		Action:         th.Action,
+		TokenId:        th.TokenId,
+		BalanceChange:  th.BalanceChange,
+		BalanceAddress: th.BalanceAddress,
+		Currency:       th.Currency,
+		AddTime:        th.AddTime,
+		Coordinate:     th.Coordinate,
+		Extra:          th.Extra,
+		Chain:          th.Chain,
+	}
+}
+
+func (etq *EthTransactionQuery) where(ctx context.Context) *gorm.DB {
+	db := util.WithContextDb(ctx).Table("transaction_histories").Where(etq.WhereQuery)
+	if etq.StartTime > 0 {
+		db = db.Where("add_time >= ?", etq.StartTime)
+	}
+	if etq.EndTime > 0 {
+		db = db.Where("add_time <= ?", etq.EndTime)
+	}
+	return db
+}
+
 func (etq *EthTransactionQuery) GetTransactionHistory(ctx context.Context) (*[]TransactionHistoryJson, int) {
-	db := util.WithContextDb(ctx)
 	var (
 		ethTran []TransactionHistoryJson
 		count   int
 	)
-	query := db.Table("transaction_histories").Where(etq.WhereQuery).
+	query := etq.where(ctx).
 		Offset(etq.Page * etq.Row).Limit(etq.Row).Order("id desc").
 		Scan(&ethTran)
 	if query.Error != nil || query == nil || query.RecordNotFound() {
 		return nil, 0
 	}
-	db.Table("transaction_histories").Where(etq.WhereQuery).Count(&count)
+	etq.where(ctx).Count(&count)
 	return &ethTran, count
 }
 
+// EachTransactionHistory walk through all histories of query latest first, ignoring Page and Row, until fn returns error
+func (etq *EthTransactionQuery) EachTransactionHistory(ctx context.Context, fn func(th *TransactionHistoryJson) error) error {
+	var lastId uint
+	for {
+		var batch []TransactionHistory
+		db := etq.where(ctx)
+		if lastId > 0 {
+			db = db.Where("id < ?", lastId)
+		}
+		if err := db.Order("id desc").Limit(transactionHistoryExportBatch).Scan(&batch).Error; err != nil {
+			return err
+		}
+		for i := range batch {
+			if err := fn(batch[i].AsJson()); err != nil {
+				return err
+			}
+		}
+		if len(batch) < transactionHistoryExportBatch {
+			return nil
+		}
+		lastId = batch[len(batch)-1].ID
+	}
+}
+
 func setTransactionHistoryExtra(txn *util.GormDB, tx, action, extra string) {
 	txn.Model(&TransactionHistory{}).Where("tx =?", tx).Where("action=?", action).UpdateColumn(TransactionHistory{Extra: extra})
 }
@@ -5,11 +5,10 @@ import (
 	"errors"
 	"math/big"
 	"math/rand"
-	"strings"
 	"time"
 
-	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 
 	solsha3 "github.com/evolutionlandorg/evo-backend/pkg/github.com/miguelmota/go-solidity-sha3"
 	"github.com/jinzhu/gorm"
@@ -168,17 +167,20 @@ func (ec *EthTransactionCallback) LuckyBagCallback(ctx context.Context) error {
 	silverBoxCount := 0
 	wallet := ""
 	chain := ec.Receipt.ChainSource
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("luckyBag", chain)) {
-			logSlice := util.LogAnalysis(log.Data)
-			if util.AddHex(log.Topics[0]) == services.AbiEncodingMethod("GoldBoxSale(address,uint256,uint256)") {
-				wallet = util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				goldBoxCount = util.StringToInt(util.U256(logSlice[0]).String())
-			} else if util.AddHex(log.Topics[0]) == services.AbiEncodingMethod("SilverBoxSale(address,uint256,uint256)") {
-				wallet = util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				silverBoxCount = util.StringToInt(util.U256(logSlice[0]).String())
-			}
-		}
+	err := ec.eachEvent(util.GetContractAddress("luckyBag", chain),
+		events.On(func(ev *GoldBoxSale) error {
+			wallet = eventAddress(ev.Buyer, chain)
+			goldBoxCount = int(ev.Amount.Int64())
+			return nil
+		}),
+		events.On(func(ev *SilverBoxSale) error {
+			wallet = eventAddress(ev.Buyer, chain)
+			silverBoxCount = int(ev.Amount.Int64())
+			return nil
+		}),
+	)
+	if err != nil {
+		return err
 	}
 	return updateTreasure(ctx, ec.Tx, wallet, goldBoxCount, silverBoxCount, chain)
 }
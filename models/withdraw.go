@@ -2,10 +2,10 @@ package models
 
 import (
 	"context"
-	"strings"
 
 	"github.com/evolutionlandorg/evo-backend/services"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/events"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
@@ -36,47 +36,9 @@ func (ec *EthTransactionCallback) TakeBackCallback(ctx context.Context) error {
 		return errors.New("tx exist")
 	}
 	chain := ec.Receipt.ChainSource
-	var (
-		err error
-	)
-
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("takeBack", chain)) {
-			if util.AddHex(log.Topics[0]) == services.AbiEncodingMethod("TakedBack(address,uint256,uint256)") {
-				wallet := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				nonce := util.StringToInt(util.U256(log.Topics[2]).String())
-				member := GetMemberByAddress(ctx, wallet, chain)
-				if member == nil {
-					return errors.New("record not find")
-				}
-
-				db := util.DbBegin(ctx)
-
-				amount := util.BigToDecimal(util.U256(log.Data), util.GetTokenDecimals(chain))
-				account := member.TouchAccount(ctx, currencyRing, wallet, chain)
-				if err := account.subBalance(db, amount, "withdraw"); err != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "balance insufficient")
-				}
-				err = member.updateWithdrawNonce(ctx, db, nonce+1, chain, currencyRing)
-				if err != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "new withdraw error")
-				}
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: wallet, BalanceChange: amount, Action: TransactionHistoryWithdraw, Currency: currencyRing}
-				_ = th.New(db)
-				if err = account.NewWithdraw(db, util.IntToString(nonce), ec.Tx, amount, currencyRing); err != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "new withdraw error")
-				}
-				db.DbCommit()
-				if db.Error != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "commit TakeBackCallback error")
-				}
-				go afterWithdraw(chain)
-			}
-		}
+	err := ec.eachEvent(util.GetContractAddress("takeBack", chain), ec.onTakedBack(ctx, currencyRing, util.GetTokenDecimals(chain)))
+	if err != nil {
+		return err
 	}
 
 	unIrDb := util.DbBegin(ctx)
@@ -88,50 +50,14 @@ func (ec *EthTransactionCallback) TakeBackCallback(ctx context.Context) error {
 	return unIrDb.Error
 }
 
-// TakeBackKtonCallback
 func (ec *EthTransactionCallback) TakeBackKtonCallback(ctx context.Context) error {
 
 	if getTransactionDeal(ctx, ec.Tx, "TakeBackKton") != nil {
 		return errors.New("tx exist")
 	}
 	chain := ec.Receipt.ChainSource
-	var err error
-	for _, log := range ec.Receipt.Logs {
-		if len(log.Topics) != 0 && strings.EqualFold(util.AddHex(log.Address, chain), util.GetContractAddress("TakeBackKton", chain)) {
-			if util.AddHex(log.Topics[0]) == services.AbiEncodingMethod("TakedBack(address,uint256,uint256)") {
-				wallet := util.AddHex(util.TrimHex(log.Topics[1])[24:64], chain)
-				nonce := util.StringToInt(util.U256(log.Topics[2]).String())
-				member := GetMemberByAddress(ctx, wallet, chain)
-				if member == nil {
-					return errors.New("record not find")
-				}
-
-				db := util.DbBegin(ctx)
-
-				amount := util.BigToDecimal(util.U256(log.Data))
-				account := member.TouchAccount(ctx, currencyKton, wallet, chain)
-				if err := account.subBalance(db, amount, "withdraw"); err != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "balance insufficient")
-				}
-				err = member.updateWithdrawNonce(ctx, db, nonce+1, chain, currencyKton)
-				if err != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "new withdraw error")
-				}
-				th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: wallet, BalanceChange: amount, Action: TransactionHistoryWithdraw, Currency: currencyKton}
-				_ = th.New(db)
-				if err = account.NewWithdraw(db, util.IntToString(nonce), ec.Tx, amount, currencyKton); err != nil {
-					db.DbRollback()
-					return errors.Wrap(err, "new withdraw error")
-				}
-				db.DbCommit()
-				if db.Error != nil {
-					return errors.Wrap(err, "commit TakeBackKtonCallback error")
-				}
-				go afterWithdraw(chain)
-			}
-		}
+	if err := ec.eachEvent(util.GetContractAddress("TakeBackKton", chain), ec.onTakedBack(ctx, currencyKton)); err != nil {
+		return err
 	}
 
 	unTrDb := util.DbBegin(ctx)
@@ -143,6 +69,42 @@ func (ec *EthTransactionCallback) TakeBackKtonCallback(ctx context.Context) erro
 	return unTrDb.Error
 }
 
+// onTakedBack withdraw currency from member account
+func (ec *EthTransactionCallback) onTakedBack(ctx context.Context, currency string, decimals ...int32) events.Handler {
+	chain := ec.Receipt.ChainSource
+	return events.On(func(ev *TakedBack) error {
+		wallet := eventAddress(ev.User, chain)
+		nonce := int(ev.Nonce.Int64())
+		member := GetMemberByAddress(ctx, wallet, chain)
+		if member == nil {
+			return errors.New("record not find")
+		}
+
+		db := util.DbBegin(ctx)
+		defer db.DbRollback()
+
+		amount := util.BigToDecimal(ev.Value, decimals...)
+		account := member.TouchAccount(ctx, currency, wallet, chain)
+		if err := account.subBalance(db, amount, "withdraw:"+ec.Tx); err != nil {
+			return errors.Wrap(err, "balance insufficient")
+		}
+		if err := member.updateWithdrawNonce(ctx, db, nonce+1, chain, currency); err != nil {
+			return errors.Wrap(err, "new withdraw error")
+		}
+		th := TransactionHistory{Tx: ec.Tx, Chain: chain, BalanceAddress: wallet, BalanceChange: amount, Action: TransactionHistoryWithdraw, Currency: currency}
+		_ = th.New(db)
+		if err := account.NewWithdraw(db, util.IntToString(nonce), ec.Tx, amount, currency); err != nil {
+			return errors.Wrap(err, "new withdraw error")
+		}
+		db.DbCommit()
+		if db.Error != nil {
+			return errors.Wrap(db.Error, "commit withdraw error")
+		}
+		go afterWithdraw(chain)
+		return nil
+	})
+}
+
 func afterWithdraw(chain string) {
 	if !util.IsProduction() {
 		return
@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary	List off-chain accounts of login member
+// @Tags		account
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		currency		query		string	false	"currency"
+// @Param		chain			query		string	false	"chain"
+// @Success	200				{object}	routes.GinJSON{data=[]models.AccountJson}
+// @Router		/account/list [get]
+func accountList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c, true)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		accounts := member.Accounts(util.GetContextByGin(c), c.Query("currency"), c.Query("chain"))
+		data := make([]models.AccountJson, 0, len(accounts))
+		for _, account := range accounts {
+			data = append(data, account.AsJson())
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data})
+	}
+}
+
+// @Summary	List balance changes of login member accounts, tx is set when the change is caused by a transaction
+// @Tags		account
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		account_id		query		int		false	"account id"
+// @Param		currency		query		string	false	"currency"
+// @Param		chain			query		string	false	"chain"
+// @Param		reason			query		string	false	"reason"
+// @Param		remark			query		string	false	"remark"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Success	200				{object}	routes.GinJSON{data=[]models.AccountVersionJson}
+// @Router		/account/ledger [get]
+func accountLedger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c, true)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		p := new(struct {
+			AccountId uint   `form:"account_id"`
+			Currency  string `form:"currency"`
+			Chain     string `form:"chain"`
+			Reason    string `form:"reason"`
+			Remark    string `form:"remark"`
+			Page      int    `form:"page"`
+			Row       int    `form:"row"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.Row <= 0 || p.Row > 100 {
+			p.Row = 20
+		}
+		ctx := util.GetContextByGin(c)
+		query := models.AvQuery{
+			WhereQuery: models.VersionQuery{Reason: p.Reason, AccountId: p.AccountId, Remark: p.Remark},
+			Page:       p.Page,
+			Row:        p.Row,
+		}
+		list, count := query.Ledger(ctx, member.Accounts(ctx, p.Currency, p.Chain))
+		if list == nil {
+			list = []models.AccountVersionJson{}
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": list, "count": count})
+	}
+}
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/services/storage"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/roundrobin"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminAuth admin api is disabled when ADMIN_TOKEN is empty
+func adminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const AdminTokenHeader = "EVO-ADMIN-TOKEN"
+		token := util.GetEnv("ADMIN_TOKEN", "")
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(AdminTokenHeader)), []byte(token)) != 1 {
+			getReturnDataByError(c, 10010)
+			return
+		}
+		c.Next()
+	}
+}
+
+type idUri struct {
+	Id uint `uri:"id" binding:"required"`
+}
+
+func bindParseTxError(c *gin.Context) *models.ParseTxError {
+	p := new(idUri)
+	if err := c.ShouldBindUri(p); err != nil {
+		getReturnDataByError(c, 10001, err.Error())
+		return nil
+	}
+	pe := models.GetParseTxError(util.GetContextByGin(c), p.Id)
+	if pe == nil {
+		getReturnDataByError(c, 10404)
+		return nil
+	}
+	return pe
+}
+
+// @Summary	List failed callbacks of the dead-letter queue
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		status			query		string	false	"status"	Enums(pending,resolved,discarded,dead,legacy)
+// @Param		chain			query		string	false	"chain"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Success	200				{object}	routes.GinJSON{data=[]models.ParseTxErrorJson}
+// @Router		/admin/parse_tx_errors [get]
+func parseTxErrorList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := new(struct {
+			Status string `form:"status" binding:"omitempty,oneof=pending resolved discarded dead legacy"`
+			Chain  string `form:"chain"`
+			Page   int    `form:"page"`
+			Row    int    `form:"row"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.Row <= 0 {
+			p.Row = 20
+		}
+		query := models.ParseTxErrorQuery{Status: p.Status, Chain: p.Chain, Page: p.Page, Row: p.Row}
+		list, count := query.List(util.GetContextByGin(c))
+		data := make([]models.ParseTxErrorJson, 0, len(list))
+		for _, pe := range list {
+			data = append(data, pe.AsJson())
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data, "count": count})
+	}
+}
+
+// @Summary	Get failed callback with its receipts
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		id				path		int		true	"id"
+// @Success	200				{object}	routes.GinJSON{data=models.ParseTxErrorJson}
+// @Router		/admin/parse_tx_errors/{id} [get]
+func parseTxErrorInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pe := bindParseTxError(c)
+		if pe == nil {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": pe.AsJson()})
+	}
+}
+
+// @Summary	Retry failed callback now
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		id				path		int		true	"id"
+// @Success	200				{object}	routes.GinJSON{data=models.ParseTxErrorJson}
+// @Router		/admin/parse_tx_errors/{id}/retry [post]
+func parseTxErrorRetry() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pe := bindParseTxError(c)
+		if pe == nil {
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		if err := pe.Retry(ctx); errors.Is(err, models.ErrRetryInProgress) {
+			getReturnDataByError(c, 10045)
+			return
+		} else if err != nil {
+			getReturnDataByError(c, 10000, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": models.GetParseTxError(ctx, pe.ID).AsJson()})
+	}
+}
+
+// @Summary	Discard failed callback
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		id				path		int		true	"id"
+// @Success	200				{object}	routes.GinJSON{data=models.ParseTxErrorJson}
+// @Router		/admin/parse_tx_errors/{id}/discard [post]
+func parseTxErrorDiscard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pe := bindParseTxError(c)
+		if pe == nil {
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		if err := pe.Discard(ctx); err != nil {
+			getReturnDataByError(c, 10000, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": models.GetParseTxError(ctx, pe.ID).AsJson()})
+	}
+}
+
+// @Summary	Health of rpc endpoints of each chain
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		chain			query		string	false	"chain"
+// @Success	200				{object}	routes.GinJSON{data=map[string][]roundrobin.EndpointHealth}
+// @Router		/admin/rpc_health [get]
+func rpcHealth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chain := c.Query("chain")
+		data := make(map[string][]roundrobin.EndpointHealth)
+		for network := range util.Evo.WipeBlock {
+			if chain != "" && !strings.EqualFold(chain, network) {
+				continue
+			}
+			data[network] = storage.New(network).EndpointHealth()
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data})
+	}
+}
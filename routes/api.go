@@ -10,56 +10,111 @@ import (
 
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/util"
-	"github.com/gin-contrib/cache"
-	"github.com/gin-contrib/cache/persistence"
 	"github.com/gin-gonic/gin"
 )
 
 type ApiHandle struct {
 	RouterGroup *gin.RouterGroup
+	// ErrorEnvelope respond errors with http status and ErrorEnvelope instead of 200
+	ErrorEnvelope bool
 }
 
 func (ap *ApiHandle) StartHttpApi() {
-	store := persistence.NewInMemoryStore(time.Second)
-	handleCache := func(store persistence.CacheStore, expire time.Duration, handle gin.HandlerFunc) gin.HandlerFunc {
-		if util.IsProduction() {
-			return cache.CachePageAtomic(store, expire, handle)
-		}
-		return handle
-	}
 	api := ap.RouterGroup
-	api.Use(headerMaker())
+	if ap.ErrorEnvelope {
+		api.Use(errorEnvelope())
+	}
+	api.Use(headerMaker(), session())
 
-	api.POST("snapshot/vote/", Snapshot())
+	api.POST("snapshot/vote/", rateLimit("snapshot", 0.2, 3), Snapshot())
 
 	// system
 	api.GET("common/time", timeHandle())
 
+	// auth
+	api.GET("auth/challenge", rateLimit("auth", 1, 10), authChallenge())
+	api.POST("auth/login", rateLimit("auth", 1, 10), authLogin())
+
+	// member
+	api.POST("member/register", memberRegister())
+	api.GET("member/profile", memberProfile())
+	api.POST("member/profile", memberUpdateProfile())
+	api.POST("member/email", memberBindEmail())
+	api.GET("member/login_history", memberLoginHistory())
+
+	// account
+	api.GET("account/list", accountList())
+	api.GET("account/ledger", accountLedger())
+
+	// transaction
+	api.GET("transaction/history", rateLimit("transaction/history", 1, 10), transactionHistory())
+
+	// building
+	api.GET("building/drawings", buildingDrawings())
+	api.GET("building/list", buildingList())
+	api.GET("building/lands", buildingLands())
+	api.POST("building/create", buildingCreate())
+	api.POST("building/upgrade", buildingUpgrade())
+	api.POST("building/hiring", buildingHiring())
+	api.POST("building/hiring/join", buildingHiringJoin())
+	api.POST("building/admin_hiring", buildingAdminHiring())
+	api.POST("building/admin_hiring/join", buildingAdminHiringJoin())
+	api.POST("building/admin_hiring/end", buildingWorkEnds())
+
+	// dapp
+	api.GET("dapp/list", dappList())
+	api.POST("dapp/publish", dappPublish())
+	api.POST("dapp/edit", dappEdit())
+	api.POST("dapp/remove", dappRemove())
+	api.POST("dapp/report", rateLimit("dapp/report", 0.1, 3), dappReport())
+
+	// stream
+	api.GET("stream/ws", rateLimit("stream", 0.2, 5), streamWebSocket())
+	api.GET("stream/sse", rateLimit("stream", 0.2, 5), streamSSE())
+
 	// land
-	api.GET("lands", handleCache(store, time.Minute, landListHandle()))
+	api.GET("lands", rateLimit("lands", 1, 10), responseCache(time.Minute, cacheTags("district", "wallet"), landListHandle()))
 	api.GET("land", landHandle())
 
-	api.GET("land/rank", handleCache(store, time.Minute, landsRank()))
+	api.GET("land/rank", rateLimit("land/rank", 1, 10), responseCache(time.Minute, cacheTags("district"), landsRank()))
 
 	api.GET("nft/metadata/:token_id", nftMetadata())
 
 	// apostle
-	api.GET("apostle/list", handleCache(store, time.Second*30, apostleListHandle()))
+	api.GET("apostle/list", rateLimit("apostle/list", 2, 20), responseCache(time.Second*30, cacheTags("district", "wallet"), apostleListHandle()))
 	api.GET("apostle/info", apostleHandle())
 
 	api.GET("furnace/illustrated", illustrated())
 	api.GET("furnace/prop", furnaceProp())
-	api.GET("furnace/props", furnaceProps())
+	api.GET("furnace/props", rateLimit("furnace/props", 2, 20), furnaceProps())
 
 	// farm
 	api.GET("farm/apr", farmAPR())
 
 	// equipment
-	api.GET("equipment/list", handleCache(store, time.Minute, equipmentList()))
-	api.GET("equipment/info", handleCache(store, time.Minute, equipmentInfo()))
+	api.GET("equipment/list", responseCache(time.Minute, cacheTags("district", "wallet"), equipmentList()))
+	api.GET("equipment/info", responseCache(time.Minute, cacheTags("token"), equipmentInfo()))
+
+	// admin
+	admin := api.Group("admin", adminAuth())
+	admin.GET("parse_tx_errors", parseTxErrorList())
+	admin.GET("parse_tx_errors/:id", parseTxErrorInfo())
+	admin.POST("parse_tx_errors/:id/retry", parseTxErrorRetry())
+	admin.POST("parse_tx_errors/:id/discard", parseTxErrorDiscard())
+	admin.GET("rpc_health", rpcHealth())
+	admin.GET("status", serviceStatus())
+	admin.GET("transaction_history", adminTransactionHistory())
+	admin.GET("dapps", adminDappList())
+	admin.POST("dapps/:id/status", adminDappStatus())
+	admin.GET("dapp_reports", adminDappReportList())
+	admin.POST("dapp_reports/:id/review", adminDappReportReview())
 }
 
 func getReturnDataByError(c *gin.Context, code int, msg ...string) {
+	if c.GetBool(errorEnvelopeKey) {
+		abortWithErrorEnvelope(c, code, msg...)
+		return
+	}
 	detail := util.QYError{Code: code}
 	c.Writer.Header().Set("content-type", "application/json; charset=utf-8")
 	res := gin.H{"code": code, "detail": detail.GetCode()}
@@ -84,7 +139,9 @@ func headerMaker() gin.HandlerFunc {
 		if qChain := context.Query(NetworkHeader); qChain != "" {
 			chain = qChain
 		}
-		headerQuery := fmt.Sprintf("cache=%x", md5.Sum([]byte(url.QueryEscape(chain+bodyEncode))))
+		// responses of session are cached per token
+		session := context.GetHeader("Authorization")
+		headerQuery := fmt.Sprintf("cache=%x", md5.Sum([]byte(url.QueryEscape(chain+bodyEncode+session))))
 		if context.Request.URL.RawQuery == "" {
 			context.Request.URL.RawQuery += headerQuery
 		} else {
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// session set the wallet of bearer session token for models.AuthOwner, request without token is anonymous
+func session() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer"))
+		if token == "" {
+			c.Next()
+			return
+		}
+		claims, err := models.ParseSession(token)
+		if err != nil {
+			getReturnDataByError(c, 99999, err.Error())
+			return
+		}
+		c.Set(models.SessionWalletKey, claims.Wallet)
+		c.Set(models.SessionChainKey, claims.Chain)
+		c.Next()
+	}
+}
+
+// @Summary	Get challenge for login, sign "welcome to evolution land {challenge}" with the wallet
+// @Tags		auth
+// @Param		EVO-NETWORK	header		string	false	"chain"
+// @Param		wallet		query		string	true	"wallet"
+// @Success	200			{object}	routes.GinJSON{data=object{challenge=string,message=string}}
+// @Router		/auth/challenge [get]
+func authChallenge() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := c.Query("wallet")
+		if wallet == "" {
+			getReturnDataByError(c, 10003)
+			return
+		}
+		challenge, err := models.NewChallenge(util.GetContextByGin(c), c.GetString("EvoNetwork"), wallet)
+		if err != nil {
+			getReturnDataByError(c, 10000, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": gin.H{
+			"challenge": challenge,
+			"message":   fmt.Sprintf("welcome to evolution land %s", challenge),
+		}})
+	}
+}
+
+// @Summary	Login with signature of challenge, use the token as "Authorization: Bearer {token}"
+// @Tags		auth
+// @Param		EVO-NETWORK	header		string	false	"chain"
+// @Param		wallet		formData	string	true	"wallet"
+// @Param		sign		formData	string	true	"signature of challenge message"
+// @Param		challenge	formData	string	true	"challenge"
+// @Success	200			{object}	routes.GinJSON{data=models.Session}
+// @Router		/auth/login [post]
+func authLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := new(models.ValidateLogin)
+		if err := c.ShouldBind(v); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		v.Chain = c.GetString("EvoNetwork")
+		s, err := v.Login(util.GetContextByGin(c), c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": s})
+	}
+}
@@ -0,0 +1,423 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/shopspring/decimal"
+
+	"github.com/gin-gonic/gin"
+)
+
+type hiringParams struct {
+	BuildingId    uint   `form:"building_id" json:"building_id" binding:"required"`
+	Payment       string `form:"payment" json:"payment" binding:"required,oneof=ring kton"`
+	PaymentAmount string `form:"payment_amount" json:"payment_amount" binding:"required"`
+	TalentLimit   uint   `form:"talent_limit" json:"talent_limit"`
+	WorkerLimit   uint   `form:"worker_limit" json:"worker_limit"`
+	AdminLimit    uint   `form:"admin_limit" json:"admin_limit"`
+	Duration      uint   `form:"duration" json:"duration"`
+}
+
+type joinParams struct {
+	BuildingId      uint     `form:"building_id" json:"building_id" binding:"required"`
+	ApostleTokenIds []string `form:"apostle_token_ids" json:"apostle_token_ids" binding:"required,min=1,dive,required"`
+}
+
+// buildingWallet the wallet of login member on current chain, response is written when it is empty
+func buildingWallet(c *gin.Context) string {
+	member := models.AuthOwner(c, true)
+	if member == nil {
+		getReturnDataByError(c, 99999)
+		return ""
+	}
+	wallet := member.GetUseAddress(c.GetString("EvoNetwork"))
+	if wallet == "" {
+		getReturnDataByError(c, 10035)
+	}
+	return wallet
+}
+
+// ownBuilding building of id owned by wallet, response is written when it is nil
+func ownBuilding(c *gin.Context, id uint, wallet string) *models.Building {
+	building := models.GetBuilding(util.GetContextByGin(c), id)
+	if building == nil {
+		getReturnDataByError(c, 10404)
+		return nil
+	}
+	if !strings.EqualFold(building.Address, wallet) {
+		modelError(c, models.ErrBuildingOwner)
+		return nil
+	}
+	return building
+}
+
+// @Summary	List building drawings
+// @Tags		building
+// @Success	200	{object}	routes.GinJSON{data=[]models.Drawing}
+// @Router		/building/drawings [get]
+func buildingDrawings() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": models.GetDrawings()})
+	}
+}
+
+// @Summary	List buildings of login wallet
+// @Tags		building
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Success	200				{object}	routes.GinJSON{data=[]models.BuildingDetail}
+// @Router		/building/list [get]
+func buildingList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		buildings := models.Builds(ctx, &models.ListOpt{WhereQuery: util.Wheres{util.Eq("address", wallet)}})
+		data := make([]*models.BuildingDetail, 0, len(buildings))
+		for i := range buildings {
+			data = append(data, buildings[i].AsJson(ctx, nil))
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data})
+	}
+}
+
+// @Summary	Lands the building of drawing occupies when placed at land_token_id, the lower left one
+// @Tags		building
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Param		drawing_index	query		int		true	"drawing index"
+// @Param		land_token_id	query		string	true	"land token id"
+// @Success	200				{object}	routes.GinJSON{data=[]models.Land}
+// @Router		/building/lands [get]
+func buildingLands() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(struct {
+			DrawingIndex int    `form:"drawing_index" binding:"required"`
+			LandTokenId  string `form:"land_token_id" binding:"required"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		drawing := models.GetDrawing(p.DrawingIndex)
+		if drawing == nil {
+			modelError(c, models.ErrDrawing)
+			return
+		}
+		lands, err := drawing.CheckLand(util.GetContextByGin(c), p.LandTokenId, wallet)
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": lands})
+	}
+}
+
+// @Summary	Place building of drawing on lands of login wallet, land_token_id is the lower left one
+// @Tags		building
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Param		drawing_index	formData	int		true	"drawing index"
+// @Param		land_token_id	formData	string	true	"land token id"
+// @Success	200				{object}	routes.GinJSON{data=models.BuildingDetail}
+// @Router		/building/create [post]
+func buildingCreate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(struct {
+			DrawingIndex int    `form:"drawing_index" json:"drawing_index" binding:"required"`
+			LandTokenId  string `form:"land_token_id" json:"land_token_id" binding:"required"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		drawing := models.GetDrawing(p.DrawingIndex)
+		if drawing == nil {
+			modelError(c, models.ErrDrawing)
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building, err := drawing.CreateBuilding(ctx, p.LandTokenId, wallet)
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": building.AsJson(ctx, nil)})
+	}
+}
+
+// @Summary	Upgrade idle building of login wallet
+// @Tags		building
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Param		building_id		formData	int		true	"building id"
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/building/upgrade [post]
+func buildingUpgrade() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(struct {
+			BuildingId uint `form:"building_id" json:"building_id" binding:"required"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building := ownBuilding(c, p.BuildingId, wallet)
+		if building == nil {
+			return
+		}
+		if err := building.Upgrade(ctx); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	Open or update worker hiring of constructing or upgrading building, worker_limit is at most miner_limit of drawing
+// @Tags		building
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Param		building_id		formData	int		true	"building id"
+// @Param		payment			formData	string	true	"payment currency"	Enums(ring,kton)
+// @Param		payment_amount	formData	string	true	"payment amount"
+// @Param		talent_limit	formData	int		false	"talent limit"
+// @Param		worker_limit	formData	int		true	"worker limit"
+// @Success	200				{object}	routes.GinJSON{data=models.BuildingHiring}
+// @Router		/building/hiring [post]
+func buildingHiring() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(hiringParams)
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		amount, err := decimal.NewFromString(p.PaymentAmount)
+		if err != nil || amount.IsNegative() {
+			getReturnDataByError(c, 10001, "error payment_amount")
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building := ownBuilding(c, p.BuildingId, wallet)
+		if building == nil {
+			return
+		}
+		if !util.StringInSlice(building.Status, []string{models.Constructing, models.Upgrading}) {
+			modelError(c, models.ErrBuildingStatus)
+			return
+		}
+		if p.WorkerLimit == 0 || int(p.WorkerLimit) > building.Drawing().MinerLimit {
+			modelError(c, models.ErrMinerLimit)
+			return
+		}
+		if hiring := building.Hiring(ctx); hiring != nil {
+			err = hiring.Update(ctx, p.Payment, amount, p.TalentLimit, p.WorkerLimit)
+			if err == nil && !building.HiringStatus {
+				building.ToggleHiring(ctx)
+			}
+		} else {
+			err = building.CreateHiring(ctx, p.Payment, amount, p.TalentLimit, p.WorkerLimit)
+		}
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": building.Hiring(ctx)})
+	}
+}
+
+// @Summary	Join worker hiring of building with apostles of login wallet
+// @Tags		building
+// @Param		Authorization		header		string		true	"Bearer {token}"
+// @Param		EVO-NETWORK			header		string		false	"chain"
+// @Param		building_id			formData	int			true	"building id"
+// @Param		apostle_token_ids	formData	[]string	true	"apostle token ids"	collectionFormat(multi)
+// @Success	200					{object}	routes.GinJSON{data=nil}
+// @Router		/building/hiring/join [post]
+func buildingHiringJoin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(joinParams)
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building := models.GetBuilding(ctx, p.BuildingId)
+		if building == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		hiring := building.Hiring(ctx)
+		if hiring == nil {
+			modelError(c, models.ErrHiringClosed)
+			return
+		}
+		if err := hiring.Join(ctx, wallet, p.ApostleTokenIds); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	Open or update admin hiring of idle building, admin_limit is at most admin_limit of drawing
+// @Tags		building
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Param		building_id		formData	int		true	"building id"
+// @Param		payment			formData	string	true	"payment currency"	Enums(ring,kton)
+// @Param		payment_amount	formData	string	true	"payment amount"
+// @Param		talent_limit	formData	int		false	"talent limit"
+// @Param		admin_limit		formData	int		true	"admin limit"
+// @Param		duration		formData	int		true	"days of employment"
+// @Success	200				{object}	routes.GinJSON{data=models.BuildingAdminHiring}
+// @Router		/building/admin_hiring [post]
+func buildingAdminHiring() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(hiringParams)
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		amount, err := decimal.NewFromString(p.PaymentAmount)
+		if err != nil || amount.IsNegative() || p.Duration == 0 {
+			getReturnDataByError(c, 10001, "error payment_amount or duration")
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building := ownBuilding(c, p.BuildingId, wallet)
+		if building == nil {
+			return
+		}
+		if building.Status != models.Idle {
+			modelError(c, models.ErrBuildingStatus)
+			return
+		}
+		if p.AdminLimit == 0 || int(p.AdminLimit) > building.Drawing().AdminLimit {
+			modelError(c, models.ErrAdminLimit)
+			return
+		}
+		if hiring := building.AdminHiring(ctx); hiring != nil {
+			err = hiring.Update(ctx, p.Payment, amount, p.TalentLimit, p.AdminLimit, p.Duration)
+			if err == nil && !building.AdminHiringStatus {
+				building.ToggleAdmin(ctx)
+			}
+		} else {
+			err = building.CreateAdminHiring(ctx, p.Payment, amount, p.TalentLimit, p.AdminLimit, p.Duration)
+		}
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": building.AdminHiring(ctx)})
+	}
+}
+
+// @Summary	Join admin hiring of building with apostles of login wallet, apostles of other wallets are employed for duration days
+// @Tags		building
+// @Param		Authorization		header		string		true	"Bearer {token}"
+// @Param		EVO-NETWORK			header		string		false	"chain"
+// @Param		building_id			formData	int			true	"building id"
+// @Param		apostle_token_ids	formData	[]string	true	"apostle token ids"	collectionFormat(multi)
+// @Success	200					{object}	routes.GinJSON{data=nil}
+// @Router		/building/admin_hiring/join [post]
+func buildingAdminHiringJoin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(joinParams)
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building := models.GetBuilding(ctx, p.BuildingId)
+		if building == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		hiring := building.AdminHiring(ctx)
+		if hiring == nil {
+			modelError(c, models.ErrHiringClosed)
+			return
+		}
+		if err := hiring.Join(ctx, building.Address, wallet, p.ApostleTokenIds); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	End admin work of apostle of login wallet in building
+// @Tags		building
+// @Param		Authorization		header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK			header		string	false	"chain"
+// @Param		building_id			formData	int		true	"building id"
+// @Param		apostle_token_id	formData	string	true	"apostle token id"
+// @Success	200					{object}	routes.GinJSON{data=nil}
+// @Router		/building/admin_hiring/end [post]
+func buildingWorkEnds() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := buildingWallet(c)
+		if wallet == "" {
+			return
+		}
+		p := new(struct {
+			BuildingId     uint   `form:"building_id" json:"building_id" binding:"required"`
+			ApostleTokenId string `form:"apostle_token_id" json:"apostle_token_id" binding:"required"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		building := models.GetBuilding(ctx, p.BuildingId)
+		if building == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		hiring := building.AdminHiring(ctx)
+		if hiring == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		if err := hiring.WorkEnds(ctx, wallet, p.ApostleTokenId); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// cacheTags tags of request, one of district, wallet and token for each kind.
+// district is the district query or the district of chain, wallet is the address query or the session wallet
+func cacheTags(kinds ...string) func(c *gin.Context) []string {
+	return func(c *gin.Context) []string {
+		var tags []string
+		for _, kind := range kinds {
+			switch kind {
+			case "district":
+				district := util.StringToInt(c.Query("district"))
+				if district <= 0 {
+					district = models.GetDistrictByChain(c.GetString("EvoNetwork"))
+				}
+				tags = append(tags, models.ChangeTag(kind, district))
+			case "wallet":
+				if wallet := c.Query("address"); wallet != "" {
+					tags = append(tags, models.ChangeTag(kind, wallet))
+				} else if wallet = c.GetString(models.SessionWalletKey); wallet != "" {
+					tags = append(tags, models.ChangeTag(kind, wallet))
+				}
+			case "token":
+				if tokenId := c.Query("token_id"); tokenId != "" {
+					tags = append(tags, models.ChangeTag(kind, tokenId))
+				}
+			}
+		}
+		return tags
+	}
+}
+
+// responseCache cache successful responses in redis shared by all instances for expire. The key contains the
+// versions of tags, worker callbacks bump the tags of their changes so the cached responses are stale at once
+func responseCache(expire time.Duration, tags func(c *gin.Context) []string, handle gin.HandlerFunc) gin.HandlerFunc {
+	if !util.IsProduction() {
+		return handle
+	}
+	return func(c *gin.Context) {
+		ctx := util.GetContextByGin(c)
+		tagList := tags(c)
+		// headerMaker adds the chain, post body and session to the query
+		key := fmt.Sprintf("ResponseCache:%x", md5.Sum([]byte(fmt.Sprintf("%s|%v|%v", c.Request.URL.RequestURI(), tagList, util.CacheTagVersions(ctx, tagList)))))
+		if b := util.GetCache(ctx, key); b != nil {
+			var r cachedResponse
+			if err := json.Unmarshal(b, &r); err == nil {
+				c.Data(http.StatusOK, r.ContentType, r.Body)
+				c.Abort()
+				return
+			}
+		}
+		w := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		handle(c)
+		c.Writer = w.ResponseWriter
+		// errors of getReturnDataByError are aborted
+		if c.IsAborted() || w.Status() != http.StatusOK {
+			return
+		}
+		b, _ := json.Marshal(cachedResponse{ContentType: w.Header().Get("Content-Type"), Body: w.body.Bytes()})
+		_ = util.SetCache(ctx, key, b, int(expire.Seconds()))
+	}
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache(t *testing.T) {
+	newTestRedis(t)
+	inProduction(t)
+	var calls int
+	r := gin.New()
+	r.GET("/land", responseCache(time.Minute, cacheTags("district", "token"), func(c *gin.Context) {
+		calls++
+		if c.Query("fail") != "" {
+			getReturnDataByError(c, 10001)
+			return
+		}
+		c.String(http.StatusOK, "land %d", calls)
+	}))
+	get := func(uri string) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, uri, nil))
+		return w.Body.String()
+	}
+	uri := "/land?district=1&token_id=0x01"
+	assert.Equal(t, "land 1", get(uri))
+	assert.Equal(t, "land 1", get(uri))
+	assert.Equal(t, "land 2", get("/land?district=2&token_id=0x01"))
+
+	// changes of other tags keep the cached response, changes of its tags make it stale
+	util.InvalidateCacheTags(context.Background(), models.ChangeTag("district", 3))
+	assert.Equal(t, "land 1", get(uri))
+	util.InvalidateCacheTags(context.Background(), models.ChangeTag("token", "0x01"))
+	assert.Equal(t, "land 3", get(uri))
+	assert.Equal(t, "land 3", get(uri))
+
+	// errors are not cached
+	get("/land?fail=1")
+	get("/land?fail=1")
+	assert.Equal(t, 5, calls)
+}
@@ -0,0 +1,368 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ownLand land of id owned by login member on the chain of land, response is written when it is nil
+func ownLand(c *gin.Context, id uint) (*models.Member, *models.Land) {
+	member := models.AuthOwner(c)
+	if member == nil {
+		getReturnDataByError(c, 99999)
+		return nil, nil
+	}
+	land := models.GetLandById(util.GetContextByGin(c), id)
+	if land == nil {
+		getReturnDataByError(c, 10404)
+		return nil, nil
+	}
+	wallet := member.GetUseAddress(models.GetChainByTokenId(land.TokenId))
+	if wallet == "" || !strings.EqualFold(land.Owner, wallet) {
+		modelError(c, models.ErrDappLandOwner)
+		return nil, nil
+	}
+	return member, land
+}
+
+func validDappCategory(c *gin.Context, category string) bool {
+	if !util.StringInSlice(category, models.DappCategory) {
+		getReturnDataByError(c, 10001, "unknown category")
+		return false
+	}
+	return true
+}
+
+// @Summary	List published dapps
+// @Tags		dapp
+// @Param		category	query		string	false	"category"	Enums(Game,Social,Tool,MarketPlace,Casino,Other)
+// @Param		page		query		int		false	"page"
+// @Param		row			query		int		false	"row"
+// @Success	200			{object}	routes.GinJSON{data=[]models.DappJson}
+// @Router		/dapp/list [get]
+func dappList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := new(struct {
+			Category string `form:"category"`
+			Page     int    `form:"page"`
+			Row      int    `form:"row"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.Category != "" && !validDappCategory(c, p.Category) {
+			return
+		}
+		if p.Row <= 0 || p.Row > 100 {
+			p.Row = 20
+		}
+		query := models.DappQuery{Category: p.Category, Status: models.DappSubmitSuccess, Page: p.Page, Row: p.Row}
+		list, count := query.List(util.GetContextByGin(c))
+		data := make([]models.DappJson, 0, len(list))
+		for _, dapp := range list {
+			data = append(data, dapp.AsJson())
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data, "count": count})
+	}
+}
+
+// @Summary	Publish dapp on land of login member, it is online after review
+// @Tags		dapp
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		land_id			formData	int		true	"land id"
+// @Param		name			formData	string	true	"name"
+// @Param		category		formData	string	true	"category"	Enums(Game,Social,Tool,MarketPlace,Casino,Other)
+// @Param		introduction	formData	string	true	"introduction"
+// @Param		email			formData	string	true	"email"
+// @Param		cover			formData	string	true	"cover url"
+// @Param		url				formData	string	true	"dapp url"
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/dapp/publish [post]
+func dappPublish() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := new(models.ValidateAddDapp)
+		if err := c.ShouldBind(v); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if !validDappCategory(c, v.Category) {
+			return
+		}
+		member, land := ownLand(c, v.LandId)
+		if land == nil {
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		if models.GetDappByLandId(ctx, land.ID) != nil {
+			modelError(c, models.ErrDappExist)
+			return
+		}
+		if err := v.AddDapp(ctx, member.ID); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	Edit dapp on land of login member, the change is online after review
+// @Tags		dapp
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		land_id			formData	int		true	"land id"
+// @Param		name			formData	string	true	"name"
+// @Param		category		formData	string	true	"category"	Enums(Game,Social,Tool,MarketPlace,Casino,Other)
+// @Param		introduction	formData	string	true	"introduction"
+// @Param		email			formData	string	true	"email"
+// @Param		cover			formData	string	true	"cover url"
+// @Param		url				formData	string	true	"dapp url"
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/dapp/edit [post]
+func dappEdit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := new(models.ValidateEditDapp)
+		if err := c.ShouldBind(v); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if !validDappCategory(c, v.Category) {
+			return
+		}
+		_, land := ownLand(c, v.LandId)
+		if land == nil {
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		if v.DappInstant = models.GetDappByLandId(ctx, land.ID); v.DappInstant == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		if err := v.EditDapp(ctx); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	Remove dapp and its versions from land of login member
+// @Tags		dapp
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		land_id			formData	int		true	"land id"
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/dapp/remove [post]
+func dappRemove() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := new(struct {
+			LandId uint `form:"land_id" json:"land_id" binding:"required"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		_, land := ownLand(c, p.LandId)
+		if land == nil {
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		dapp := models.GetDappByLandId(ctx, land.ID)
+		if dapp == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		if err := land.DelDapp(ctx, dapp); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	Report published dapp
+// @Tags		dapp
+// @Param		Authorization	header		string		true	"Bearer {token}"
+// @Param		dapp_id			formData	int			true	"dapp id"
+// @Param		reason			formData	[]string	true	"reasons"	collectionFormat(multi)
+// @Param		remark			formData	string		false	"remark"
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/dapp/report [post]
+func dappReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if member := models.AuthOwner(c); member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		p := new(struct {
+			DappId uint     `form:"dapp_id" json:"dapp_id" binding:"required"`
+			Reason []string `form:"reason" json:"reason" binding:"required,min=1,dive,required,max=50"`
+			Remark string   `form:"remark" json:"remark" binding:"max=200"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		dapp := models.GetDappById(ctx, p.DappId)
+		if dapp == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		if dapp.Status != models.DappSubmitSuccess {
+			modelError(c, models.ErrDappNotPublish)
+			return
+		}
+		if err := dapp.Report(ctx, p.Reason, p.Remark); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	List dapps for moderation, submitted ones are waiting for review
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		status			query		string	false	"status"	Enums(submitted,success,rejected,banned)
+// @Param		category		query		string	false	"category"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Success	200				{object}	routes.GinJSON{data=[]models.DappJson}
+// @Router		/admin/dapps [get]
+func adminDappList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := new(struct {
+			Status   string `form:"status" binding:"omitempty,oneof=submitted success rejected banned"`
+			Category string `form:"category"`
+			Page     int    `form:"page"`
+			Row      int    `form:"row"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.Row <= 0 {
+			p.Row = 20
+		}
+		query := models.DappQuery{Category: p.Category, Status: p.Status, Page: p.Page, Row: p.Row}
+		list, count := query.List(util.GetContextByGin(c))
+		data := make([]models.DappJson, 0, len(list))
+		for _, dapp := range list {
+			data = append(data, dapp.AsJson())
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data, "count": count})
+	}
+}
+
+// @Summary	Approve, reject or ban dapp
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		id				path		int		true	"dapp id"
+// @Param		status			formData	string	true	"status"	Enums(success,rejected,banned)
+// @Success	200				{object}	routes.GinJSON{data=models.DappJson}
+// @Router		/admin/dapps/{id}/status [post]
+func adminDappStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uri := new(idUri)
+		if err := c.ShouldBindUri(uri); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		p := new(struct {
+			Status string `form:"status" json:"status" binding:"required,oneof=success rejected banned"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		dapp := models.GetDappById(ctx, uri.Id)
+		if dapp == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		if err := dapp.SetStatus(ctx, p.Status); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": dapp.AsJson()})
+	}
+}
+
+// @Summary	List dapp reports waiting for review
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		dapp_id			query		int		false	"dapp id"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Success	200				{object}	routes.GinJSON{data=[]models.DappReportJson}
+// @Router		/admin/dapp_reports [get]
+func adminDappReportList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := new(struct {
+			DappId uint `form:"dapp_id"`
+			Page   int  `form:"page"`
+			Row    int  `form:"row"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.Row <= 0 {
+			p.Row = 20
+		}
+		ctx := util.GetContextByGin(c)
+		query := models.DappReportQuery{DappId: p.DappId, Page: p.Page, Row: p.Row}
+		list, count := query.List(ctx)
+		data := make([]models.DappReportJson, 0, len(list))
+		for _, report := range list {
+			data = append(data, report.AsJson(ctx))
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data, "count": count})
+	}
+}
+
+// @Summary	Review dapp report, ban the dapp or dismiss the report
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		id				path		int		true	"report id"
+// @Param		action			formData	string	true	"action"	Enums(ban,dismiss)
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/admin/dapp_reports/{id}/review [post]
+func adminDappReportReview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uri := new(idUri)
+		if err := c.ShouldBindUri(uri); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		p := new(struct {
+			Action string `form:"action" json:"action" binding:"required,oneof=ban dismiss"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		report := models.GetDappReport(ctx, uri.Id)
+		if report == nil {
+			getReturnDataByError(c, 10404)
+			return
+		}
+		var err error
+		if dapp := models.GetDappById(ctx, report.DappId); dapp != nil && p.Action == "ban" {
+			err = dapp.SetStatus(ctx, models.DappBanned)
+		} else {
+			err = report.Resolve(ctx)
+		}
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
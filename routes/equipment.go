@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/evolutionlandorg/evo-backend/models"
@@ -33,9 +32,9 @@ func equipmentList() gin.HandlerFunc {
 		}
 
 		opt := models.ListOpt{Page: p.Page, Row: p.Row, Order: p.Order, Chain: chain, OrderField: "rarity"}
-		opt.WhereQuery = []interface{}{fmt.Sprintf("chain = '%s'", chain)}
+		opt.WhereQuery = util.Wheres{util.Eq("chain", chain)}
 		if p.Object != "" {
-			opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("object = '%s'", p.Object))
+			opt.WhereQuery = append(opt.WhereQuery, util.Eq("object", p.Object))
 		}
 		if memberInfo := models.AuthOwner(c, true); memberInfo != nil {
 			wallet := memberInfo.GetUseAddress(chain)
@@ -43,7 +42,7 @@ func equipmentList() gin.HandlerFunc {
 				getReturnDataByError(c, 10035)
 				return
 			}
-			opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("owner = '%s' or origin_owner = '%s'", wallet, wallet))
+			opt.WhereQuery = append(opt.WhereQuery, util.Or(util.Eq("owner", wallet), util.Eq("origin_owner", wallet)))
 		}
 
 		list, count := models.EquipmentList(c, opt)
@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// ApiV1Prefix versioned api, errors respond with their http status and ErrorEnvelope.
+// /api keeps responding errors with 200 and GinJSON
+const ApiV1Prefix = "/api/v1"
+
+const errorEnvelopeKey = "ErrorEnvelope"
+
+type ErrorEnvelope struct {
+	Code  int `json:"code"`
+	Error struct {
+		Key     string `json:"key"`
+		Message string `json:"message"`
+		Detail  string `json:"detail,omitempty"`
+	} `json:"error"`
+	RequestId string `json:"request_id"`
+}
+
+var errorLanguages = language.NewMatcher([]language.Tag{language.English, language.Chinese})
+
+func errorEnvelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(errorEnvelopeKey, true)
+		c.Next()
+	}
+}
+
+// errorLang en or zh matched from Accept-Language
+func errorLang(c *gin.Context) string {
+	tags, _, _ := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if _, index, _ := errorLanguages.Match(tags...); index == 1 {
+		return "zh"
+	}
+	return "en"
+}
+
+func abortWithErrorEnvelope(c *gin.Context, code int, msg ...string) {
+	qyErr := util.QYError{Code: code}
+	var e ErrorEnvelope
+	e.Code = code
+	e.Error.Key = qyErr.Key()
+	e.Error.Message = qyErr.Message(errorLang(c))
+	e.Error.Detail = strings.Join(msg, ",")
+	e.RequestId = c.GetString("RequestId")
+	c.AbortWithStatusJSON(qyErr.Status(), e)
+}
+
+// NoRoute not found of ApiV1Prefix responds 404 with ErrorEnvelope, others keep the legacy response
+func NoRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, ApiV1Prefix+"/") {
+			abortWithErrorEnvelope(c, 10404, c.Request.URL.Path)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code": 10000,
+			"msg":  "not found",
+		})
+	}
+}
+
+// modelErrorCodes code of errors returned by models, matched with errors.Is
+var modelErrorCodes = []struct {
+	err  error
+	code int
+}{
+	// session of auth login
+	{models.ErrSessionDisabled, 10009},
+	{models.ErrChallengeExpired, 10013},
+	{models.ErrSignature, 10002},
+	// member
+	{models.ErrEmailExist, 10004},
+	{models.ErrNameExist, 10005},
+	{models.ErrWalletExist, 10006},
+	{models.ErrEmailBound, 10033},
+	{models.ErrItCode, 10001},
+	// building
+	{models.ErrDrawing, 30004},
+	{models.ErrBuildingLand, 30005},
+	{models.ErrBuildingRange, 30006},
+	{models.ErrBuildingApostle, 30007},
+	{models.ErrBuildingStatus, 30008},
+	{models.ErrMinerLimit, 30009},
+	{models.ErrAdminLimit, 30010},
+	{models.ErrHiringClosed, 30011},
+	{models.ErrBuildingOwner, 30012},
+	// dapp
+	{models.ErrDappExist, 40001},
+	{models.ErrDappStatus, 40002},
+	{models.ErrDappNotPublish, 40003},
+	{models.ErrDappLandOwner, 40004},
+}
+
+// modelError write the code of err returned by models, 10000 if it is unknown
+func modelError(c *gin.Context, err error) {
+	for _, e := range modelErrorCodes {
+		if errors.Is(err, e.err) {
+			getReturnDataByError(c, e.code, err.Error())
+			return
+		}
+	}
+	getReturnDataByError(c, 10000, err.Error())
+}
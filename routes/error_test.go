@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorEnvelope(t *testing.T) {
+	handle := func(c *gin.Context) {
+		c.Set("RequestId", "request-1")
+		modelError(c, fmt.Errorf("join: %w", models.ErrHiringClosed))
+	}
+	r := gin.New()
+	r.GET("/api/building", handle)
+	r.GET(ApiV1Prefix+"/building", errorEnvelope(), handle)
+	r.NoRoute(NoRoute())
+	get := func(uri, lang string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, uri, nil)
+		req.Header.Set("Accept-Language", lang)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// legacy api keeps 200 with the code
+	w := get("/api/building", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"code":30011,"detail":"building not hiring","message":"join: building not hiring"}`, w.Body.String())
+
+	w = get(ApiV1Prefix+"/building", "zh-CN,zh;q=0.9")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var e ErrorEnvelope
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, 30011, e.Code)
+	assert.Equal(t, "hiring_closed", e.Error.Key)
+	assert.Equal(t, "建筑未在招聘", e.Error.Message)
+	assert.Equal(t, "join: building not hiring", e.Error.Detail)
+	assert.Equal(t, "request-1", e.RequestId)
+
+	w = get(ApiV1Prefix+"/none", "en")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, "not_found", e.Error.Key)
+	assert.Equal(t, "record not found", e.Error.Message)
+	assert.Equal(t, http.StatusOK, get("/api/none", "en").Code)
+}
+
+func TestModelError(t *testing.T) {
+	for err, code := range map[error]int{
+		models.ErrSessionDisabled:                10009,
+		models.ErrChallengeExpired:               10013,
+		models.ErrSignature:                      10002,
+		models.ErrEmailExist:                     10004,
+		models.ErrWalletExist:                    10006,
+		models.ErrEmailBound:                     10033,
+		models.ErrItCode:                         10001,
+		models.ErrBuildingOwner:                  30012,
+		models.ErrDappLandOwner:                  40004,
+		fmt.Errorf("x: %w", models.ErrNameExist): 10005,
+		errors.New("db error"):                   10000,
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		modelError(c, err)
+		var res struct {
+			Code int `json:"code"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, code, res.Code, err.Error())
+	}
+}
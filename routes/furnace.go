@@ -1,9 +1,7 @@
 package routes
 
 import (
-	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/services/storage"
@@ -51,15 +49,15 @@ func furnaceProps() gin.HandlerFunc {
 		opt := models.ListOpt{Page: p.Page, Row: p.Row, Order: p.Order, Filter: p.Filter, Chain: chain}
 		switch p.Filter {
 		case "fresh":
-			opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("owner = '%s'", wallet))
+			opt.WhereQuery = append(opt.WhereQuery, util.Eq("owner", wallet))
 		case "working":
-			opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("token_id in ('%s')", strings.Join(memberInfo.EqDrill(util.GetContextByGin(c), wallet), "','")))
+			opt.WhereQuery = append(opt.WhereQuery, util.In("token_id", memberInfo.EqDrill(util.GetContextByGin(c), wallet)))
 		default:
-			opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("owner = '%s' or token_id in ('%s')", wallet, strings.Join(memberInfo.EqDrill(util.GetContextByGin(c), wallet), "','")))
+			opt.WhereQuery = append(opt.WhereQuery, util.Or(util.Eq("owner", wallet), util.In("token_id", memberInfo.EqDrill(util.GetContextByGin(c), wallet))))
 		}
-		opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("chain = '%s'", chain))
+		opt.WhereQuery = append(opt.WhereQuery, util.Eq("chain", chain))
 		if p.FormulaId > 0 {
-			opt.WhereQuery = append(opt.WhereQuery, fmt.Sprintf("formula_id = '%d'", p.FormulaId))
+			opt.WhereQuery = append(opt.WhereQuery, util.Eq("formula_id", p.FormulaId))
 			if p.FormulaId != 256 {
 				opt.Display = "ignore" // ignore dego
 			}
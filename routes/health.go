@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/daemons"
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz liveness probe, the process is serving
+func Healthz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// Readyz readiness probe, 503 until mysql and redis respond
+func Readyz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(util.GetContextByGin(c), 2*time.Second)
+		defer cancel()
+		database, redis := models.PingDatabase(ctx), models.PingRedis(ctx)
+		status := http.StatusOK
+		if !database.Ok || !redis.Ok {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"database": database, "redis": redis})
+	}
+}
+
+// @Summary	Service status, mysql and redis latency, scanner head against WipeBlock cursor, worker queue depth and last success of daemons
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Success	200				{object}	routes.GinJSON{data=models.ServiceStatus}
+// @Router		/admin/status [get]
+func serviceStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := models.GetServiceStatus(util.GetContextByGin(c), daemons.Names())
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": status})
+	}
+}
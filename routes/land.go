@@ -1,7 +1,6 @@
 package routes
 
 import (
-	"fmt"
 	"github.com/evolutionlandorg/evo-backend/models"
 	"github.com/evolutionlandorg/evo-backend/util"
 	"net/http"
@@ -46,7 +45,7 @@ func landListHandle() gin.HandlerFunc {
 		if searchId != "" {
 			findNum := regexp.MustCompile("[0-9]+").FindAllString(searchId, -1)
 			if len(findNum) > 0 && util.StringToInt(findNum[0]) > 0 {
-				query.WhereInterface = append(query.WhereInterface, fmt.Sprintf("token_id = '%s'", models.GenerateLandTokenId(chain, util.StringToInt(findNum[0]))))
+				query.WhereInterface = append(query.WhereInterface, util.Eq("lands.token_id", models.GenerateLandTokenId(chain, util.StringToInt(findNum[0]))))
 			}
 		}
 
@@ -68,7 +67,7 @@ func landListHandle() gin.HandlerFunc {
 				query.PriceMap = priceMap
 				query.MyLastBid = myLastBid
 			case "onsale": // 出售中地块
-				tokenIds, hasBidArr, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, fmt.Sprintf("seller = '%s' and status = '%s'", wallet, "going"))
+				tokenIds, hasBidArr, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, util.Eq("seller", wallet), util.Eq("status", models.AuctionGoing))
 				query.TokenId = tokenIds
 				query.PriceMap = priceMap
 				query.HasBid = hasBidArr
@@ -76,13 +75,13 @@ func landListHandle() gin.HandlerFunc {
 			case "my":
 				query.WhereQuery.Owner = wallet
 			case "other":
-				query.WhereInterface = append(query.WhereInterface, fmt.Sprintf("owner != '%s'", wallet))
+				query.WhereInterface = append(query.WhereInterface, util.Ne("lands.owner", wallet))
 			case "fresh":
 				query.WhereQuery.Owner = wallet
 				query.WhereQuery.Status = "fresh"
 			case "mine":
 				query.WhereQuery.Owner = wallet
-				tokenIds, hasBidArr, _, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, fmt.Sprintf("seller = '%s' and status = '%s'", wallet, "going"))
+				tokenIds, hasBidArr, _, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, util.Eq("seller", wallet), util.Eq("status", models.AuctionGoing))
 				query.TokenId = tokenIds
 				query.HasBid = hasBidArr
 				query.TokenMap = tokenMap
@@ -94,9 +93,8 @@ func landListHandle() gin.HandlerFunc {
 					c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": list, "count": 0})
 					return
 				}
-				query.WhereInterface = append(query.WhereInterface, fmt.Sprintf("id in (%s)", util.StringsJoinQuot(digLandIds)))
-				query.WhereInterface = append(query.WhereInterface, fmt.Sprintf("token_id not in (%s)",
-					util.StringsJoinQuot(models.FullyLoadedLandId(util.GetContextByGin(c)))))
+				query.WhereInterface = append(query.WhereInterface, util.In("lands.id", digLandIds),
+					util.NotIn("lands.token_id", models.FullyLoadedLandId(util.GetContextByGin(c))))
 			}
 			query.PendingTrans = models.CurrentPendingLand(util.GetContextByGin(c), wallet)
 			var result *[]models.LandJson
@@ -113,7 +111,7 @@ func landListHandle() gin.HandlerFunc {
 
 		switch filter {
 		case "onsale":
-			tokenIds, hasBidArr, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, fmt.Sprintf("status = '%s'", "going"))
+			tokenIds, hasBidArr, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, util.Eq("status", models.AuctionGoing))
 			query.TokenId = tokenIds
 			query.PriceMap = priceMap
 			query.TokenMap = tokenMap
@@ -121,7 +119,7 @@ func landListHandle() gin.HandlerFunc {
 			list, count = query.LandList(util.GetContextByGin(c))
 		case "genesis":
 			tokenIds, hasBidArr, priceMap, startAtMap, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district,
-				fmt.Sprintf("seller = '%s' and status = '%s'", util.GetContractAddress("genesisHolder", chain), "going"),
+				util.Eq("seller", util.GetContractAddress("genesisHolder", chain)), util.Eq("status", models.AuctionGoing),
 			)
 			query.TokenId = tokenIds
 			query.HasBid = hasBidArr
@@ -131,7 +129,7 @@ func landListHandle() gin.HandlerFunc {
 			list, count = query.LandList(util.GetContextByGin(c))
 		case "secondhand":
 			tokenIds, hasBidArr, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district,
-				fmt.Sprintf("seller != '%s' and status = '%s'", util.GetContractAddress("genesisHolder", chain), "going"),
+				util.Ne("seller", util.GetContractAddress("genesisHolder", chain)), util.Eq("status", models.AuctionGoing),
 			)
 			query.TokenId = tokenIds
 			query.HasBid = hasBidArr
@@ -139,10 +137,10 @@ func landListHandle() gin.HandlerFunc {
 			query.PriceMap = priceMap
 			list, count = query.LandList(util.GetContextByGin(c))
 		case "plo":
-			query.WhereInterface = append(query.WhereInterface, fmt.Sprintf("token_index in (%s)", util.StringsJoinQuot(util.Evo.GRLandId[models.GetChainByDistrict(district)])))
+			query.WhereInterface = append(query.WhereInterface, util.In("lands.token_index", util.Evo.GRLandId[models.GetChainByDistrict(district)]))
 			list, count = query.LandList(util.GetContextByGin(c))
 		default:
-			_, _, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, fmt.Sprintf("status = '%s'", "going"))
+			_, _, priceMap, _, tokenMap := models.OnsellLandList(util.GetContextByGin(c), district, util.Eq("status", models.AuctionGoing))
 			query.PriceMap = priceMap
 			query.TokenMap = tokenMap
 			list, count = query.AllLands(util.GetContextByGin(c))
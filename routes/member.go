@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary	Register wallet as member with signature of challenge, then login
+// @Tags		member
+// @Param		EVO-NETWORK	header		string	false	"chain"
+// @Param		wallet		formData	string	true	"wallet"
+// @Param		sign		formData	string	true	"signature of challenge message"
+// @Param		challenge	formData	string	true	"challenge of /auth/challenge"
+// @Param		name		formData	string	true	"name"
+// @Param		itCode		formData	string	false	"invitation code of referrer"
+// @Success	200			{object}	routes.GinJSON{data=models.Session}
+// @Router		/member/register [post]
+func memberRegister() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := new(models.ValidateReg)
+		if err := c.ShouldBind(v); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		v.Chain = c.GetString("EvoNetwork")
+		if !util.VerifyAddress(v.Wallet, v.Chain) {
+			getReturnDataByError(c, 10003)
+			return
+		}
+		s, err := v.Register(util.GetContextByGin(c), c.ClientIP(), c.Request.UserAgent())
+		if err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": s})
+	}
+}
+
+// @Summary	Get profile of login member
+// @Tags		member
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Success	200				{object}	routes.GinJSON{data=models.MemberJson}
+// @Router		/member/profile [get]
+func memberProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": member.AsJson(util.GetContextByGin(c), c.GetString("EvoNetwork"))})
+	}
+}
+
+// @Summary	Update profile of login member, empty fields are unchanged
+// @Tags		member
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		name			formData	string	false	"name"
+// @Param		region			formData	string	false	"region"
+// @Param		reward_chain	formData	string	false	"reward chain"
+// @Success	200				{object}	routes.GinJSON{data=models.MemberJson}
+// @Router		/member/profile [post]
+func memberUpdateProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		p := new(models.MemberProfile)
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.RewardChain != "" && !util.StringInSlice(p.RewardChain, util.Evo.Networks) {
+			getReturnDataByError(c, 10001, "unknown reward_chain")
+			return
+		}
+		ctx := util.GetContextByGin(c)
+		if err := member.UpdateProfile(ctx, p); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": member.AsJson(ctx, c.GetString("EvoNetwork"))})
+	}
+}
+
+// @Summary	Bind email to login member
+// @Tags		member
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		email			formData	string	true	"email"
+// @Success	200				{object}	routes.GinJSON{data=nil}
+// @Router		/member/email [post]
+func memberBindEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		p := new(struct {
+			Email string `form:"email" json:"email" binding:"required,email"`
+		})
+		if err := c.ShouldBind(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if err := member.BindEmail(util.GetContextByGin(c), p.Email); err != nil {
+			modelError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success"})
+	}
+}
+
+// @Summary	List login history of login member
+// @Tags		member
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Success	200				{object}	routes.GinJSON{data=[]models.MemberLoginInfoJson}
+// @Router		/member/login_history [get]
+func memberLoginHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		p := new(struct {
+			Page int `form:"page"`
+			Row  int `form:"row"`
+		})
+		if err := c.ShouldBindQuery(p); err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		if p.Row <= 0 || p.Row > 100 {
+			p.Row = 20
+		}
+		list, count := member.LoginHistory(util.GetContextByGin(c), p.Page, p.Row)
+		data := make([]models.MemberLoginInfoJson, 0, len(list))
+		for _, info := range list {
+			data = append(data, info.AsJson())
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": data, "count": count})
+	}
+}
@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/daemons"
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+const metricsRefreshInterval = 15 * time.Second
+
+var metricsRefresh struct {
+	sync.Mutex
+	at time.Time
+}
+
+// refreshStatusMetrics set gauges of queues, scanners and daemons from the service status, at most once per
+// metricsRefreshInterval since it calls rpc of every chain. They are shared state, so any instance can be scraped
+func refreshStatusMetrics(c *gin.Context) {
+	metricsRefresh.Lock()
+	defer metricsRefresh.Unlock()
+	if time.Since(metricsRefresh.at) < metricsRefreshInterval {
+		return
+	}
+	metricsRefresh.at = time.Now()
+	status := models.GetServiceStatus(util.GetContextByGin(c), daemons.Names())
+	for _, q := range status.Queues {
+		if q.Error == "" {
+			metrics.QueueDepth.WithLabelValues(q.Queue).Set(float64(q.Depth))
+		}
+	}
+	for _, s := range status.Scanners {
+		metrics.ScannerCursor.WithLabelValues(s.Chain).Set(float64(s.Cursor))
+		if s.Error == "" {
+			metrics.ScannerHead.WithLabelValues(s.Chain).Set(float64(s.Head))
+			metrics.ScannerLag.WithLabelValues(s.Chain).Set(float64(s.Behind))
+		}
+	}
+	for name, at := range status.Daemons {
+		metrics.DaemonLastSuccess.WithLabelValues(name).Set(float64(at))
+	}
+}
+
+// Metrics prometheus metrics, no datadog agent is needed
+func Metrics() gin.HandlerFunc {
+	handler := metrics.Handler()
+	return func(c *gin.Context) {
+		refreshStatusMetrics(c)
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
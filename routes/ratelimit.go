@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"crypto/md5"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/spf13/cast"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "EVO-API-KEY"
+
+var (
+	// rateLimitApiKeys api keys of consumers and the scale of their limits, e.g. "key1:10,key2:5".
+	// Unknown api keys are limited by wallet or ip, so rotating keys gets no new bucket
+	rateLimitApiKeys = parseRateLimitApiKeys(util.GetEnv("RATE_LIMIT_API_KEYS", ""))
+	// rateLimitAllowlist ips, cidrs or api keys of trusted internal consumers which are never limited,
+	// e.g. "10.0.0.0/8,127.0.0.1,key3"
+	rateLimitAllowNets, rateLimitAllowKeys = parseRateLimitAllowlist(util.GetEnv("RATE_LIMIT_ALLOWLIST", ""))
+)
+
+func parseRateLimitApiKeys(s string) map[string]float64 {
+	keys := make(map[string]float64)
+	for _, item := range util.RemoveEmptyStrings(strings.Split(s, ",")) {
+		key, scale, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if keys[key] = cast.ToFloat64(scale); keys[key] <= 0 {
+			keys[key] = 1
+		}
+	}
+	return keys
+}
+
+func parseRateLimitAllowlist(s string) (nets []*net.IPNet, keys []string) {
+	for _, item := range util.RemoveEmptyStrings(strings.Split(s, ",")) {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") && net.ParseIP(item) != nil {
+			item += util.TrueOrElse(strings.Contains(item, ":"), "/128", "/32")
+		}
+		if _, ipNet, err := net.ParseCIDR(item); err == nil {
+			nets = append(nets, ipNet)
+			continue
+		}
+		keys = append(keys, item)
+	}
+	return
+}
+
+// rateLimitClient bucket of the client and the scale of its limits, a registered api key, the session wallet or ip.
+// It returns false for allowlisted clients
+func rateLimitClient(c *gin.Context) (client string, scale float64, limited bool) {
+	apiKey := c.GetHeader(apiKeyHeader)
+	if apiKey != "" && util.StringInSlice(apiKey, rateLimitAllowKeys) {
+		return "", 0, false
+	}
+	ip := net.ParseIP(c.ClientIP())
+	for _, ipNet := range rateLimitAllowNets {
+		if ip != nil && ipNet.Contains(ip) {
+			return "", 0, false
+		}
+	}
+	if scale, ok := rateLimitApiKeys[apiKey]; ok && apiKey != "" {
+		return fmt.Sprintf("key:%x", md5.Sum([]byte(apiKey))), scale, true
+	}
+	if wallet := c.GetString(models.SessionWalletKey); wallet != "" {
+		return "wallet:" + strings.ToLower(wallet), 1, true
+	}
+	return "ip:" + c.ClientIP(), 1, true
+}
+
+// rateLimit limit requests of each client to name with a token bucket refilled by rate per second up to burst,
+// shared by all instances. RATE_LIMIT_<NAME>="rate,burst" overrides the limit, "0" disables it.
+// Limited requests get 10044 with Retry-After
+func rateLimit(name string, rate float64, burst int) gin.HandlerFunc {
+	env := "RATE_LIMIT_" + strings.ToUpper(strings.NewReplacer("/", "_", "-", "_").Replace(name))
+	if override := util.GetEnv(env, ""); override != "" {
+		r, b, _ := strings.Cut(override, ",")
+		rate, burst = cast.ToFloat64(r), util.StringToInt(b)
+	}
+	if !util.IsProduction() || rate <= 0 || burst <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		client, scale, limited := rateLimitClient(c)
+		if !limited {
+			c.Next()
+			return
+		}
+		key := fmt.Sprintf("RateLimit:%s:%s", name, client)
+		taken, wait, err := util.TakeToken(util.GetContextByGin(c), key, rate*scale, int(math.Ceil(float64(burst)*scale)))
+		if err != nil {
+			// fail open rather than rejecting every request when redis is down
+			log.Warn("rate limit %s error: %s", key, err)
+			c.Next()
+			return
+		}
+		if !taken {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			getReturnDataByError(c, 10044)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedis(t *testing.T) *miniredis.Miniredis {
+	log.InitLog(log.Options{Level: log.StrLevel2zAPlEVEL("ERROR")})
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_HOST", mr.Host())
+	t.Setenv("REDIS_PORT", mr.Port())
+	assert.NoError(t, util.InitRedis())
+	return mr
+}
+
+// inProduction run the test as production, where rate limit and response cache are enabled
+func inProduction(t *testing.T) {
+	environment := util.Environment
+	util.Environment = util.Production
+	t.Cleanup(func() { util.Environment = environment })
+}
+
+func TestParseRateLimitApiKeys(t *testing.T) {
+	assert.Equal(t, map[string]float64{"key1": 10, "key2": 1, "key3": 1}, parseRateLimitApiKeys("key1:10, key2,key3:-1,"))
+	assert.Empty(t, parseRateLimitApiKeys(""))
+}
+
+func TestParseRateLimitAllowlist(t *testing.T) {
+	nets, keys := parseRateLimitAllowlist("10.0.0.0/8, 127.0.0.1,::1,key3")
+	if assert.Len(t, nets, 3) {
+		assert.Equal(t, "10.0.0.0/8", nets[0].String())
+		assert.Equal(t, "127.0.0.1/32", nets[1].String())
+		assert.Equal(t, "::1/128", nets[2].String())
+	}
+	assert.Equal(t, []string{"key3"}, keys)
+}
+
+func TestRateLimitClient(t *testing.T) {
+	apiKeys, allowNets, allowKeys := rateLimitApiKeys, rateLimitAllowNets, rateLimitAllowKeys
+	defer func() { rateLimitApiKeys, rateLimitAllowNets, rateLimitAllowKeys = apiKeys, allowNets, allowKeys }()
+	rateLimitApiKeys = parseRateLimitApiKeys("key1:10")
+	rateLimitAllowNets, rateLimitAllowKeys = parseRateLimitAllowlist("10.0.0.0/8,key3")
+
+	newContext := func(ip, apiKey, wallet string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/land/list", nil)
+		c.Request.RemoteAddr = ip + ":12345"
+		if apiKey != "" {
+			c.Request.Header.Set(apiKeyHeader, apiKey)
+		}
+		if wallet != "" {
+			c.Set(models.SessionWalletKey, wallet)
+		}
+		return c
+	}
+	for _, tc := range []struct {
+		name    string
+		c       *gin.Context
+		client  string
+		scale   float64
+		limited bool
+	}{
+		{"allowlisted ip", newContext("10.1.2.3", "", "0xABC"), "", 0, false},
+		{"allowlisted key", newContext("1.2.3.4", "key3", ""), "", 0, false},
+		{"registered key", newContext("1.2.3.4", "key1", "0xABC"), fmt.Sprintf("key:%x", md5.Sum([]byte("key1"))), 10, true},
+		{"session wallet", newContext("1.2.3.4", "", "0xABC"), "wallet:0xabc", 1, true},
+		{"unknown key", newContext("1.2.3.4", "key2", ""), "ip:1.2.3.4", 1, true},
+		{"ip", newContext("1.2.3.4", "", ""), "ip:1.2.3.4", 1, true},
+	} {
+		client, scale, limited := rateLimitClient(tc.c)
+		assert.Equal(t, tc.client, client, tc.name)
+		assert.Equal(t, tc.scale, scale, tc.name)
+		assert.Equal(t, tc.limited, limited, tc.name)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	newTestRedis(t)
+	inProduction(t)
+	t.Setenv("RATE_LIMIT_TEST_ROUTE", "0.001,2")
+	r := gin.New()
+	r.GET("/test", rateLimit("test/route", 10, 100), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	get := func(ip string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = ip + ":12345"
+		r.ServeHTTP(w, req)
+		return w
+	}
+	// the env overrides the limit to a burst of 2
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "ok", get("1.2.3.4").Body.String())
+	}
+	w := get("1.2.3.4")
+	assert.Contains(t, w.Body.String(), `"code":10044`)
+	assert.Equal(t, "1000", w.Header().Get("Retry-After"))
+	assert.Equal(t, "ok", get("5.6.7.8").Body.String())
+}
@@ -1,8 +1,8 @@
 package routes
 
 import (
-	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"sync"
 
@@ -37,7 +37,7 @@ func Snapshot() func(c *gin.Context) {
 		}
 
 		if len(args.Options.Chain) <= 0 {
-			args.Options.Chain = append(args.Options.Chain, models.EthChain, models.CrabChain, models.HecoChain, models.PolygonChain, models.TronChain)
+			args.Options.Chain = append(args.Options.Chain, util.Evo.Networks...)
 		}
 
 		args.Network = util.GetNetworkNameById(args.Network)
@@ -54,43 +54,45 @@ func Snapshot() func(c *gin.Context) {
 			otherVote sync.Map
 		)
 		for _, chain := range args.Options.Chain {
+			var (
+				queries []storage.BalanceQuery
+				kinds   []string
+			)
 			for _, v := range args.Addresses {
 				if args.Options.Kton > 0 {
-					wg.Add(1)
-					go func(address, chain string) {
-						defer wg.Done()
-						sg := storage.New(chain)
-						_ = util.Try(func() error {
-							balance := util.BigToDecimal(sg.BalanceOf(address, util.GetContractAddress("kton", models.HecoChain)))
-							if balance.IsNegative() {
-								return errors.New("rpc error")
-							}
-
-							otherVote.Store(fmt.Sprintf("%s-kton", address), balance.IntPart())
-							return nil
-						}, 10)
-					}(v, chain)
+					queries = append(queries, storage.BalanceQuery{Address: v, Contract: util.GetContractAddress("kton", models.HecoChain)})
+					kinds = append(kinds, "kton")
 				}
 				if args.Options.Element > 0 {
-					wg.Add(1)
-					go func(address, chain string) {
-						defer wg.Done()
-						sg := storage.New(chain)
-						var balance decimal.Decimal
-						for _, v := range []string{"gold", "wood", "water", "fire", "soil"} {
-							_ = util.Try(func() error {
-								elementBalance := util.BigToDecimal(sg.BalanceOf(address, util.GetContractAddress(v, models.HecoChain)))
-								if elementBalance.IsNegative() {
-									return errors.New("rpc error")
-								}
-								balance = balance.Add(elementBalance)
-								return nil
-							}, 10)
-						}
-						otherVote.Store(fmt.Sprintf("%s-element", address), balance.IntPart())
-					}(v, chain)
+					for _, element := range []string{"gold", "wood", "water", "fire", "soil"} {
+						queries = append(queries, storage.BalanceQuery{Address: v, Contract: util.GetContractAddress(element, models.HecoChain)})
+						kinds = append(kinds, "element")
+					}
 				}
 			}
+			if len(queries) == 0 {
+				continue
+			}
+			wg.Add(1)
+			go func(chain string) {
+				defer wg.Done()
+				balances := snapshotBalances(storage.New(chain), queries)
+				elements := make(map[string]decimal.Decimal)
+				for i, q := range queries {
+					if balances[i] == nil || balances[i].Sign() < 0 {
+						continue
+					}
+					balance := util.BigToDecimal(balances[i])
+					if kinds[i] == "kton" {
+						otherVote.Store(fmt.Sprintf("%s-kton", q.Address), balance.IntPart())
+						continue
+					}
+					elements[q.Address] = elements[q.Address].Add(balance)
+				}
+				for address, balance := range elements {
+					otherVote.Store(fmt.Sprintf("%s-element", address), balance.IntPart())
+				}
+			}(chain)
 		}
 
 		result, err := models.GetSnapshotByAddress(util.GetContextByGin(c), args.Addresses, cast.ToInt(args.Snapshot), args.Options.Chain, args.Network)
@@ -129,3 +131,27 @@ func Snapshot() func(c *gin.Context) {
 		c.JSON(200, snapshots)
 	}
 }
+
+// snapshotBalances balances of queries in one batch, failed ones are retried up to 10 times
+func snapshotBalances(sg storage.IStorage, queries []storage.BalanceQuery) []*big.Int {
+	balances := sg.BatchBalanceOf(queries)
+	for try := 1; try < 10; try++ {
+		var failed []int
+		for i, balance := range balances {
+			if balance == nil || balance.Sign() < 0 {
+				failed = append(failed, i)
+			}
+		}
+		if len(failed) == 0 {
+			break
+		}
+		retry := make([]storage.BalanceQuery, len(failed))
+		for j, i := range failed {
+			retry[j] = queries[i]
+		}
+		for j, balance := range sg.BatchBalanceOf(retry) {
+			balances[failed[j]] = balance
+		}
+	}
+	return balances
+}
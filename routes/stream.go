@@ -0,0 +1,244 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/gorilla/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	streamMaxTopics  = 50
+	streamBufferSize = 64
+	streamPing       = 30 * time.Second
+)
+
+var streamTopicKinds = []string{"wallet", "token", "auction", "tx"}
+
+// streamHub fan out events of models.StreamChannel to clients of this instance by topic
+type streamHub struct {
+	once    sync.Once
+	mu      sync.RWMutex
+	clients map[*streamClient]struct{}
+}
+
+type streamClient struct {
+	mu     sync.RWMutex
+	topics map[string]bool
+	send   chan []byte
+}
+
+var hub = &streamHub{clients: make(map[*streamClient]struct{})}
+
+func (h *streamHub) run() {
+	h.once.Do(func() {
+		go func() {
+			for {
+				err := util.Subscribe(context.Background(), models.StreamChannel, h.dispatch)
+				log.Warn("stream subscribe %s error: %v, reconnecting", models.StreamChannel, err)
+				time.Sleep(3 * time.Second)
+			}
+		}()
+	})
+}
+
+func (h *streamHub) dispatch(data []byte) {
+	var e struct {
+		Topics []string `json:"topics"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if !client.match(e.Topics) {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default: // slow client misses events rather than blocking others
+		}
+	}
+}
+
+func (h *streamHub) register(topics []string) *streamClient {
+	h.run()
+	client := &streamClient{topics: make(map[string]bool), send: make(chan []byte, streamBufferSize)}
+	client.subscribe(topics)
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+	return client
+}
+
+func (h *streamHub) unregister(client *streamClient) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+}
+
+func (s *streamClient) match(topics []string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, topic := range topics {
+		if s.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *streamClient) subscribe(topics []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, topic := range topics {
+		if len(s.topics) >= streamMaxTopics {
+			return
+		}
+		s.topics[topic] = true
+	}
+}
+
+func (s *streamClient) unsubscribe(topics []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+}
+
+// parseStreamTopics normalize topics as models.ChangeTag, e.g. wallet:0x..., token:2a01..., auction:1, tx:0x..., broadcast
+func parseStreamTopics(raw []string) ([]string, error) {
+	var topics []string
+	for _, topic := range raw {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if topic == models.ChangeTag(models.StreamBroadcast) {
+			topics = append(topics, topic)
+			continue
+		}
+		kind, value, ok := strings.Cut(topic, ":")
+		if !ok || value == "" || !util.StringInSlice(kind, streamTopicKinds) {
+			return nil, fmt.Errorf("unknown topic %s", topic)
+		}
+		topics = append(topics, models.ChangeTag(kind, value))
+	}
+	if len(topics) == 0 || len(topics) > streamMaxTopics {
+		return nil, fmt.Errorf("subscribe 1 to %d topics", streamMaxTopics)
+	}
+	return topics, nil
+}
+
+var streamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// @Summary	Stream change events over WebSocket, send {"op":"subscribe|unsubscribe","topics":[...]} to change topics
+// @Tags		stream
+// @Param		topic	query	[]string	true	"wallet:{address}, token:{token id}, auction:{district}, tx:{hash} or broadcast"	collectionFormat(multi)
+// @Success	101		{object}	models.StreamEvent
+// @Router		/stream/ws [get]
+func streamWebSocket() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topics, err := parseStreamTopics(c.QueryArray("topic"))
+		if err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		conn, err := streamUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		client := hub.register(topics)
+		defer hub.unregister(client)
+
+		// the request context of a hijacked connection is never canceled, the reader closes done when the peer leaves
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				var msg struct {
+					Op     string   `json:"op"`
+					Topics []string `json:"topics"`
+				}
+				if err := conn.ReadJSON(&msg); err != nil {
+					return
+				}
+				topics, err := parseStreamTopics(msg.Topics)
+				if err != nil {
+					continue
+				}
+				switch msg.Op {
+				case "subscribe":
+					client.subscribe(topics)
+				case "unsubscribe":
+					client.unsubscribe(topics)
+				}
+			}
+		}()
+
+		ping := time.NewTicker(streamPing)
+		defer ping.Stop()
+		for {
+			select {
+			case data := <-client.send:
+				if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// @Summary	Stream change events as Server-Sent Events
+// @Tags		stream
+// @Param		topic	query		[]string	true	"wallet:{address}, token:{token id}, auction:{district}, tx:{hash} or broadcast"	collectionFormat(multi)
+// @Success	200		{object}	models.StreamEvent
+// @Router		/stream/sse [get]
+func streamSSE() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topics, err := parseStreamTopics(c.QueryArray("topic"))
+		if err != nil {
+			getReturnDataByError(c, 10001, err.Error())
+			return
+		}
+		client := hub.register(topics)
+		defer hub.unregister(client)
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		ping := time.NewTicker(streamPing)
+		defer ping.Stop()
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case data := <-client.send:
+				c.SSEvent("message", string(data))
+			case <-ping.C:
+				c.SSEvent("ping", time.Now().Unix())
+			case <-c.Request.Context().Done():
+				return false
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStreamTopics(t *testing.T) {
+	topics, err := parseStreamTopics([]string{" Wallet:0xABC", "token:2A01", "auction:1", "tx:0xF0", "BROADCAST"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"wallet:0xabc", "token:2a01", "auction:1", "tx:0xf0", "broadcast"}, topics)
+
+	for _, raw := range [][]string{nil, {"district:1"}, {"wallet:"}, {"wallet"}} {
+		_, err = parseStreamTopics(raw)
+		assert.Error(t, err, "%v", raw)
+	}
+	raw := make([]string, streamMaxTopics+1)
+	for i := range raw {
+		raw[i] = fmt.Sprintf("tx:%d", i)
+	}
+	_, err = parseStreamTopics(raw)
+	assert.Error(t, err)
+}
+
+func TestStreamClient(t *testing.T) {
+	client := &streamClient{topics: make(map[string]bool), send: make(chan []byte, 1)}
+	client.subscribe([]string{"wallet:0xabc", "auction:1"})
+	assert.True(t, client.match([]string{"token:2a01", "wallet:0xabc"}))
+	assert.False(t, client.match([]string{"wallet:0xdef", "auction:2"}))
+	client.unsubscribe([]string{"wallet:0xabc"})
+	assert.False(t, client.match([]string{"wallet:0xabc"}))
+
+	// topics over the limit are dropped
+	for i := 0; i < streamMaxTopics+5; i++ {
+		client.subscribe([]string{fmt.Sprintf("tx:%d", i)})
+	}
+	assert.Len(t, client.topics, streamMaxTopics)
+}
+
+func TestStreamHub(t *testing.T) {
+	newTestRedis(t)
+	h := &streamHub{clients: make(map[*streamClient]struct{})}
+	wallet := h.register([]string{"wallet:0xabc"})
+	auction := h.register([]string{"auction:1", "broadcast"})
+	defer h.unregister(wallet)
+	defer h.unregister(auction)
+	events := func(client *streamClient) (types []string) {
+		for {
+			select {
+			case data := <-client.send:
+				var e models.StreamEvent
+				assert.NoError(t, json.Unmarshal(data, &e))
+				types = append(types, e.Type)
+			case <-time.After(100 * time.Millisecond):
+				return
+			}
+		}
+	}
+	ctx := context.Background()
+	// wait for the subscription of the hub
+	assert.Eventually(t, func() bool {
+		models.PublishStreamEvent(ctx, &models.StreamEvent{Type: models.StreamBroadcast})
+		return len(events(auction)) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	models.PublishStreamEvent(ctx, &models.StreamEvent{Type: "land", TokenId: "2a01", Wallets: []string{"0xABC"}})
+	models.PublishStreamEvent(ctx, &models.StreamEvent{Type: models.StreamAuction, District: 1, TokenId: "2a02"})
+	models.PublishStreamEvent(ctx, &models.StreamEvent{Type: models.StreamAuction, District: 2, TokenId: "2a03"})
+	models.PublishStreamEvent(ctx, &models.StreamEvent{Type: models.StreamTxStatus, Tx: "0xf0", Status: models.StreamTxSuccess})
+	assert.Equal(t, []string{"land"}, events(wallet))
+	assert.Equal(t, []string{models.StreamAuction}, events(auction))
+
+	// slow client misses events rather than blocking the others
+	for i := 0; i < streamBufferSize+10; i++ {
+		h.dispatch([]byte(`{"topics":["wallet:0xabc","auction:1"]}`))
+	}
+	assert.Len(t, wallet.send, streamBufferSize)
+	assert.Len(t, auction.send, streamBufferSize)
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	newTestRedis(t)
+	r := gin.New()
+	r.GET("/stream/ws", streamWebSocket())
+	server := httptest.NewServer(r)
+	defer server.Close()
+	clients := func() int {
+		hub.mu.RLock()
+		defer hub.mu.RUnlock()
+		return len(hub.clients)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/stream/ws?topic=broadcast", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Eventually(t, func() bool { return clients() == 1 }, time.Second, 10*time.Millisecond)
+
+	// the handler returns once the peer leaves, though the request context of the hijacked connection lives on
+	_ = conn.Close()
+	assert.Eventually(t, func() bool { return clients() == 0 }, time.Second, 10*time.Millisecond)
+}
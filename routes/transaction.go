@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/models"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type transactionHistoryParams struct {
+	Action    string `form:"action"`
+	Currency  string `form:"currency"`
+	Chain     string `form:"chain"`
+	TokenId   string `form:"token_id"`
+	StartTime int    `form:"start_time"`
+	EndTime   int    `form:"end_time"`
+	Page      int    `form:"page"`
+	Row       int    `form:"row"`
+	Format    string `form:"format" binding:"omitempty,oneof=json csv jsonl"`
+}
+
+// @Summary	List transaction history of login wallet, format csv or jsonl exports all matched histories
+// @Tags		transaction
+// @Param		Authorization	header		string	true	"Bearer {token}"
+// @Param		EVO-NETWORK		header		string	false	"chain"
+// @Param		action			query		string	false	"action"
+// @Param		currency		query		string	false	"currency"
+// @Param		chain			query		string	false	"chain"
+// @Param		token_id		query		string	false	"token id"
+// @Param		start_time		query		int		false	"start of add_time, unix seconds"
+// @Param		end_time		query		int		false	"end of add_time, unix seconds"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Param		format			query		string	false	"format"	Enums(json,csv,jsonl)
+// @Success	200				{object}	routes.GinJSON{data=[]models.TransactionHistoryJson}
+// @Router		/transaction/history [get]
+func transactionHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := models.AuthOwner(c, true)
+		if member == nil {
+			getReturnDataByError(c, 99999)
+			return
+		}
+		wallet := member.GetUseAddress(c.GetString("EvoNetwork"))
+		if wallet == "" {
+			getReturnDataByError(c, 10035)
+			return
+		}
+		queryTransactionHistory(c, wallet)
+	}
+}
+
+// @Summary	List transaction history of any wallet, format csv or jsonl exports all matched histories
+// @Tags		admin
+// @Param		EVO-ADMIN-TOKEN	header		string	true	"admin token"
+// @Param		address			query		string	true	"wallet"
+// @Param		action			query		string	false	"action"
+// @Param		currency		query		string	false	"currency"
+// @Param		chain			query		string	false	"chain"
+// @Param		token_id		query		string	false	"token id"
+// @Param		start_time		query		int		false	"start of add_time, unix seconds"
+// @Param		end_time		query		int		false	"end of add_time, unix seconds"
+// @Param		page			query		int		false	"page"
+// @Param		row				query		int		false	"row"
+// @Param		format			query		string	false	"format"	Enums(json,csv,jsonl)
+// @Success	200				{object}	routes.GinJSON{data=[]models.TransactionHistoryJson}
+// @Router		/admin/transaction_history [get]
+func adminTransactionHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		wallet := c.Query("address")
+		if wallet == "" {
+			getReturnDataByError(c, 10003)
+			return
+		}
+		queryTransactionHistory(c, wallet)
+	}
+}
+
+func queryTransactionHistory(c *gin.Context, wallet string) {
+	p := new(transactionHistoryParams)
+	if err := c.ShouldBindQuery(p); err != nil {
+		getReturnDataByError(c, 10001, err.Error())
+		return
+	}
+	if p.Row <= 0 || p.Row > 100 {
+		p.Row = 20
+	}
+	query := models.EthTransactionQuery{StartTime: p.StartTime, EndTime: p.EndTime, Page: p.Page, Row: p.Row}
+	query.WhereQuery.BalanceAddress = wallet
+	query.WhereQuery.Action = p.Action
+	query.WhereQuery.Currency = p.Currency
+	query.WhereQuery.Chain = p.Chain
+	query.WhereQuery.TokenId = p.TokenId
+
+	switch p.Format {
+	case "csv", "jsonl":
+		exportTransactionHistory(c, &query, p.Format)
+	default:
+		list, count := query.GetTransactionHistory(util.GetContextByGin(c))
+		if list == nil {
+			list = &[]models.TransactionHistoryJson{}
+		}
+		c.JSON(http.StatusOK, gin.H{"code": 0, "detail": "success", "data": list, "count": count})
+	}
+}
+
+var transactionHistoryCsvHeader = []string{"tx", "chain", "action", "currency", "balance_change", "balance_address", "token_id", "coordinate", "add_time", "time", "extra"}
+
+// exportTransactionHistory stream all histories of query as attachment, error after the first row can only be logged
+func exportTransactionHistory(c *gin.Context, query *models.EthTransactionQuery, format string) {
+	filename := fmt.Sprintf("transaction_history_%s.%s", time.Now().UTC().Format("20060102150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Status(http.StatusOK)
+
+	var (
+		write func(th *models.TransactionHistoryJson) error
+		flush func()
+	)
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write(transactionHistoryCsvHeader)
+		write = func(th *models.TransactionHistoryJson) error {
+			return w.Write([]string{
+				th.Tx, th.Chain, th.Action, th.Currency, th.BalanceChange.String(), th.BalanceAddress, th.TokenId, th.Coordinate,
+				strconv.Itoa(th.AddTime), time.Unix(int64(th.AddTime), 0).UTC().Format(time.RFC3339), th.Extra,
+			})
+		}
+		flush = w.Flush
+	default:
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+		enc := json.NewEncoder(c.Writer)
+		write = func(th *models.TransactionHistoryJson) error { return enc.Encode(th) }
+		flush = func() {}
+	}
+
+	var n int
+	err := query.EachTransactionHistory(util.GetContextByGin(c), func(th *models.TransactionHistoryJson) error {
+		if err := write(th); err != nil {
+			return err
+		}
+		if n++; n%1000 == 0 {
+			flush()
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	flush()
+	if err != nil {
+		log.Error("export transaction history of %s fail after %d rows: %s", query.WhereQuery.BalanceAddress, n, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/evolutionlandorg/evo-backend/pkg/github.com/regcostajr/go-web3/complex/types"
 	"github.com/evolutionlandorg/evo-backend/pkg/github.com/regcostajr/go-web3/dto"
 	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/roundrobin"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/shopspring/decimal"
@@ -23,7 +24,7 @@ type ethCall struct {
 
 func NewEth() *ethCall {
 	c := Call{Network: "Eth"}
-	c.RpcClient = util.Rb
+	c.RpcClient = rpcClient(Ethereum, func() *roundrobin.Balancer { return util.Rb })
 	return &ethCall{Call: c}
 }
 
@@ -163,10 +164,16 @@ func (c ethCall) BlockNumber() uint64 {
 }
 
 func (c ethCall) FilterTrans(blockNum uint64, filter []string) (txn []string, contracts []string, timestamp uint64, transactionTo []string) {
+	_, txn, contracts, timestamp, transactionTo = c.FilterBlock(blockNum, filter)
+	return
+}
+
+func (c ethCall) FilterBlock(blockNum uint64, filter []string) (hash string, txn []string, contracts []string, timestamp uint64, transactionTo []string) {
 	block, err := c.BlockInfo(blockNum)
 	if err != nil {
 		return
 	}
+	hash = block.Hash
 
 	for _, transaction := range block.Transactions {
 		to := strings.ToLower(transaction.To)
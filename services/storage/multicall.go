@@ -0,0 +1,320 @@
+package storage
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/evolutionlandorg/evo-backend/pkg/github.com/regcostajr/go-web3/dto"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// multicall3Address Multicall3 is deployed at the same address on most evm chains,
+	// set contract "multicall" in chain config to override it
+	multicall3Address = "0xcA11bde05779BA9821caC2f6bB24faA1Bc4E3dF1"
+	// multicallChunk calls aggregated in one eth_call
+	multicallChunk = 200
+	// batchConcurrency concurrent single calls of chains without multicall
+	batchConcurrency = 8
+)
+
+// BalanceQuery erc20 balance of Address in Contract
+type BalanceQuery struct {
+	Address  string
+	Contract string
+}
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+var contractABIs sync.Map
+
+func contractABI(name string) *abi.ABI {
+	if v, ok := contractABIs.Load(name); ok {
+		return v.(*abi.ABI)
+	}
+	b, err := os.ReadFile(filepath.Join(abiPath(), util.GetEnv("CONTRACT_ABI", "contract"), name+".abi"))
+	util.Panic(err)
+	parsed, err := abi.JSON(strings.NewReader(string(b)))
+	util.Panic(err)
+	contractABIs.Store(name, &parsed)
+	return &parsed
+}
+
+func (c ethCall) multicallAddress() string {
+	if address := util.GetContractAddress("multicall", c.Network); address != "" {
+		return address
+	}
+	return multicall3Address
+}
+
+func (c ethCall) rawCall(to string, data []byte) ([]byte, error) {
+	pointer := &dto.RequestResult{}
+	params := []interface{}{map[string]string{"to": to, "data": hexutil.Encode(data)}, "latest"}
+	if err := c.EthRPC().Provider.SendRequest(pointer, "eth_call", params); err != nil {
+		return nil, err
+	}
+	if pointer.Error != nil {
+		return nil, errors.New(pointer.Error.Message)
+	}
+	result, ok := pointer.Result.(string)
+	if !ok {
+		return nil, errors.New("eth_call result is not string")
+	}
+	return common.FromHex(result), nil
+}
+
+// aggregate run calls through multicall contract, return data of failed call is nil
+func (c ethCall) aggregate(calls []multicallCall) ([][]byte, error) {
+	multicall := contractABI("multicall")
+	returnData := make([][]byte, 0, len(calls))
+	for start := 0; start < len(calls); start += multicallChunk {
+		end := start + multicallChunk
+		if end > len(calls) {
+			end = len(calls)
+		}
+		input, err := multicall.Pack("aggregate3", calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		output, err := c.rawCall(c.multicallAddress(), input)
+		if err != nil {
+			return nil, err
+		}
+		values, err := multicall.Unpack("aggregate3", output)
+		if err != nil {
+			return nil, err
+		}
+		var results []multicallResult
+		if err = multicall.Methods["aggregate3"].Outputs.Copy(&results, values); err != nil {
+			return nil, err
+		}
+		if len(results) != end-start {
+			return nil, errors.New("multicall result count mismatch")
+		}
+		for _, r := range results {
+			if !r.Success {
+				r.ReturnData = nil
+			}
+			returnData = append(returnData, r.ReturnData)
+		}
+	}
+	return returnData, nil
+}
+
+// multicall pack calls of method to contracts, nil means the chain has no multicall and caller should fall back
+func (c ethCall) multicall(contractName, method string, targets []string, args [][]interface{}) [][]byte {
+	if len(targets) == 0 {
+		return [][]byte{}
+	}
+	contract := contractABI(contractName)
+	calls := make([]multicallCall, len(targets))
+	for i := range targets {
+		data, err := contract.Pack(method, args[i]...)
+		if err != nil {
+			log.Error("multicall pack %s.%s error: %s", contractName, method, err)
+			return nil
+		}
+		calls[i] = multicallCall{Target: common.HexToAddress(targets[i]), AllowFailure: true, CallData: data}
+	}
+	returnData, err := c.aggregate(calls)
+	if err != nil {
+		log.Warn("%s multicall %s.%s fail, fall back to single calls: %s", c.Network, contractName, method, err)
+		return nil
+	}
+	return returnData
+}
+
+func (c ethCall) BatchBalanceOf(queries []BalanceQuery) []*big.Int {
+	targets := make([]string, len(queries))
+	args := make([][]interface{}, len(queries))
+	for i, q := range queries {
+		targets[i] = q.Contract
+		args[i] = []interface{}{common.HexToAddress(q.Address)}
+	}
+	returnData := c.multicall("erc20", "balanceOf", targets, args)
+	if returnData == nil {
+		return batchBalanceOf(c, queries)
+	}
+	balances := make([]*big.Int, len(queries))
+	for i, data := range returnData {
+		if len(data) < 32 {
+			balances[i] = big.NewInt(-1)
+			continue
+		}
+		balances[i] = new(big.Int).SetBytes(data[:32])
+	}
+	return balances
+}
+
+func (c ethCall) BatchOwnerOf(tokenIds []string) []string {
+	returnData := c.multicall("objectOwnership", "ownerOf", sameTarget(util.GetContractAddress("objectOwnership", c.Network), len(tokenIds)), tokenIdArgs(tokenIds))
+	if returnData == nil {
+		return batchOwnerOf(c, tokenIds)
+	}
+	owners := make([]string, len(tokenIds))
+	for i, data := range returnData {
+		if len(data) < 32 {
+			continue
+		}
+		owners[i] = util.AddHex(hex.EncodeToString(data[12:32]))
+	}
+	return owners
+}
+
+func (c ethCall) BatchLandCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return c.batchCurrentPriceInToken("clockAuctionApostle", "clockAuction", tokenIds, batchLandCurrentPriceInToken)
+}
+
+func (c ethCall) BatchApostleCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return c.batchCurrentPriceInToken("clockAuctionApostle", "clockAuctionApostle", tokenIds, batchApostleCurrentPriceInToken)
+}
+
+// BatchSiringCurrentPriceInToken current prices of siring auctions, there is no single call fallback
+func (c ethCall) BatchSiringCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return c.batchCurrentPriceInToken("apostleFertility", "apostleFertility", tokenIds, func(_ IStorage, tokenIds []string) []decimal.Decimal {
+		return unknownPrices(len(tokenIds))
+	})
+}
+
+// batchCurrentPriceInToken getCurrentPriceInToken of abi of auctions deployed at contract, -1 if failed
+func (c ethCall) batchCurrentPriceInToken(abiName, contract string, tokenIds []string, fallback func(sg IStorage, tokenIds []string) []decimal.Decimal) []decimal.Decimal {
+	returnData := c.multicall(abiName, "getCurrentPriceInToken", sameTarget(util.GetContractAddress(contract, c.Network), len(tokenIds)), tokenIdArgs(tokenIds))
+	if returnData == nil {
+		return fallback(c, tokenIds)
+	}
+	prices := make([]decimal.Decimal, len(tokenIds))
+	for i, data := range returnData {
+		if len(data) < 32 {
+			prices[i] = decimal.RequireFromString("-1")
+			continue
+		}
+		prices[i] = util.BigToDecimal(new(big.Int).SetBytes(data[:32]), util.GetTokenDecimals(c.Network))
+	}
+	return prices
+}
+
+func (c ethCall) BatchUnclaimedResource(tokenIds []string, resourceAddress []string) [][]string {
+	resources := make([]common.Address, len(resourceAddress))
+	for i, address := range resourceAddress {
+		resources[i] = common.HexToAddress(address)
+	}
+	args := tokenIdArgs(tokenIds)
+	for i := range args {
+		args[i] = append(args[i], resources)
+	}
+	returnData := c.multicall("landResource", "availableLandResources", sameTarget(util.GetContractAddress("landResource", c.Network), len(tokenIds)), args)
+	if returnData == nil {
+		return batchUnclaimedResource(c, tokenIds, resourceAddress)
+	}
+	result := make([][]string, len(tokenIds))
+	for i, data := range returnData {
+		// same as UnclaimedResource, words after the offset and length of uint256[]
+		if words := util.LogAnalysis(hex.EncodeToString(data)); len(words) > 2 {
+			result[i] = words[2:]
+		}
+	}
+	return result
+}
+
+func sameTarget(target string, n int) []string {
+	targets := make([]string, n)
+	for i := range targets {
+		targets[i] = target
+	}
+	return targets
+}
+
+func tokenIdArgs(tokenIds []string) [][]interface{} {
+	args := make([][]interface{}, len(tokenIds))
+	for i, tokenId := range tokenIds {
+		args[i] = []interface{}{util.U256(tokenId)}
+	}
+	return args
+}
+
+// batch fallbacks, single call for each item with limited concurrency
+
+func eachConcurrently(n int, f func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, batchConcurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func batchBalanceOf(sg IStorage, queries []BalanceQuery) []*big.Int {
+	balances := make([]*big.Int, len(queries))
+	eachConcurrently(len(queries), func(i int) {
+		balances[i] = sg.BalanceOf(queries[i].Address, queries[i].Contract)
+	})
+	return balances
+}
+
+func batchOwnerOf(sg IStorage, tokenIds []string) []string {
+	owners := make([]string, len(tokenIds))
+	eachConcurrently(len(tokenIds), func(i int) {
+		owners[i], _ = sg.OwnerOf(tokenIds[i])
+	})
+	return owners
+}
+
+func batchLandCurrentPriceInToken(sg IStorage, tokenIds []string) []decimal.Decimal {
+	prices := make([]decimal.Decimal, len(tokenIds))
+	eachConcurrently(len(tokenIds), func(i int) {
+		prices[i] = sg.LandCurrentPriceInToken(tokenIds[i])
+	})
+	return prices
+}
+
+func batchApostleCurrentPriceInToken(sg IStorage, tokenIds []string) []decimal.Decimal {
+	prices := make([]decimal.Decimal, len(tokenIds))
+	eachConcurrently(len(tokenIds), func(i int) {
+		prices[i] = sg.ApostleCurrentPriceInToken(tokenIds[i])
+	})
+	return prices
+}
+
+// unknownPrices -1 for each token of chains that can not read the price
+func unknownPrices(n int) []decimal.Decimal {
+	prices := make([]decimal.Decimal, n)
+	for i := range prices {
+		prices[i] = decimal.RequireFromString("-1")
+	}
+	return prices
+}
+
+func batchUnclaimedResource(sg IStorage, tokenIds []string, resourceAddress []string) [][]string {
+	result := make([][]string, len(tokenIds))
+	eachConcurrently(len(tokenIds), func(i int) {
+		result[i] = sg.UnclaimedResource(tokenIds[i], resourceAddress)
+	})
+	return result
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// multicallNode answer aggregate3 of erc20 balanceOf with the last byte of owner, owner 0x0 fails
+func multicallNode(t *testing.T) *httptest.Server {
+	multicall, erc20 := contractABI("multicall"), contractABI("erc20")
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     int               `json:"id"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		var call struct {
+			Data string `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(req.Params[0], &call))
+		input := common.FromHex(call.Data)
+		values, err := multicall.Methods["aggregate3"].Inputs.Unpack(input[4:])
+		assert.NoError(t, err)
+		var calls []multicallCall
+		assert.NoError(t, multicall.Methods["aggregate3"].Inputs.Copy(&calls, values))
+
+		var results []multicallResult
+		for _, c := range calls {
+			args, err := erc20.Methods["balanceOf"].Inputs.Unpack(c.CallData[4:])
+			assert.NoError(t, err)
+			owner := args[0].(common.Address)
+			if owner == (common.Address{}) {
+				results = append(results, multicallResult{})
+				continue
+			}
+			results = append(results, multicallResult{Success: true, ReturnData: common.BigToHash(big.NewInt(int64(owner[19]))).Bytes()})
+		}
+		output, err := multicall.Methods["aggregate3"].Outputs.Pack(results)
+		assert.NoError(t, err)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": hexutil.Encode(output)})
+	}))
+}
+
+func TestEthCall_BatchBalanceOf(t *testing.T) {
+	node := multicallNode(t)
+	defer node.Close()
+	c := ethCall{Call: Call{Network: Ethereum, RpcClient: util.NewRPCBalancer([]string{node.URL}, 10, false)}}
+	balances := c.BatchBalanceOf([]BalanceQuery{
+		{Address: "0x0000000000000000000000000000000000000001", Contract: "0x00000000000000000000000000000000000000aa"},
+		{Address: "0x0000000000000000000000000000000000000000", Contract: "0x00000000000000000000000000000000000000aa"},
+		{Address: "0x0000000000000000000000000000000000000007", Contract: "0x00000000000000000000000000000000000000bb"},
+	})
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(-1), big.NewInt(7)}, balances)
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrOffline storage of an offline chain is read, see Offline
+var ErrOffline = errors.New("chain storage is offline")
+
+var (
+	offlineChains = make(map[string]bool)
+	offlineLock   sync.RWMutex
+)
+
+// Offline following storage.New(chain) calls panic with ErrOffline until Online(chain). Jobs that must not
+// read the chain, as replaying stored logs, recover it to fail the work which tried to
+func Offline(chain string) {
+	offlineLock.Lock()
+	offlineChains[chain] = true
+	offlineLock.Unlock()
+}
+
+// Online undo Offline(chain)
+func Online(chain string) {
+	offlineLock.Lock()
+	delete(offlineChains, chain)
+	offlineLock.Unlock()
+}
+
+func isOffline(chain string) bool {
+	offlineLock.RLock()
+	defer offlineLock.RUnlock()
+	return offlineChains[chain]
+}
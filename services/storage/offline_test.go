@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffline(t *testing.T) {
+	Offline(Sim)
+	assert.PanicsWithValue(t, ErrOffline, func() { New(Sim) })
+	assert.NotPanics(t, func() { New(Ethereum) })
+	Online(Sim)
+	assert.NotNil(t, New(Sim))
+}
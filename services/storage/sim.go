@@ -0,0 +1,810 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evolutionlandorg/block-scan/services"
+	evoServices "github.com/evolutionlandorg/evo-backend/services"
+	"github.com/evolutionlandorg/evo-backend/util"
+
+	"github.com/shopspring/decimal"
+)
+
+// SimChain in-process chain implementing IStorage, used by tests instead of a live RPC.
+// Transactions submitted with SendTransaction stay pending until the next block is mined.
+type SimChain struct {
+	Call
+	mu sync.RWMutex
+
+	BlockTime uint64
+	fork      int
+	blocks    []*SimBlock
+	pending   []*SimTransaction
+	txs       map[string]*SimTransaction
+
+	balances      map[string]*big.Int
+	erc20         map[string]map[string]*big.Int
+	erc1155       map[string]map[string]map[string]uint64
+	owners        map[string]string
+	degoOwners    map[string]string
+	locations     map[string][2]int64
+	resourceRates map[string]string
+	landMasks     map[string]int
+	unclaimed     map[string]map[string]*big.Int
+	auctions      map[string]string
+	landPrices    map[string]decimal.Decimal
+	apostlePrices map[string]decimal.Decimal
+	apostles      map[string][]string
+	nonces        map[string]map[string]int64
+	protects      map[string]int64
+	points        map[string]decimal.Decimal
+	penalties     map[int64]string
+	pools         map[string]*SimStakingPool
+	pairs         map[string]*SimPair
+
+	swapFee       decimal.Decimal
+	rewardsInPool decimal.Decimal
+}
+
+// SimBlock mined block of a SimChain
+type SimBlock struct {
+	Number     uint64
+	Hash       string
+	ParentHash string
+	Timestamp  uint64
+	Txs        []*SimTransaction
+}
+
+// SimTransaction transaction of a SimChain, Logs become the receipt logs once mined
+type SimTransaction struct {
+	Hash   string
+	From   string
+	To     string
+	Logs   []services.Log
+	Failed bool
+
+	block *SimBlock
+}
+
+// SimStakingPool StakingRewards.sol state
+type SimStakingPool struct {
+	RewardsToken string
+	StakingToken string
+	PeriodFinish int64
+	RewardRate   *big.Int
+}
+
+// SimPair UniswapV2Pair.sol state, lp balances are kept as erc20 balances of the pair
+type SimPair struct {
+	Token0             string
+	Token1             string
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast int64
+}
+
+var (
+	simChains = make(map[string]*SimChain)
+	simLock   sync.RWMutex
+)
+
+// NewSimChain create a simulated chain with a genesis block
+func NewSimChain(network string) *SimChain {
+	s := &SimChain{
+		Call:          Call{Network: network},
+		BlockTime:     6,
+		txs:           make(map[string]*SimTransaction),
+		balances:      make(map[string]*big.Int),
+		erc20:         make(map[string]map[string]*big.Int),
+		erc1155:       make(map[string]map[string]map[string]uint64),
+		owners:        make(map[string]string),
+		degoOwners:    make(map[string]string),
+		locations:     make(map[string][2]int64),
+		resourceRates: make(map[string]string),
+		landMasks:     make(map[string]int),
+		unclaimed:     make(map[string]map[string]*big.Int),
+		auctions:      make(map[string]string),
+		landPrices:    make(map[string]decimal.Decimal),
+		apostlePrices: make(map[string]decimal.Decimal),
+		apostles:      make(map[string][]string),
+		nonces:        make(map[string]map[string]int64),
+		protects:      make(map[string]int64),
+		points:        make(map[string]decimal.Decimal),
+		penalties:     make(map[int64]string),
+		pools:         make(map[string]*SimStakingPool),
+		pairs:         make(map[string]*SimPair),
+		swapFee:       decimal.Zero,
+		rewardsInPool: decimal.Zero,
+	}
+	s.appendBlock(uint64(time.Now().Unix()))
+	return s
+}
+
+// Simulate replace chain with a new SimChain, following storage.New(chain) calls return it
+func Simulate(chain string) *SimChain {
+	s := NewSimChain(chain)
+	simLock.Lock()
+	simChains[chain] = s
+	simLock.Unlock()
+	return s
+}
+
+// StopSimulate restore the real storage of chain
+func StopSimulate(chain string) {
+	simLock.Lock()
+	delete(simChains, chain)
+	simLock.Unlock()
+}
+
+func getSimChain(chain string) *SimChain {
+	simLock.RLock()
+	s := simChains[chain]
+	simLock.RUnlock()
+	if s == nil && chain == Sim {
+		simLock.Lock()
+		defer simLock.Unlock()
+		if s = simChains[chain]; s == nil {
+			s = NewSimChain(chain)
+			simChains[chain] = s
+		}
+	}
+	return s
+}
+
+func simKey(s string) string {
+	return strings.ToLower(util.TrimHex(s))
+}
+
+func simWord(v *big.Int) string {
+	return fmt.Sprintf("%064x", v)
+}
+
+func simHash(parts ...interface{}) string {
+	h := sha256.Sum256([]byte(fmt.Sprint(parts...)))
+	return util.AddHex(hex.EncodeToString(h[:]))
+}
+
+// SimLog build a log of event signature, eg: Transfer(address,address,uint256)
+func SimLog(address, event string, topics []string, data ...string) services.Log {
+	log := services.Log{Address: strings.ToLower(address), Topics: []string{evoServices.AbiEncodingMethod(event)}}
+	log.Topics = append(log.Topics, topics...)
+	for _, word := range data {
+		log.Data += fmt.Sprintf("%064s", util.TrimHex(word))
+	}
+	log.Data = util.AddHex(log.Data)
+	return log
+}
+
+func (s *SimChain) head() *SimBlock {
+	return s.blocks[len(s.blocks)-1]
+}
+
+func (s *SimChain) appendBlock(timestamp uint64) *SimBlock {
+	block := SimBlock{Timestamp: timestamp, Txs: s.pending}
+	if len(s.blocks) > 0 {
+		block.Number = s.head().Number + 1
+		block.ParentHash = s.head().Hash
+	}
+	txHashes := make([]string, 0, len(block.Txs))
+	for _, tx := range block.Txs {
+		tx.block = &block
+		txHashes = append(txHashes, tx.Hash)
+	}
+	block.Hash = simHash(s.Network, block.Number, block.ParentHash, s.fork, txHashes)
+	s.pending = nil
+	s.blocks = append(s.blocks, &block)
+	return &block
+}
+
+// Mine pack pending transactions into a new block
+func (s *SimChain) Mine() *SimBlock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.appendBlock(s.head().Timestamp + s.BlockTime)
+}
+
+// AdvanceBlocks mine n blocks, return the new head
+func (s *SimChain) AdvanceBlocks(n int) *SimBlock {
+	var block *SimBlock
+	for i := 0; i < n; i++ {
+		block = s.Mine()
+	}
+	return block
+}
+
+// Reorg drop the latest depth blocks and mine the same number of blocks with different hashes,
+// transactions in dropped blocks are returned to pending unless they are dropped too
+func (s *SimChain) Reorg(depth int, dropTxs bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if depth >= len(s.blocks) {
+		depth = len(s.blocks) - 1
+	}
+	orphaned := s.blocks[len(s.blocks)-depth:]
+	s.blocks = s.blocks[:len(s.blocks)-depth]
+	s.fork++
+	var pending []*SimTransaction
+	for _, block := range orphaned {
+		for _, tx := range block.Txs {
+			tx.block = nil
+			if dropTxs {
+				delete(s.txs, tx.Hash)
+				continue
+			}
+			pending = append(pending, tx)
+		}
+	}
+	s.pending = append(pending, s.pending...)
+	for range orphaned {
+		s.appendBlock(s.head().Timestamp + s.BlockTime)
+	}
+}
+
+// SendTransaction add a pending transaction, return tx hash
+func (s *SimChain) SendTransaction(tx SimTransaction) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx.Hash == "" {
+		tx.Hash = simHash(s.Network, "tx", len(s.txs), s.head().Number, tx.To)
+	}
+	tx.To = strings.ToLower(tx.To)
+	tx.block = nil
+	s.txs[tx.Hash] = &tx
+	s.pending = append(s.pending, &tx)
+	return tx.Hash
+}
+
+// EmitLogs send a transaction to contract emitting logs and mine it, return tx hash
+func (s *SimChain) EmitLogs(to string, logs ...services.Log) string {
+	tx := s.SendTransaction(SimTransaction{To: to, Logs: logs})
+	s.Mine()
+	return tx
+}
+
+// Block get mined block by number
+func (s *SimChain) Block(blockNum uint64) *SimBlock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if blockNum >= uint64(len(s.blocks)) {
+		return nil
+	}
+	return s.blocks[blockNum]
+}
+
+func (s *SimChain) SetBalance(address string, amount *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[simKey(address)] = new(big.Int).Set(amount)
+}
+
+// MintERC20 add amount of contract token to address
+func (s *SimChain) MintERC20(contract, to string, amount *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	contract = simKey(contract)
+	if s.erc20[contract] == nil {
+		s.erc20[contract] = make(map[string]*big.Int)
+	}
+	balance, ok := s.erc20[contract][simKey(to)]
+	if !ok {
+		balance = new(big.Int)
+		s.erc20[contract][simKey(to)] = balance
+	}
+	balance.Add(balance, amount)
+}
+
+// MintERC721 set owner of tokenId (objectOwnership)
+func (s *SimChain) MintERC721(tokenId, owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owners[simKey(tokenId)] = strings.ToLower(owner)
+}
+
+// MintERC1155 add amount of token id to owner
+func (s *SimChain) MintERC1155(contract, owner string, id *big.Int, amount uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	contract = simKey(contract)
+	if s.erc1155[contract] == nil {
+		s.erc1155[contract] = make(map[string]map[string]uint64)
+	}
+	if s.erc1155[contract][simKey(owner)] == nil {
+		s.erc1155[contract][simKey(owner)] = make(map[string]uint64)
+	}
+	s.erc1155[contract][simKey(owner)][id.String()] += amount
+}
+
+func (s *SimChain) MintDEGO(tokenId, owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.degoOwners[simKey(tokenId)] = strings.ToLower(owner)
+}
+
+// SetLand set land location, resource rate attr and flag mask
+func (s *SimChain) SetLand(tokenId string, x, y int64, resourceRateAttr string, mask int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locations[simKey(tokenId)] = [2]int64{x, y}
+	s.resourceRates[simKey(tokenId)] = resourceRateAttr
+	s.landMasks[simKey(tokenId)] = mask
+}
+
+// SetUnclaimedResource set land or drill unclaimed amount of resource
+func (s *SimChain) SetUnclaimedResource(tokenId, resource string, amount *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unclaimed[simKey(tokenId)] == nil {
+		s.unclaimed[simKey(tokenId)] = make(map[string]*big.Int)
+	}
+	s.unclaimed[simKey(tokenId)][simKey(resource)] = new(big.Int).Set(amount)
+}
+
+// SetAuction set raw getAuction result of tokenId, empty data removes the auction
+func (s *SimChain) SetAuction(tokenId, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if data == "" {
+		delete(s.auctions, simKey(tokenId))
+		return
+	}
+	s.auctions[simKey(tokenId)] = data
+}
+
+func (s *SimChain) SetLandPrice(tokenId string, price decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.landPrices[simKey(tokenId)] = price
+}
+
+func (s *SimChain) SetApostlePrice(tokenId string, price decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apostlePrices[simKey(tokenId)] = price
+}
+
+// SetApostle set tokenId2Apostle words
+func (s *SimChain) SetApostle(tokenId string, words []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apostles[simKey(tokenId)] = words
+}
+
+func (s *SimChain) SetNonce(address, contract string, nonce int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nonces[simKey(contract)] == nil {
+		s.nonces[simKey(contract)] = make(map[string]int64)
+	}
+	s.nonces[simKey(contract)][simKey(address)] = nonce
+}
+
+func (s *SimChain) SetProtectPeriod(tokenId string, end int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.protects[simKey(tokenId)] = end
+}
+
+func (s *SimChain) SetPoints(address string, points decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.points[simKey(address)] = points
+}
+
+func (s *SimChain) SetPenalty(depositId int64, penalty string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.penalties[depositId] = penalty
+}
+
+func (s *SimChain) SetSwapFee(fee decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.swapFee = fee
+}
+
+func (s *SimChain) SetTotalRewardInPool(reward decimal.Decimal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rewardsInPool = reward
+}
+
+func (s *SimChain) SetStakingPool(pool string, p SimStakingPool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pools[simKey(pool)] = &p
+}
+
+func (s *SimChain) SetPair(lpToken string, p SimPair) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pairs[simKey(lpToken)] = &p
+}
+
+// RPC
+
+func (s *SimChain) ReceiptLog(tx string) (*services.Receipts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t := s.txs[tx]
+	if t == nil || t.block == nil {
+		return nil, nil
+	}
+	receipt := services.Receipts{
+		BlockNumber: util.IntToHex(t.block.Number),
+		Status:      util.If(t.Failed, "0x0", "0x1").(string),
+		Logs:        append([]services.Log{}, t.Logs...),
+		ChainSource: s.Network,
+		Solidity:    true,
+	}
+	return &receipt, nil
+}
+
+func (s *SimChain) BlockNumber() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.head().Number
+}
+
+func (s *SimChain) FilterTrans(blockNum uint64, filter []string) (txn []string, contracts []string, timestamp uint64, transactionTo []string) {
+	_, txn, contracts, timestamp, transactionTo = s.FilterBlock(blockNum, filter)
+	return
+}
+
+func (s *SimChain) FilterBlock(blockNum uint64, filter []string) (hash string, txn []string, contracts []string, timestamp uint64, transactionTo []string) {
+	block := s.Block(blockNum)
+	if block == nil {
+		return
+	}
+	hash = block.Hash
+	for _, tx := range block.Txs {
+		transactionTo = append(transactionTo, tx.To)
+		if util.StringInSlice(tx.To, filter) {
+			txn = append(txn, tx.Hash)
+			contracts = append(contracts, tx.To)
+		}
+	}
+	timestamp = block.Timestamp
+	return
+}
+
+func (s *SimChain) GetBalance(address string) *big.Int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if balance, ok := s.balances[simKey(address)]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return big.NewInt(0)
+}
+
+func (s *SimChain) BlockHeader(blockNum uint64) *services.BlockHeader {
+	block := s.Block(blockNum)
+	if block == nil {
+		return nil
+	}
+	return &services.BlockHeader{BlockTimeStamp: block.Timestamp, Hash: block.Hash}
+}
+
+func (s *SimChain) GetTransactionStatus(tx string) string {
+	res, _ := s.ReceiptLog(tx)
+	if res == nil {
+		return ""
+	}
+	if res.Status == "0x1" {
+		return "Success"
+	}
+	return "Fail"
+}
+
+func (s *SimChain) GetTransaction(tx string) *Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t := s.txs[tx]
+	if t == nil || t.block == nil {
+		return nil
+	}
+	return &Transaction{BlockNum: t.block.Number}
+}
+
+func (s *SimChain) SendRawTransaction(signedDat string) (string, error, int) {
+	return s.SendTransaction(SimTransaction{Hash: simHash(s.Network, "raw", signedDat)}), nil, 0
+}
+
+// Contract
+
+func (s *SimChain) GetTokenLocationHM(tokenId string) (int64, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	location, ok := s.locations[simKey(tokenId)]
+	if !ok {
+		return 0, 0, fmt.Errorf("land %s not found", tokenId)
+	}
+	return location[0], location[1], nil
+}
+
+func (s *SimChain) OwnerOf(tokenId string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.owners[simKey(tokenId)], nil
+}
+
+func (s *SimChain) GetResourceRateAttr(tokenId string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.resourceRates[simKey(tokenId)], nil
+}
+
+func (s *SimChain) DEGOOwnerOf(tokenId string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.degoOwners[simKey(tokenId)]
+}
+
+func (s *SimChain) DEGOTokensOfOwner(owner string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var tokens []string
+	for tokenId, o := range s.degoOwners {
+		if strings.EqualFold(o, owner) {
+			tokens = append(tokens, tokenId)
+		}
+	}
+	return tokens
+}
+
+func (s *SimChain) UserToNonce(address, contract string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nonces[simKey(contract)][simKey(address)]
+}
+
+func (s *SimChain) BalanceOf(address, contract string) *big.Int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if balance, ok := s.erc20[simKey(contract)][simKey(address)]; ok {
+		return new(big.Int).Set(balance)
+	}
+	return big.NewInt(0)
+}
+
+func (s *SimChain) QuerySwapFee(*big.Int) decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.swapFee
+}
+
+func (s *SimChain) TotalRewardInPool() decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rewardsInPool
+}
+
+func (s *SimChain) PointsBalanceOf(address string) decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.points[simKey(address)]
+}
+
+func (s *SimChain) ComputePenalty(depositId int64) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.penalties[depositId]
+}
+
+func (s *SimChain) ApostleCurrentPriceInToken(tokenId string) decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if price, ok := s.apostlePrices[simKey(tokenId)]; ok {
+		return price
+	}
+	return decimal.RequireFromString("-1")
+}
+
+func (s *SimChain) LandCurrentPriceInToken(tokenId string) decimal.Decimal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if price, ok := s.landPrices[simKey(tokenId)]; ok {
+		return price
+	}
+	return decimal.RequireFromString("-1")
+}
+
+func (s *SimChain) unclaimedResource(tokenId string, resourceAddress []string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	resources := s.unclaimed[simKey(tokenId)]
+	result := make([]string, 0, len(resourceAddress))
+	for _, address := range resourceAddress {
+		amount := resources[simKey(address)]
+		if amount == nil {
+			amount = big.NewInt(0)
+		}
+		result = append(result, simWord(amount))
+	}
+	return result
+}
+
+func (s *SimChain) DrillUnclaimedResource(drillTokenId string, resourceAddress []string) []string {
+	return s.unclaimedResource(drillTokenId, resourceAddress)
+}
+
+func (s *SimChain) LandMask(tokenId string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.landMasks[simKey(tokenId)], nil
+}
+
+func (s *SimChain) UnclaimedResource(tokenId string, resourceAddress []string) []string {
+	return s.unclaimedResource(tokenId, resourceAddress)
+}
+
+func (s *SimChain) Auction(tokenId string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.auctions[simKey(tokenId)]
+	if !ok {
+		return "", errors.New("get auction fail, not found auction from chain")
+	}
+	return data, nil
+}
+
+func (s *SimChain) ProtectPeriod(_, tokenId string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.protects[simKey(tokenId)]
+}
+
+// ERC1155
+
+func (s *SimChain) BalanceOfBatch(contractAddr string, owners []string, ids []*big.Int) []uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(owners) != len(ids) {
+		return nil
+	}
+	res := make([]uint64, 0, len(owners))
+	for i, owner := range owners {
+		res = append(res, s.erc1155[simKey(contractAddr)][simKey(owner)][ids[i].String()])
+	}
+	return res
+}
+
+// Batch
+
+func (s *SimChain) BatchBalanceOf(queries []BalanceQuery) []*big.Int {
+	return batchBalanceOf(s, queries)
+}
+
+func (s *SimChain) BatchOwnerOf(tokenIds []string) []string {
+	return batchOwnerOf(s, tokenIds)
+}
+
+func (s *SimChain) BatchLandCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return batchLandCurrentPriceInToken(s, tokenIds)
+}
+
+func (s *SimChain) BatchApostleCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return batchApostleCurrentPriceInToken(s, tokenIds)
+}
+
+func (s *SimChain) BatchSiringCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return unknownPrices(len(tokenIds))
+}
+
+func (s *SimChain) BatchUnclaimedResource(tokenIds []string, resourceAddress []string) [][]string {
+	return batchUnclaimedResource(s, tokenIds, resourceAddress)
+}
+
+// StakingRewards.sol
+
+func (s *SimChain) stakingPool(pool string) (*SimStakingPool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.pools[simKey(pool)]
+	if !ok {
+		return nil, fmt.Errorf("staking pool %s not found", pool)
+	}
+	return p, nil
+}
+
+func (s *SimChain) RewardsToken(pool string) (string, error) {
+	p, err := s.stakingPool(pool)
+	if err != nil {
+		return "", err
+	}
+	return p.RewardsToken, nil
+}
+
+func (s *SimChain) StakingToken(pool string) (string, error) {
+	p, err := s.stakingPool(pool)
+	if err != nil {
+		return "", err
+	}
+	return p.StakingToken, nil
+}
+
+func (s *SimChain) PeriodFinish(pool string) (int64, error) {
+	p, err := s.stakingPool(pool)
+	if err != nil {
+		return 0, err
+	}
+	return p.PeriodFinish, nil
+}
+
+func (s *SimChain) RewardRate(pool string) (*big.Int, error) {
+	p, err := s.stakingPool(pool)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(p.RewardRate), nil
+}
+
+// UniswapV2Pair.sol
+
+func (s *SimChain) pair(lpToken string) (*SimPair, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.pairs[simKey(lpToken)]
+	if !ok {
+		return nil, fmt.Errorf("pair %s not found", lpToken)
+	}
+	return p, nil
+}
+
+func (s *SimChain) PairBalanceOf(lpToken, pool string) (*big.Int, error) {
+	if _, err := s.pair(lpToken); err != nil {
+		return nil, err
+	}
+	return s.BalanceOf(pool, lpToken), nil
+}
+
+func (s *SimChain) TotalSupply(lpToken string) (*big.Int, error) {
+	if _, err := s.pair(lpToken); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	total := big.NewInt(0)
+	for _, balance := range s.erc20[simKey(lpToken)] {
+		total.Add(total, balance)
+	}
+	return total, nil
+}
+
+func (s *SimChain) Token0(lpToken string) (string, error) {
+	p, err := s.pair(lpToken)
+	if err != nil {
+		return "", err
+	}
+	return p.Token0, nil
+}
+
+func (s *SimChain) Token1(lpToken string) (string, error) {
+	p, err := s.pair(lpToken)
+	if err != nil {
+		return "", err
+	}
+	return p.Token1, nil
+}
+
+func (s *SimChain) GetReserves(lpToken string) (reserve0 *big.Int, reserve1 *big.Int, blockTimestampLast int64, err error) {
+	p, err := s.pair(lpToken)
+	if err != nil {
+		return big.NewInt(0), big.NewInt(0), 0, err
+	}
+	return new(big.Int).Set(p.Reserve0), new(big.Int).Set(p.Reserve1), p.BlockTimestampLast, nil
+}
+
+// Apostle
+
+func (s *SimChain) TokenId2Apostle(tokenId string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.apostles[simKey(tokenId)]
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimChain_Transaction(t *testing.T) {
+	s := Simulate(Heco)
+	defer StopSimulate(Heco)
+	assert.Equal(t, s, New(Heco))
+
+	contract := "0x0000000000000000000000000000000000000abc"
+	log := SimLog(contract, "Transfer(address,address,uint256)", nil, "01")
+	tx := s.SendTransaction(SimTransaction{To: contract, Logs: []services.Log{log}})
+	receipt, err := s.ReceiptLog(tx)
+	assert.Nil(t, err)
+	assert.Nil(t, receipt)
+	assert.Equal(t, "", s.GetTransactionStatus(tx))
+
+	block := s.Mine()
+	receipt, _ = s.ReceiptLog(tx)
+	assert.Equal(t, "0x1", receipt.Status)
+	assert.Equal(t, Heco, receipt.ChainSource)
+	assert.Len(t, receipt.Logs, 1)
+	assert.Equal(t, "Success", s.GetTransactionStatus(tx))
+	assert.Equal(t, block.Number, s.GetTransaction(tx).BlockNum)
+
+	txn, contracts, timestamp, _ := s.FilterTrans(block.Number, []string{contract})
+	assert.Equal(t, []string{tx}, txn)
+	assert.Equal(t, []string{contract}, contracts)
+	assert.Equal(t, block.Timestamp, timestamp)
+
+	s.AdvanceBlocks(3)
+	assert.Equal(t, block.Number+3, s.BlockNumber())
+}
+
+func TestSimChain_Reorg(t *testing.T) {
+	s := NewSimChain(Sim)
+	tx := s.EmitLogs("0x0000000000000000000000000000000000000abc")
+	s.AdvanceBlocks(2)
+	before := s.BlockHeader(s.BlockNumber() - 2).Hash
+
+	s.Reorg(3, true)
+	assert.NotEqual(t, before, s.BlockHeader(s.BlockNumber()-2).Hash)
+	assert.Nil(t, s.GetTransaction(tx))
+}
+
+func TestSimChain_Contract(t *testing.T) {
+	s := NewSimChain(Sim)
+	ring := "0x0000000000000000000000000000000000000001"
+	owner := "0x8303fa5849d4E7B84A44083BD993733c31f63B3B"
+	tokenId := "2a04000104000105000000000000000400000000000000000000000000000007"
+
+	s.MintERC20(ring, owner, big.NewInt(100))
+	s.MintERC20(ring, owner, big.NewInt(1))
+	assert.Equal(t, int64(101), s.BalanceOf(owner, ring).Int64())
+
+	s.MintERC721("0x"+tokenId, owner)
+	o, _ := s.OwnerOf(tokenId)
+	assert.Equal(t, "0x8303fa5849d4e7b84a44083bd993733c31f63b3b", o)
+
+	s.MintERC1155(ring, owner, big.NewInt(2), 5)
+	assert.Equal(t, []uint64{5, 0}, s.BalanceOfBatch(ring, []string{owner, owner}, []*big.Int{big.NewInt(2), big.NewInt(3)}))
+
+	assert.True(t, s.LandCurrentPriceInToken(tokenId).Equal(decimal.RequireFromString("-1")))
+	s.SetLandPrice(tokenId, decimal.NewFromInt(2))
+	assert.True(t, s.LandCurrentPriceInToken(tokenId).Equal(decimal.NewFromInt(2)))
+	s.SetApostlePrice(tokenId, decimal.NewFromInt(3))
+	prices := s.BatchApostleCurrentPriceInToken([]string{tokenId, owner})
+	assert.True(t, prices[0].Equal(decimal.NewFromInt(3)))
+	assert.True(t, prices[1].Equal(decimal.RequireFromString("-1")))
+
+	s.SetUnclaimedResource(tokenId, ring, big.NewInt(16))
+	result := s.UnclaimedResource(tokenId, []string{ring, owner})
+	assert.Len(t, result, 2)
+	assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000010", result[0])
+	assert.Equal(t, [][]string{result, result}, s.BatchUnclaimedResource([]string{tokenId, tokenId}, []string{ring, owner}))
+}
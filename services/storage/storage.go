@@ -1,7 +1,11 @@
 package storage
 
 import (
+	"context"
 	"math/big"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/evolutionlandorg/block-scan/services"
 	evoServices "github.com/evolutionlandorg/evo-backend/services"
@@ -11,12 +15,19 @@ import (
 	"github.com/evolutionlandorg/evo-backend/pkg/github.com/regcostajr/go-web3"
 
 	"github.com/shopspring/decimal"
+	"github.com/spf13/cast"
 )
 
 var (
 	ethNoneAddress = "0x0000000000000000000000000000000000000000"
 )
 
+// BlockFilter storage filtering transactions of a block along with the hash of it, the hash recorded for reorg
+// check is then the one of the block whose transactions were scanned
+type BlockFilter interface {
+	FilterBlock(blockNum uint64, filter []string) (hash string, txn []string, contracts []string, timestamp uint64, transactionTo []string)
+}
+
 type IStorage interface {
 	// RPC
 	ReceiptLog(tx string) (*services.Receipts, error)
@@ -50,6 +61,14 @@ type IStorage interface {
 	// ERC1155
 	BalanceOfBatch(string, []string, []*big.Int) []uint64
 
+	// Batch, through multicall on evm chains
+	BatchBalanceOf(queries []BalanceQuery) []*big.Int
+	BatchOwnerOf(tokenIds []string) []string
+	BatchLandCurrentPriceInToken(tokenIds []string) []decimal.Decimal
+	BatchApostleCurrentPriceInToken(tokenIds []string) []decimal.Decimal
+	BatchSiringCurrentPriceInToken(tokenIds []string) []decimal.Decimal
+	BatchUnclaimedResource(tokenIds []string, resourceAddress []string) [][]string
+
 	// StakingRewards.sol
 	RewardsToken(pool string) (string, error)
 	StakingToken(pool string) (string, error)
@@ -65,6 +84,8 @@ type IStorage interface {
 
 	// Apostle
 	TokenId2Apostle(tokenId string) []string
+
+	EndpointHealth() []roundrobin.EndpointHealth
 }
 
 type BlockHeader struct {
@@ -127,6 +148,30 @@ func (c Call) BalanceOfBatch(string, []string, []*big.Int) []uint64 {
 	panic("implement me")
 }
 
+func (c Call) BatchBalanceOf(queries []BalanceQuery) []*big.Int {
+	return batchBalanceOf(c, queries)
+}
+
+func (c Call) BatchOwnerOf(tokenIds []string) []string {
+	return batchOwnerOf(c, tokenIds)
+}
+
+func (c Call) BatchLandCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return batchLandCurrentPriceInToken(c, tokenIds)
+}
+
+func (c Call) BatchApostleCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return batchApostleCurrentPriceInToken(c, tokenIds)
+}
+
+func (c Call) BatchSiringCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return unknownPrices(len(tokenIds))
+}
+
+func (c Call) BatchUnclaimedResource(tokenIds []string, resourceAddress []string) [][]string {
+	return batchUnclaimedResource(c, tokenIds, resourceAddress)
+}
+
 func (c Call) LandMask(tokenId string) (int, error) {
 	panic("implement me")
 }
@@ -236,6 +281,14 @@ func (c Call) EthRPC() *web3.Web3 {
 	return item.(*web3.Web3)
 }
 
+// EndpointHealth health of rpc endpoints serving the chain
+func (c Call) EndpointHealth() []roundrobin.EndpointHealth {
+	if c.RpcClient == nil {
+		return nil
+	}
+	return c.RpcClient.Health()
+}
+
 const (
 	Ethereum = "Eth"
 	Tron     = "Tron"
@@ -243,49 +296,36 @@ const (
 	Heco     = "Heco"
 	Bsc      = "Bsc"
 	Polygon  = "Polygon"
+	Sim      = "Sim"
 )
 
+// GetChainWssRpc wss endpoint of chain from config/<chain>.json or <CHAIN>_WSS_RPC
 func GetChainWssRpc(chain string) string {
-	switch chain {
-	case Ethereum:
-		return util.GetEnv("ETH_WSS_RPC", "")
-	case Crab:
-		return util.GetEnv("CRAB_WSS_RPC", "wss://darwinia-crab.api.onfinality.io/public-ws")
-	case Tron:
-		return ""
-	case Heco:
-		return util.GetEnv("HECO_WSS_RPC", "wss://ws-testnet.hecochain.com")
-	case Polygon:
-		return util.GetEnv("POLYGON_WSS_RPC", "wss://polygon-mumbai.api.onfinality.io/public")
-	}
-	return ""
+	return util.Evo.WssEndpoint[chain]
 }
 
+// New storage of chain, any evm chain with rpc endpoints in config/<chain>.json is served by ethCall
 func New(chain string) IStorage {
+	if isOffline(chain) {
+		panic(ErrOffline)
+	}
+	if sim := getSimChain(chain); sim != nil {
+		return sim
+	}
 	c := Call{Network: chain}
 	switch chain {
 	case Ethereum:
-		c.RpcClient = util.Rb
+		c.RpcClient = rpcClient(Ethereum, func() *roundrobin.Balancer { return util.Rb })
 		return ethCall{Call: c}
-	case Crab:
-		crab := crabCall{ethCall{Call: c}}
-		crab.initClient()
-		return crab
 	case Tron:
 		rpc := evoServices.TronClient{TrxRPC: util.TronRpc}
 		return tronCall{Call: c, rpc: &rpc}
-	case Heco:
-		heco := hecoCall{ethCall{Call: c}}
-		heco.initClient()
-		return heco
-	case Bsc:
-		bsc := bscCall{ethCall{Call: c}}
-		bsc.initClient()
-		return bsc
-	case Polygon:
-		Polygon := polygonCall{ethCall{Call: c}}
-		Polygon.initClient()
-		return Polygon
+	}
+	if util.Evo.RpcEndpoint[chain] != "" {
+		c.RpcClient = rpcClient(chain, func() *roundrobin.Balancer {
+			return util.NewRPCBalancer(strings.Split(util.Evo.RpcEndpoint[chain], ","), util.Evo.RpcTimeout[chain], true)
+		})
+		return ethCall{Call: c}
 	}
 	return c
 }
@@ -293,3 +333,33 @@ func New(chain string) IStorage {
 func abiPath() string {
 	return util.GetEnv("ABI_PATH", "")
 }
+
+var rpcClients sync.Map
+
+// rpcClient balancer of chain shared by all storage instances, its endpoints are health checked in background
+func rpcClient(chain string, build func() *roundrobin.Balancer) *roundrobin.Balancer {
+	if b, ok := rpcClients.Load(chain); ok {
+		return b.(*roundrobin.Balancer)
+	}
+	b, loaded := rpcClients.LoadOrStore(chain, build())
+	balancer := b.(*roundrobin.Balancer)
+	if !loaded {
+		balancer.StartHealthCheck(context.Background(), rpcHealthOption(chain))
+	}
+	return balancer
+}
+
+func rpcHealthOption(chain string) roundrobin.HealthOption {
+	opt := roundrobin.DefaultHealthOption()
+	if depth := util.Evo.WipeBlock[chain].SafeDepth; depth > 0 {
+		opt.MaxLag = depth
+	}
+	if interval := cast.ToInt(util.GetEnv("RPC_HEALTH_CHECK_INTERVAL", "15")); interval > 0 {
+		opt.Interval = time.Duration(interval) * time.Second
+	}
+	opt.MaxLatency = time.Duration(cast.ToInt(util.GetEnv("RPC_MAX_LATENCY", "3000"))) * time.Millisecond
+	if rate := cast.ToFloat64(util.GetEnv("RPC_MAX_ERROR_RATE", "0.5")); rate > 0 {
+		opt.MaxErrorRate = rate
+	}
+	return opt
+}
@@ -209,6 +209,32 @@ func (c tronCall) DrillUnclaimedResource(drillTokenId string, resourceAddress []
 	return nil
 }
 
+// tron has no multicall, batch methods call the contract for each item concurrently
+
+func (t tronCall) BatchBalanceOf(queries []BalanceQuery) []*big.Int {
+	return batchBalanceOf(t, queries)
+}
+
+func (t tronCall) BatchOwnerOf(tokenIds []string) []string {
+	return batchOwnerOf(t, tokenIds)
+}
+
+func (t tronCall) BatchLandCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return batchLandCurrentPriceInToken(t, tokenIds)
+}
+
+func (t tronCall) BatchApostleCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return batchApostleCurrentPriceInToken(t, tokenIds)
+}
+
+func (t tronCall) BatchSiringCurrentPriceInToken(tokenIds []string) []decimal.Decimal {
+	return unknownPrices(len(tokenIds))
+}
+
+func (t tronCall) BatchUnclaimedResource(tokenIds []string, resourceAddress []string) [][]string {
+	return batchUnclaimedResource(t, tokenIds, resourceAddress)
+}
+
 func (t tronCall) Auction(tokenId string) (string, error) {
 	res := t.rpc.ContractCall(util.GetContractAddress("clockAuction", Tron), "", 0, 0, "getAuction(uint256)", tokenId)
 	if res == nil || len(res.ConstantResult) == 0 {
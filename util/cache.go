@@ -3,6 +3,7 @@ package util
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 )
@@ -22,6 +23,20 @@ func GetCache(ctx context.Context, key string) []byte {
 	}
 }
 
+// PopCache get and delete key, only one of concurrent callers gets the value
+func PopCache(ctx context.Context, key string) []byte {
+	cacheKey := fmt.Sprintf("evo:%s", key)
+	do := SubPoolWithContextDo(ctx)
+	cache, err := redis.String(do("get", cacheKey))
+	if err != nil {
+		return nil
+	}
+	if n, _ := redis.Int(do("del", cacheKey)); n == 0 {
+		return nil
+	}
+	return []byte(cache)
+}
+
 func SetMap(ctx context.Context, key string, field string, value interface{}) {
 	cacheKey := fmt.Sprintf("evo:%s", key)
 	_, _ = SubPoolWithContextDo(ctx)("HSET", cacheKey, field, value)
@@ -152,3 +167,61 @@ func SRemArray(ctx context.Context, key string, value []interface{}) bool {
 //	_, err = conn.Do("HMSET", args...)
 //	return
 //}
+
+// CacheTagVersions current versions of tags, values cached with older versions of their tags are stale
+func CacheTagVersions(ctx context.Context, tags []string) []int64 {
+	if len(tags) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		args = append(args, fmt.Sprintf("evo:CacheTag:%s", tag))
+	}
+	versions, _ := redis.Int64s(SubPoolWithContextDo(ctx)("mget", args...))
+	return versions
+}
+
+// InvalidateCacheTags bump versions of tags to make values cached with them stale. Versions outlive any cached
+// value, so a version expired and restarted from 0 can not match a value still cached
+func InvalidateCacheTags(ctx context.Context, tags ...string) {
+	for _, tag := range tags {
+		IncrCache(ctx, fmt.Sprintf("CacheTag:%s", tag), 86400)
+	}
+}
+
+// tokenBucket refill tokens of KEYS[1] by ARGV[1] per second up to ARGV[2] since the last take, take one at ARGV[3].
+// It returns 1 and 0 if taken, otherwise 0 and seconds until a token is available
+var tokenBucket = redis.NewScript(1, `
+local rate, burst, now = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local taken, wait = 0, (1 - tokens) / rate
+if tokens >= 1 then
+	tokens, taken, wait = tokens - 1, 1, 0
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('EXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+return {taken, tostring(wait)}
+`)
+
+// TakeToken take a token of bucket key refilled by rate tokens per second up to burst, shared by all instances.
+// If no token is left it returns false and the time until one is available
+func TakeToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	conn := subPool.Get()
+	defer conn.Close()
+	now := float64(time.Now().UnixNano()) / float64(time.Second)
+	reply, err := redis.Values(tokenBucket.DoContext(ctx, conn, fmt.Sprintf("evo:%s", key), rate, burst, now))
+	if err != nil {
+		return false, 0, err
+	}
+	var (
+		taken int
+		wait  float64
+	)
+	if _, err = redis.Scan(reply, &taken, &wait); err != nil {
+		return false, 0, err
+	}
+	return taken == 1, time.Duration(wait * float64(time.Second)), nil
+}
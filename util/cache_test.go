@@ -0,0 +1,48 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTakeToken(t *testing.T) {
+	mr := newTestRedis(t)
+	ctx := context.Background()
+	// burst tokens at once, then one every 1/rate second
+	for i := 0; i < 2; i++ {
+		taken, wait, err := TakeToken(ctx, "RateLimit:test", 10, 2)
+		assert.NoError(t, err)
+		assert.True(t, taken)
+		assert.Zero(t, wait)
+	}
+	taken, wait, err := TakeToken(ctx, "RateLimit:test", 10, 2)
+	assert.NoError(t, err)
+	assert.False(t, taken)
+	assert.True(t, wait > 0 && wait <= 100*time.Millisecond)
+	assert.True(t, mr.TTL("evo:RateLimit:test") > 0)
+
+	time.Sleep(wait + 10*time.Millisecond)
+	taken, _, err = TakeToken(ctx, "RateLimit:test", 10, 2)
+	assert.NoError(t, err)
+	assert.True(t, taken)
+
+	// buckets of keys are independent
+	taken, _, err = TakeToken(ctx, "RateLimit:other", 10, 2)
+	assert.NoError(t, err)
+	assert.True(t, taken)
+}
+
+func TestInvalidateCacheTags(t *testing.T) {
+	newTestRedis(t)
+	ctx := context.Background()
+	tags := []string{"wallet:0xabc", "district:1"}
+	assert.Equal(t, []int64{0, 0}, CacheTagVersions(ctx, tags))
+	InvalidateCacheTags(ctx, "district:1")
+	assert.Equal(t, []int64{0, 1}, CacheTagVersions(ctx, tags))
+	InvalidateCacheTags(ctx, tags...)
+	assert.Equal(t, []int64{1, 2}, CacheTagVersions(ctx, tags))
+	assert.Nil(t, CacheTagVersions(ctx, nil))
+}
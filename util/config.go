@@ -26,12 +26,16 @@ type ApplicationConf struct {
 	ContractsListen       []string
 	GenesisApostlePicture map[string]string
 	// multi network
+	Networks      []string          // chains in the order of application.json network
 	NetworkId     map[string]string // takeBack networkId
 	TokenDecimals map[string]int
 	Contracts     map[string]ContractAddress
 	GraphUrl      map[string]string
 	WipeBlock     map[string]WipeBlockConf
-	RpcEndpoint   map[string]string
+	RpcEndpoint   map[string]string // comma separated, "url|weight" to weight one
+	RpcTimeout    map[string]int32
+	WssEndpoint   map[string]string
+	SkipSnapshot  map[string]bool
 	District      map[string]uint
 	ChainId       map[string]uint
 	TokenIdPrefix map[string]string
@@ -73,6 +77,7 @@ type WipeBlockConf struct {
 	CacheKey   string `json:"cacheKey"`
 	BlockDelay uint   `json:"blockDelay"`
 	BlockTime  uint   `json:"blockTime"`
+	SafeDepth  uint64 `json:"safeDepth"` // blocks below head-SafeDepth are treated as final
 }
 
 type NetworkConf struct {
@@ -83,18 +88,21 @@ type NetworkConf struct {
 	GRLand        string          `json:"grLand"`
 	LandRange     []int           `json:"range"`
 	ChainId       uint            `json:"chainId"`
+	SkipSnapshot  bool            `json:"skipSnapshot"`
 	Production    *NetworkEnvConf `json:"production"`
 	Dev           *NetworkEnvConf `json:"dev"`
 	Formula       []Formula       `json:"formula"`
 }
 
 type NetworkEnvConf struct {
-	Contracts ContractAddress  `json:"contracts"`
-	NetworkId string           `json:"networkId"`
-	Rpc       string           `json:"rpc"`
-	GraphUrl  string           `json:"graphUrl"`
-	WipeBlock WipeBlockConf    `json:"wipeBlock"`
-	Tokens    map[string]Token `json:"tokens"`
+	Contracts  ContractAddress  `json:"contracts"`
+	NetworkId  string           `json:"networkId"`
+	Rpc        string           `json:"rpc"`
+	RpcTimeout int32            `json:"rpcTimeout"` // seconds, default 10
+	Wss        string           `json:"wss"`
+	GraphUrl   string           `json:"graphUrl"`
+	WipeBlock  WipeBlockConf    `json:"wipeBlock"`
+	Tokens     map[string]Token `json:"tokens"`
 }
 
 type Token struct {
@@ -105,6 +113,9 @@ type Token struct {
 
 type ContractAddress map[string]string // map[address]contractName
 
+// deprecatedRpcEnv former rpc env of chains, still read when <CHAIN>_RPC is not set
+var deprecatedRpcEnv = map[string]string{"Crab": "CRAB_NODE"}
+
 func LoadConf() {
 	var (
 		conf ApplicationConf
@@ -129,6 +140,9 @@ func LoadConf() {
 	TokenDecimals := make(map[string]int)
 	WipeBlock := make(map[string]WipeBlockConf)
 	RpcEndpoint := make(map[string]string)
+	RpcTimeout := make(map[string]int32)
+	WssEndpoint := make(map[string]string)
+	SkipSnapshot := make(map[string]bool)
 	TokenIdPrefix := make(map[string]string)
 	ApostlePrefix := make(map[string]string)
 	District := make(map[string]uint)
@@ -174,7 +188,17 @@ func LoadConf() {
 		Contracts[network] = contractsMap
 		GraphUrl[network] = ncf.GraphUrl
 		WipeBlock[network] = ncf.WipeBlock
-		RpcEndpoint[network] = ncf.Rpc
+		// <CHAIN>_RPC and <CHAIN>_WSS_RPC override the endpoints of config
+		RpcEndpoint[network] = GetEnv(fmt.Sprintf("%s_RPC", strings.ToUpper(network)), ncf.Rpc)
+		if env, ok := deprecatedRpcEnv[network]; ok && os.Getenv(fmt.Sprintf("%s_RPC", strings.ToUpper(network))) == "" {
+			RpcEndpoint[network] = GetEnv(env, RpcEndpoint[network])
+		}
+		WssEndpoint[network] = GetEnv(fmt.Sprintf("%s_WSS_RPC", strings.ToUpper(network)), ncf.Wss)
+		RpcTimeout[network] = ncf.RpcTimeout
+		if ncf.RpcTimeout == 0 {
+			RpcTimeout[network] = 10
+		}
+		SkipSnapshot[network] = n.SkipSnapshot
 		ChainId[network] = n.ChainId
 		District[network] = n.District
 		TokenIdPrefix[network] = n.TokenIdPrefix
@@ -183,11 +207,15 @@ func LoadConf() {
 		PLOLandId[network] = strings.Split(n.GRLand, ",")
 		formula[network] = n.Formula
 	}
+	conf.Networks = viper.GetStringSlice("network")
 	conf.NetworkId = NetworkId
 	conf.Contracts = Contracts
 	conf.GraphUrl = GraphUrl
 	conf.WipeBlock = WipeBlock
 	conf.RpcEndpoint = RpcEndpoint
+	conf.RpcTimeout = RpcTimeout
+	conf.WssEndpoint = WssEndpoint
+	conf.SkipSnapshot = SkipSnapshot
 	conf.District = District
 	conf.ChainId = ChainId
 	conf.TokenIdPrefix = TokenIdPrefix
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNetworkConf = `{
+  "district": 9,
+  "chainId": 9,
+  "skipSnapshot": true,
+  "tokenIdPrefix": "2a090001090001010000000000000009",
+  "dev": {
+    "contracts": {"ClockAuction": "0xAbC0000000000000000000000000000000000001"},
+    "networkId": "1287",
+    "rpc": "https://dev.rpc",
+    "wss": "wss://dev.rpc",
+    "wipeBlock": {"initBlock": 100},
+    "tokens": {"RING": {"address": "0x01", "symbol": "RING", "decimals": 18}}
+  },
+  "production": {
+    "contracts": {"ClockAuction": "0xAbC0000000000000000000000000000000000002"},
+    "networkId": "1284",
+    "rpc": "",
+    "wss": "",
+    "wipeBlock": {"initBlock": 200},
+    "tokens": {"RING": {"address": "0x02", "symbol": "RING", "decimals": 18}}
+  }
+}`
+
+func TestLoadConf(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "application.json"), []byte(`{"network": ["Moonbeam", "Crab"]}`), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "moonbeam.json"), []byte(testNetworkConf), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "crab.json"), []byte(testNetworkConf), 0644))
+
+	evo, pictureDir := Evo, ApostlePictureDir
+	defer func() { Evo, ApostlePictureDir = evo, pictureDir }()
+	ApostlePictureDir = t.TempDir()
+	t.Setenv("CONF_DIR", dir)
+	t.Setenv("MOONBEAM_WSS_RPC", "wss://env.rpc")
+	t.Setenv("CRAB_NODE", "https://crab.node")
+	LoadConf()
+
+	// chains declared only in config are registered in the order of application.json
+	assert.Equal(t, []string{"Moonbeam", "Crab"}, Evo.Networks)
+	assert.Equal(t, ContractAddress{"0xabc0000000000000000000000000000000000001": "ClockAuction"}, Evo.Contracts["Moonbeam"])
+	assert.Equal(t, "1287", Evo.NetworkId["Moonbeam"])
+	assert.Equal(t, uint64(100), Evo.WipeBlock["Moonbeam"].InitBlock)
+	assert.Equal(t, uint(9), Evo.District["Moonbeam"])
+	assert.True(t, Evo.SkipSnapshot["Moonbeam"])
+	assert.Equal(t, int32(10), Evo.RpcTimeout["Moonbeam"])
+	assert.Equal(t, "https://dev.rpc", Evo.RpcEndpoint["Moonbeam"])
+	assert.Equal(t, "wss://env.rpc", Evo.WssEndpoint["Moonbeam"])
+
+	// CRAB_NODE is read when CRAB_RPC is not set
+	assert.Equal(t, "https://crab.node", Evo.RpcEndpoint["Crab"])
+	t.Setenv("CRAB_RPC", "https://crab.rpc")
+	LoadConf()
+	assert.Equal(t, "https://crab.rpc", Evo.RpcEndpoint["Crab"])
+}
@@ -1,5 +1,7 @@
 package util
 
+import "net/http"
+
 type QYError struct {
 	Code int `json:"code"`
 	// Message string "json: message"
@@ -51,9 +53,24 @@ var QYErrorMap = map[int]string{
 	10041: "stage not exist",
 	10042: "need choose one card",
 	10043: "invalid card",
+	10044: "too many requests",
+	10045: "retry in progress",
 	30001: "upgrade in progress",
 	30002: "the building has reached the highest level",
 	30003: "the building upgrade complete",
+	30004: "error drawing index",
+	30005: "land is not available for building",
+	30006: "land choose out of range",
+	30007: "error apostle",
+	30008: "building status not allowed",
+	30009: "exceed miner limit of drawing",
+	30010: "exceed admin limit of drawing",
+	30011: "building not hiring",
+	30012: "not owner of building",
+	40001: "land already has dapp",
+	40002: "dapp status not allowed",
+	40003: "dapp not published",
+	40004: "not owner of land",
 	10404: "record not found",
 	20001: "itering login error",
 	20002: "not have any data",
@@ -65,6 +82,183 @@ var QYErrorMap = map[int]string{
 	99999: "need login",
 }
 
+// QYErrorStatus http status and stable machine-readable key of code for the error envelope
+var QYErrorStatus = map[int]struct {
+	Status int
+	Key    string
+}{
+	10000: {http.StatusInternalServerError, "fail"},
+	10001: {http.StatusBadRequest, "invalid_params"},
+	10002: {http.StatusUnauthorized, "invalid_signature"},
+	10003: {http.StatusBadRequest, "invalid_wallet"},
+	10004: {http.StatusConflict, "email_exist"},
+	10005: {http.StatusConflict, "name_exist"},
+	10006: {http.StatusConflict, "wallet_exist"},
+	10007: {http.StatusConflict, "wallet_exist"},
+	10008: {http.StatusBadRequest, "invalid_sso_query"},
+	10009: {http.StatusInternalServerError, "token_set_fail"},
+	10010: {http.StatusForbidden, "no_permission"},
+	10011: {http.StatusUnprocessableEntity, "insufficient_balance"},
+	10012: {http.StatusBadRequest, "invalid_nonce"},
+	10013: {http.StatusBadRequest, "invalid_sign_message"},
+	10014: {http.StatusConflict, "unfinished_withdraw"},
+	10015: {http.StatusConflict, "tx_imported"},
+	10016: {http.StatusNotFound, "treasure_not_found"},
+	10017: {http.StatusConflict, "treasure_unlocked"},
+	10018: {http.StatusInternalServerError, "open_treasure_fail"},
+	10019: {http.StatusConflict, "field_conflict"},
+	10020: {http.StatusNotFound, "bank_trade_not_found"},
+	10021: {http.StatusRequestEntityTooLarge, "upload_too_large"},
+	10022: {http.StatusUnsupportedMediaType, "upload_type_not_supported"},
+	10023: {http.StatusInternalServerError, "upload_fail"},
+	10024: {http.StatusBadRequest, "keystore_examine_fail"},
+	10025: {http.StatusInternalServerError, "upload_cover_fail"},
+	10026: {http.StatusConflict, "keystore_bound"},
+	10027: {http.StatusBadRequest, "invalid_phone"},
+	10028: {http.StatusBadRequest, "invalid_sms_code"},
+	10029: {http.StatusConflict, "already_grabbed"},
+	10030: {http.StatusConflict, "red_packet_opened"},
+	10031: {http.StatusConflict, "mobile_bound"},
+	10032: {http.StatusConflict, "mobile_used"},
+	10033: {http.StatusConflict, "email_bound"},
+	10034: {http.StatusTooManyRequests, "sms_limit"},
+	10035: {http.StatusForbidden, "wallet_not_bound"},
+	10036: {http.StatusConflict, "wallet_bound"},
+	10037: {http.StatusBadRequest, "invalid_app"},
+	10038: {http.StatusForbidden, "no_right"},
+	10039: {http.StatusConflict, "adventure_started"},
+	10040: {http.StatusBadRequest, "adventure_need_apostles"},
+	10041: {http.StatusNotFound, "stage_not_found"},
+	10042: {http.StatusBadRequest, "card_required"},
+	10043: {http.StatusBadRequest, "invalid_card"},
+	10044: {http.StatusTooManyRequests, "too_many_requests"},
+	10045: {http.StatusConflict, "retry_in_progress"},
+	30001: {http.StatusConflict, "upgrade_in_progress"},
+	30002: {http.StatusConflict, "building_max_level"},
+	30003: {http.StatusConflict, "upgrade_complete"},
+	30004: {http.StatusBadRequest, "invalid_drawing"},
+	30005: {http.StatusConflict, "building_land_unavailable"},
+	30006: {http.StatusBadRequest, "building_out_of_range"},
+	30007: {http.StatusBadRequest, "invalid_building_apostle"},
+	30008: {http.StatusConflict, "building_status_not_allowed"},
+	30009: {http.StatusConflict, "miner_limit_exceeded"},
+	30010: {http.StatusConflict, "admin_limit_exceeded"},
+	30011: {http.StatusConflict, "hiring_closed"},
+	30012: {http.StatusForbidden, "not_building_owner"},
+	40001: {http.StatusConflict, "dapp_exist"},
+	40002: {http.StatusConflict, "dapp_status_not_allowed"},
+	40003: {http.StatusNotFound, "dapp_not_published"},
+	40004: {http.StatusForbidden, "not_land_owner"},
+	10404: {http.StatusNotFound, "not_found"},
+	20001: {http.StatusBadGateway, "itering_login_fail"},
+	20002: {http.StatusNotFound, "no_data"},
+	20003: {http.StatusTooManyRequests, "rate_limited"},
+	20004: {http.StatusUnauthorized, "sso_token_expired"},
+	77777: {http.StatusBadRequest, "invalid_json"},
+	88888: {http.StatusServiceUnavailable, "callback_fail"},
+	88889: {http.StatusConflict, "tx_exist"},
+	99999: {http.StatusUnauthorized, "need_login"},
+}
+
+// QYErrorMapZh chinese messages of code, QYErrorMap is used if missing
+var QYErrorMapZh = map[int]string{
+	10000: "操作失败",
+	10001: "参数错误",
+	10002: "签名验证失败",
+	10003: "钱包地址错误",
+	10004: "邮箱已存在",
+	10005: "名称已存在",
+	10006: "钱包已存在",
+	10007: "钱包已存在",
+	10008: "SSO 参数错误",
+	10009: "token 设置失败",
+	10010: "没有权限",
+	10011: "余额不足",
+	10012: "nonce 错误",
+	10013: "签名消息错误",
+	10014: "有未完成的提现",
+	10015: "相同哈希的交易已导入",
+	10016: "宝箱不存在",
+	10017: "宝箱已解锁",
+	10018: "开宝箱失败",
+	10019: "字段冲突",
+	10020: "银行交易数据不存在",
+	10021: "上传文件不能超过 50kb",
+	10022: "不支持的上传类型",
+	10023: "上传文件失败",
+	10024: "keystore 审核失败",
+	10025: "上传地块封面失败",
+	10026: "已绑定 keystore",
+	10027: "手机号无效",
+	10028: "短信验证码无效",
+	10029: "已经抢过了",
+	10030: "已打开过该红包",
+	10031: "你已绑定手机",
+	10032: "该手机已被绑定",
+	10033: "你已绑定邮箱",
+	10034: "短信发送次数超限",
+	10035: "未绑定钱包",
+	10036: "已绑定钱包",
+	10037: "无效的应用",
+	10038: "无权操作",
+	10039: "冒险已开始",
+	10040: "冒险需要 4 个使徒",
+	10041: "关卡不存在",
+	10042: "需要选择一张卡片",
+	10043: "无效的卡片",
+	10044: "请求过于频繁",
+	10045: "正在重试中",
+	30001: "正在升级中",
+	30002: "建筑已达到最高等级",
+	30003: "建筑升级已完成",
+	30004: "图纸错误",
+	30005: "土地不可建造",
+	30006: "选择的土地超出范围",
+	30007: "使徒错误",
+	30008: "建筑状态不允许此操作",
+	30009: "超过图纸的矿工上限",
+	30010: "超过图纸的管理员上限",
+	30011: "建筑未在招聘",
+	30012: "不是建筑的所有者",
+	40001: "土地已有 dapp",
+	40002: "dapp 状态不允许此操作",
+	40003: "dapp 未发布",
+	40004: "不是土地的所有者",
+	10404: "记录不存在",
+	20001: "itering 登录错误",
+	20002: "没有任何数据",
+	20003: "60 秒内只能请求 8 次",
+	20004: "SSO token 已过期",
+	77777: "JSON 解析错误",
+	88888: "回调错误，请重试",
+	88889: "交易已存在",
+	99999: "需要登录",
+}
+
+// Status http status of code, 500 if unknown
+func (qyErr QYError) Status() int {
+	if s, ok := QYErrorStatus[qyErr.Code]; ok {
+		return s.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// Key machine-readable key of code, stable across message changes
+func (qyErr QYError) Key() string {
+	if s, ok := QYErrorStatus[qyErr.Code]; ok {
+		return s.Key
+	}
+	return "unknown"
+}
+
+// Message message of code in lang, en or zh
+func (qyErr QYError) Message(lang string) string {
+	if msg, ok := QYErrorMapZh[qyErr.Code]; ok && lang == "zh" {
+		return msg
+	}
+	return QYErrorMap[qyErr.Code]
+}
+
 func (qyErr *QYError) GetCode() string {
 	return QYErrorMap[qyErr.Code]
 }
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQYError(t *testing.T) {
+	err := QYError{Code: 10044}
+	assert.Equal(t, http.StatusTooManyRequests, err.Status())
+	assert.Equal(t, "too_many_requests", err.Key())
+	assert.Equal(t, "too many requests", err.Message("en"))
+	assert.Equal(t, "请求过于频繁", err.Message("zh"))
+
+	assert.Equal(t, "too many requests", err.Message("fr"))
+
+	err = QYError{Code: 12345}
+	assert.Equal(t, http.StatusInternalServerError, err.Status())
+	assert.Equal(t, "unknown", err.Key())
+	assert.Empty(t, err.Message("en"))
+}
+
+// every code has a status, key and messages in both languages
+func TestQYErrorMaps(t *testing.T) {
+	for code := range QYErrorMap {
+		assert.Contains(t, QYErrorStatus, code)
+		assert.Contains(t, QYErrorMapZh, code)
+	}
+	assert.Len(t, QYErrorStatus, len(QYErrorMap))
+	assert.Len(t, QYErrorMapZh, len(QYErrorMap))
+}
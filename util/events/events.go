@@ -0,0 +1,265 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/evolutionlandorg/evo-backend/util"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+)
+
+// Registry abi events indexed by topic0, used to decode logs into typed event structs
+type Registry struct {
+	mu     sync.RWMutex
+	events map[common.Hash][]abi.Event
+	names  map[string]bool
+}
+
+// Handler typed event subscriber, see On
+type Handler struct {
+	Name   string
+	handle func(l services.Log, event *abi.Event) error
+}
+
+var (
+	defaultRegistry = NewRegistry()
+	loadOnce        sync.Once
+)
+
+func NewRegistry() *Registry {
+	return &Registry{events: make(map[common.Hash][]abi.Event), names: make(map[string]bool)}
+}
+
+// Default registry of the abi files in ABI_PATH/CONTRACT_ABI, loaded on first use
+func Default() *Registry {
+	loadOnce.Do(func() {
+		dir := filepath.Join(util.GetEnv("ABI_PATH", ""), util.GetEnv("CONTRACT_ABI", "contract"))
+		if err := defaultRegistry.LoadDir(dir); err != nil {
+			log.Error("load abi events from %s error: %s", dir, err)
+		}
+	})
+	return defaultRegistry
+}
+
+// MustRegister add event signature to default registry, for events without abi file
+func MustRegister(signature string) {
+	util.Panic(defaultRegistry.Register(signature))
+}
+
+// MustRegisterAs add event signature to default registry under name, see RegisterAs
+func MustRegisterAs(name, signature string) {
+	util.Panic(defaultRegistry.RegisterAs(name, signature))
+}
+
+// Handle decode log with default registry and dispatch to handlers
+func Handle(l services.Log, handlers ...Handler) error {
+	return Default().Handle(l, handlers...)
+}
+
+// LoadDir add events of all *.abi files in dir
+func (r *Registry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.abi"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no abi file found in %s", dir)
+	}
+	for _, file := range files {
+		if err := r.LoadFile(file); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) LoadFile(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	contract, err := abi.JSON(strings.NewReader(string(b)))
+	if err != nil {
+		return err
+	}
+	for _, event := range contract.Events {
+		r.add(event)
+	}
+	return nil
+}
+
+// Register add event by signature like "Transfer(address indexed from, address indexed to, uint256 value)"
+func (r *Registry) Register(signature string) error {
+	return r.RegisterAs("", signature)
+}
+
+// RegisterAs add event by signature under name, for overloaded events, e.g. the Enchanced of ItemBase and
+// CraftBase, so that each of them is subscribed by its own type. Empty name is the name of signature
+func (r *Registry) RegisterAs(name, signature string) error {
+	signature = strings.TrimSpace(signature)
+	open, end := strings.Index(signature, "("), strings.LastIndex(signature, ")")
+	if open <= 0 || end != len(signature)-1 {
+		return fmt.Errorf("invalid event signature %s", signature)
+	}
+	rawName := strings.TrimSpace(signature[:open])
+	if name == "" {
+		name = rawName
+	}
+	var inputs abi.Arguments
+	if params := strings.TrimSpace(signature[open+1 : end]); params != "" {
+		for i, param := range strings.Split(params, ",") {
+			fields := strings.Fields(param)
+			if len(fields) == 0 || len(fields) > 3 {
+				return fmt.Errorf("invalid param %q of event signature %s", param, signature)
+			}
+			typ, err := abi.NewType(fields[0], "", nil)
+			if err != nil {
+				return err
+			}
+			arg := abi.Argument{Name: fmt.Sprintf("arg%d", i), Type: typ}
+			for _, field := range fields[1:] {
+				if field == "indexed" {
+					arg.Indexed = true
+				} else {
+					arg.Name = field
+				}
+			}
+			inputs = append(inputs, arg)
+		}
+	}
+	r.add(abi.NewEvent(name, rawName, false, inputs))
+	return nil
+}
+
+func (r *Registry) add(event abi.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.events[event.ID] {
+		if indexedCount(e) == indexedCount(event) {
+			return
+		}
+	}
+	r.events[event.ID] = append(r.events[event.ID], event)
+	r.names[event.Name] = true
+}
+
+func indexedCount(event abi.Event) (n int) {
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			n++
+		}
+	}
+	return
+}
+
+// Event abi event of log, events sharing topic0 (e.g. erc20/erc721 Transfer) are told apart by indexed count
+func (r *Registry) Event(l services.Log) *abi.Event {
+	if len(l.Topics) == 0 {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.events[common.HexToHash(l.Topics[0])] {
+		if indexedCount(e) == len(l.Topics)-1 {
+			return &e
+		}
+	}
+	return nil
+}
+
+// Topics topic0 of the events named name, for filtering stored logs
+func (r *Registry) Topics(name string) []common.Hash {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var topics []common.Hash
+	for id, events := range r.events {
+		for _, e := range events {
+			if e.Name == name {
+				topics = append(topics, id)
+				break
+			}
+		}
+	}
+	return topics
+}
+
+// Decode log into out, a pointer to struct with a field for each event input named abi.ToCamelCase(input name)
+func (r *Registry) Decode(l services.Log, out interface{}) error {
+	event := r.Event(l)
+	if event == nil {
+		return fmt.Errorf("unknown event %s", l.Topics)
+	}
+	return decode(event, l, out)
+}
+
+func decode(event *abi.Event, l services.Log, out interface{}) error {
+	values, err := event.Inputs.Unpack(common.FromHex(util.AddHex(l.Data)))
+	if err != nil {
+		return fmt.Errorf("unpack %s data error: %w", event.Name, err)
+	}
+	if err = event.Inputs.Copy(out, values); err != nil {
+		return fmt.Errorf("copy %s data error: %w", event.Name, err)
+	}
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			continue
+		}
+		if !reflect.ValueOf(out).Elem().FieldByName(abi.ToCamelCase(input.Name)).IsValid() {
+			return fmt.Errorf("field %s of %s not found in %T", abi.ToCamelCase(input.Name), event.Name, out)
+		}
+		indexed = append(indexed, input)
+	}
+	topics := make([]common.Hash, 0, len(l.Topics)-1)
+	for _, topic := range l.Topics[1:] {
+		topics = append(topics, common.HexToHash(topic))
+	}
+	if err = abi.ParseTopics(out, indexed, topics); err != nil {
+		return fmt.Errorf("parse %s topics error: %w", event.Name, err)
+	}
+	return nil
+}
+
+// Handle decode log into the event type of the handler subscribing it. Logs of other events are ignored,
+// but subscribing an event that the registry doesn't know is an error
+func (r *Registry) Handle(l services.Log, handlers ...Handler) error {
+	r.mu.RLock()
+	for _, h := range handlers {
+		if !r.names[h.Name] {
+			r.mu.RUnlock()
+			return fmt.Errorf("abi event %s not registered", h.Name)
+		}
+	}
+	r.mu.RUnlock()
+	event := r.Event(l)
+	if event == nil {
+		return nil
+	}
+	for _, h := range handlers {
+		if h.Name == event.Name {
+			return h.handle(l, event)
+		}
+	}
+	return nil
+}
+
+// On subscribe event named as type T, e.g. On(func(ev *TakedBack) error {...})
+func On[T any](fn func(event *T) error) Handler {
+	return Handler{
+		Name: reflect.TypeOf((*T)(nil)).Elem().Name(),
+		handle: func(l services.Log, event *abi.Event) error {
+			out := new(T)
+			if err := decode(event, l, out); err != nil {
+				return err
+			}
+			return fn(out)
+		},
+	}
+}
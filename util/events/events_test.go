@@ -0,0 +1,138 @@
+package events
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/evolutionlandorg/block-scan/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type TakedBack struct {
+	User  common.Address
+	Nonce *big.Int
+	Value *big.Int
+}
+
+type TakebackMaterial struct {
+	Account common.Address
+	Nonce   *big.Int
+	Id      *big.Int
+	TokenId *big.Int
+	Amount  *big.Int
+}
+
+func word(v int64) string {
+	return common.BigToHash(big.NewInt(v)).Hex()[2:]
+}
+
+func TestRegistry_Handle(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.LoadDir("../../contract"))
+	user := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	l := services.Log{
+		Topics: []string{
+			crypto.Keccak256Hash([]byte("TakedBack(address,uint256,uint256)")).Hex(),
+			common.BytesToHash(user.Bytes()).Hex(),
+			"0x" + word(3),
+		},
+		Data: "0x" + word(1000),
+	}
+	var got *TakedBack
+	assert.NoError(t, r.Handle(l, On(func(ev *TakedBack) error {
+		got = ev
+		return nil
+	})))
+	if assert.NotNil(t, got) {
+		assert.Equal(t, user, got.User)
+		assert.Equal(t, int64(3), got.Nonce.Int64())
+		assert.Equal(t, int64(1000), got.Value.Int64())
+	}
+
+	// not registered
+	assert.Error(t, r.Handle(l, On(func(ev *TakebackMaterial) error { return nil })))
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.Register("TakebackMaterial(address account, uint256 nonce, uint128 id, uint256 tokenId, uint256 amount)"))
+	assert.Error(t, r.Register("TakebackMaterial"))
+	l := services.Log{
+		Topics: []string{crypto.Keccak256Hash([]byte("TakebackMaterial(address,uint256,uint128,uint256,uint256)")).Hex()},
+		Data:   word(0xaa) + word(1) + word(2) + word(3) + word(4),
+	}
+	var ev TakebackMaterial
+	assert.NoError(t, r.Decode(l, &ev))
+	assert.Equal(t, common.HexToAddress("0xaa"), ev.Account)
+	assert.Equal(t, int64(2), ev.Id.Int64())
+	assert.Equal(t, int64(4), ev.Amount.Int64())
+}
+
+type DrillEnchanced struct {
+	User    common.Address
+	TokenId *big.Int
+	Index   *big.Int
+	Rate    *big.Int
+	Class   uint16
+	Grade   uint16
+	Prefer  uint16
+	Now     *big.Int
+}
+
+type Enchanced struct {
+	Id        *big.Int
+	Class     uint8
+	Timestamp *big.Int
+}
+
+func TestRegistry_RegisterAs(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.LoadDir("../../contract"))
+	assert.NoError(t, r.RegisterAs("DrillEnchanced", "Enchanced(address indexed user, uint256 indexed tokenId, uint256 index, uint128 rate, uint16 class, uint16 grade, uint16 prefer, uint256 now)"))
+	user := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	drill := services.Log{
+		Topics: []string{
+			crypto.Keccak256Hash([]byte("Enchanced(address,uint256,uint256,uint128,uint16,uint16,uint16,uint256)")).Hex(),
+			common.BytesToHash(user.Bytes()).Hex(),
+			"0x" + word(7),
+		},
+		Data: word(1) + word(2) + word(3) + word(4) + word(5) + word(6),
+	}
+	craft := services.Log{
+		Topics: []string{crypto.Keccak256Hash([]byte("Enchanced(uint256,uint8,uint256)")).Hex()},
+		Data:   word(9) + word(2) + word(100),
+	}
+	var (
+		drills []DrillEnchanced
+		crafts []Enchanced
+	)
+	handlers := []Handler{
+		On(func(ev *DrillEnchanced) error {
+			drills = append(drills, *ev)
+			return nil
+		}),
+		On(func(ev *Enchanced) error {
+			crafts = append(crafts, *ev)
+			return nil
+		}),
+	}
+	assert.NoError(t, r.Handle(drill, handlers...))
+	assert.NoError(t, r.Handle(craft, handlers...))
+	if assert.Len(t, drills, 1) && assert.Len(t, crafts, 1) {
+		assert.Equal(t, user, drills[0].User)
+		assert.Equal(t, int64(7), drills[0].TokenId.Int64())
+		assert.Equal(t, uint16(3), drills[0].Class)
+		assert.Equal(t, int64(6), drills[0].Now.Int64())
+		assert.Equal(t, int64(9), crafts[0].Id.Int64())
+		assert.Equal(t, uint8(2), crafts[0].Class)
+	}
+}
+
+func TestRegistry_Topics(t *testing.T) {
+	r := NewRegistry()
+	assert.NoError(t, r.Register("TakedBack(address indexed user, uint256 indexed nonce, uint256 value)"))
+	assert.Equal(t, []common.Hash{crypto.Keccak256Hash([]byte("TakedBack(address,uint256,uint256)"))}, r.Topics("TakedBack"))
+	assert.Empty(t, r.Topics("Transfer"))
+}
@@ -0,0 +1,109 @@
+// Package metrics prometheus collectors of api, callbacks, workers, scanners, rpc and daemons, exposed by /metrics
+// without any agent
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "evo"
+
+var (
+	HttpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of api requests by route, method and status, its count is the request count.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	CallbackTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "callback_total",
+		Help:      "Callbacks of scanned transactions by method, chain and result (success, fail or skip).",
+	}, []string{"method", "chain", "result"})
+
+	WorkerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "worker_duration_seconds",
+		Help:      "Processing time of worker jobs by queue.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"queue"})
+
+	QueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_queue_depth",
+		Help:      "Jobs waiting in worker queue.",
+	}, []string{"queue"})
+
+	ScannerHead = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scanner_head_block",
+		Help:      "Latest block of chain rpc.",
+	}, []string{"chain"})
+
+	ScannerCursor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scanner_cursor_block",
+		Help:      "WipeBlock cursor of chain scanner.",
+	}, []string{"chain"})
+
+	ScannerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "scanner_lag_blocks",
+		Help:      "Blocks the chain scanner is behind the rpc head.",
+	}, []string{"chain"})
+
+	RpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Latency of rpc calls and health checks by roundrobin endpoint.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2, 3, 5, 10},
+	}, []string{"endpoint"})
+
+	RpcErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_errors_total",
+		Help:      "Failed rpc calls and health checks by roundrobin endpoint.",
+	}, []string{"endpoint"})
+
+	DaemonTickDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "daemon_tick_duration_seconds",
+		Help:      "Duration of daemon ticks.",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"daemon"})
+
+	DaemonLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "daemon_last_success_timestamp_seconds",
+		Help:      "Unix time of the last success of daemon.",
+	}, []string{"daemon"})
+)
+
+func init() {
+	prometheus.MustRegister(HttpRequestDuration, CallbackTotal, WorkerDuration, QueueDepth, ScannerHead, ScannerCursor,
+		ScannerLag, RpcDuration, RpcErrors, DaemonTickDuration, DaemonLastSuccess)
+}
+
+// Since observe seconds since start, e.g. defer metrics.Since(metrics.DaemonTickDuration, time.Now(), "BuildingTimer")
+func Since(h *prometheus.HistogramVec, start time.Time, labels ...string) {
+	h.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+}
+
+// ObserveRpc record latency or error of a call through rpc endpoint
+func ObserveRpc(endpoint string, latency time.Duration, err error) {
+	if err != nil {
+		RpcErrors.WithLabelValues(endpoint).Inc()
+		return
+	}
+	RpcDuration.WithLabelValues(endpoint).Observe(latency.Seconds())
+}
+
+// Handler metrics in prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
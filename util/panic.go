@@ -33,19 +33,29 @@ func Recover(msg string, showStack ...bool) {
 	}
 }
 
+// RecoverRunForever run f until it returns, f is restarted after interval when it panics.
+// A panic of context canceled ends it like a return
 func RecoverRunForever(msg string, f func(), interval time.Duration, showStack ...bool) {
-	if err := recover(); err != nil {
-		if errors.Is(err.(error), context.Canceled) ||
-			strings.Contains(err.(error).Error(), "canceled") {
-			log.Info("exit '%s'", msg)
-			return
-		}
-		log.DPanic(fmt.Sprintf("%s: %s", msg, cast.ToString(err)))
-		if len(showStack) != 0 && showStack[0] {
-			log.Error(string(debug.Stack()))
-		}
+	for !runRecover(msg, f, showStack...) {
 		time.Sleep(interval)
-		f()
 	}
+}
+
+// runRecover run f, it returns false if f panics with an error other than context canceled
+func runRecover(msg string, f func(), showStack ...bool) (done bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok && errors.Is(err, context.Canceled) || strings.Contains(cast.ToString(r), "canceled") {
+				log.Info("exit '%s'", msg)
+				done = true
+				return
+			}
+			log.DPanic(fmt.Sprintf("%s: %s", msg, cast.ToString(r)))
+			if len(showStack) != 0 && showStack[0] {
+				log.Error(string(debug.Stack()))
+			}
+		}
+	}()
 	f()
+	return true
 }
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverRunForever(t *testing.T) {
+	log.InitLog(log.Options{Level: log.StrLevel2zAPlEVEL("ERROR")})
+	runs := 0
+	RecoverRunForever("test", func() {
+		if runs++; runs < 3 {
+			Panic(context.DeadlineExceeded, "rpc")
+		}
+	}, time.Millisecond)
+	assert.Equal(t, 3, runs)
+
+	runs = 0
+	RecoverRunForever("test", func() {
+		runs++
+		panic(context.Canceled)
+	}, time.Millisecond)
+	assert.Equal(t, 1, runs)
+}
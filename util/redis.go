@@ -1,10 +1,12 @@
 package util
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/evolutionlandorg/evo-backend/util/log"
 	"github.com/go-redsync/redsync/v4/redis/redigo"
 	"github.com/itering/go-workers"
 	"golang.org/x/net/context"
@@ -17,11 +19,6 @@ import (
 var (
 	subPool *redis.Pool
 	RedSync *redsync.Redsync
-
-	redisHost     = GetEnv("REDIS_HOST", "127.0.0.1")
-	redisPort     = GetEnv("REDIS_PORT", "6379")
-	redisPassword = GetEnv("REDIS_PASSWORD", "")
-	redisDatabase = GetEnv("REDIS_DATABASE", "0")
 )
 
 func init() {
@@ -30,8 +27,8 @@ func init() {
 
 func InitWorkers() error {
 	workers.Configure(map[string]string{
-		"server":    redisHost + ":" + redisPort,
-		"database":  redisDatabase,
+		"server":    GetEnv("REDIS_HOST", "127.0.0.1") + ":" + GetEnv("REDIS_PORT", "6379"),
+		"database":  GetEnv("REDIS_DATABASE", "0"),
 		"pool":      "30",
 		"process":   "1",
 		"namespace": "evo",
@@ -39,14 +36,20 @@ func InitWorkers() error {
 	return nil
 }
 
+// InitRedis connect to REDIS_HOST:REDIS_PORT, env is read on each call so tests can point it at a fake server
 func InitRedis() error {
-	db, _ := strconv.Atoi(redisDatabase)
+	var (
+		redisHost     = GetEnv("REDIS_HOST", "127.0.0.1")
+		redisPort     = GetEnv("REDIS_PORT", "6379")
+		redisPassword = GetEnv("REDIS_PASSWORD", "")
+	)
+	db, _ := strconv.Atoi(GetEnv("REDIS_DATABASE", "0"))
 	subPool = &redis.Pool{
 		MaxIdle:     3,
 		IdleTimeout: 240 * time.Second,
 		Dial: func() (redis.Conn, error) {
 			return redigotrace.Dial("tcp", fmt.Sprintf("%s:%s", redisHost, redisPort),
-				redis.DialPassword(redisPassword), redis.DialDatabase(db))
+				redis.DialPassword(redisPassword), redis.DialDatabase(db), redigotrace.WithContextConnection())
 		},
 		TestOnBorrow: func(c redis.Conn, t time.Time) error {
 			_, err := c.Do("PING")
@@ -68,3 +71,89 @@ func SubPoolWithContextDo(ctx context.Context) func(commandName string, args ...
 		return conn.Do(commandName, args...)
 	}
 }
+
+// Subscribe call onMessage with messages of channel, it returns when ctx is done or the connection fails
+func Subscribe(ctx context.Context, channel string, onMessage func(data []byte)) error {
+	conn := subPool.Get()
+	defer conn.Close()
+	psc := redis.PubSubConn{Conn: conn}
+	if err := psc.Subscribe(channel); err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = psc.Unsubscribe(channel)
+		case <-done:
+		}
+	}()
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			onMessage(v.Data)
+		case redis.Subscription:
+			if v.Count == 0 {
+				return ctx.Err()
+			}
+		case error:
+			return v
+		}
+	}
+}
+
+// QueueDepth jobs waiting in go-workers queue
+func QueueDepth(ctx context.Context, queue string) (int, error) {
+	if workers.Config == nil {
+		return 0, errors.New("workers not configured")
+	}
+	return redis.Int(SubPoolWithContextDo(ctx)("LLEN", workers.Config.Namespace+"queue:"+queue))
+}
+
+// RunWithLease run fn on the instance holding lease name, exactly one instance of the cluster leads at a time.
+// The leader keeps extending the lease every ttl/3 until ctx is done, when it fails to extend ctx of fn is canceled
+// and another instance takes over once the lease expires. fn may return early, e.g. after starting goroutines on
+// its ctx, the lease is held until ctx is done anyway
+func RunWithLease(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context)) {
+	for {
+		mutex := RedSync.NewMutex(fmt.Sprintf("evo:Lease:%s", name), redsync.WithExpiry(ttl), redsync.WithTries(1))
+		if err := mutex.TryLockContext(ctx); err == nil {
+			log.Info("lead %s", name)
+			lead(ctx, mutex, ttl, fn)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ttl / 3):
+		}
+	}
+}
+
+func lead(ctx context.Context, mutex *redsync.Mutex, ttl time.Duration, fn func(ctx context.Context)) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		_, _ = mutex.UnlockContext(context.Background())
+	}()
+	// a panic of fn releases the lease instead of killing the process, the lease is taken again by any instance
+	defer Recover(fmt.Sprintf("lead %s error", mutex.Name()), true)
+	go func() {
+		t := time.NewTicker(ttl / 3)
+		defer t.Stop()
+		for {
+			select {
+			case <-leaderCtx.Done():
+				return
+			case <-t.C:
+				if ok, err := mutex.ExtendContext(leaderCtx); !ok || err != nil {
+					log.Warn("lost lease %s: %v", mutex.Name(), err)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	fn(leaderCtx)
+	<-leaderCtx.Done()
+}
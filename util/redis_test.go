@@ -0,0 +1,83 @@
+package util
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedis(t *testing.T) *miniredis.Miniredis {
+	log.InitLog(log.Options{Level: log.StrLevel2zAPlEVEL("ERROR")})
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_HOST", mr.Host())
+	t.Setenv("REDIS_PORT", mr.Port())
+	assert.NoError(t, InitRedis())
+	assert.NoError(t, InitWorkers())
+	return mr
+}
+
+// leader fn of RunWithLease recording how many times it leads and whether it leads now
+type testLeader struct {
+	leads   int32
+	leading int32
+}
+
+func (l *testLeader) fn(ctx context.Context) {
+	atomic.AddInt32(&l.leads, 1)
+	atomic.StoreInt32(&l.leading, 1)
+	go func() {
+		<-ctx.Done()
+		atomic.StoreInt32(&l.leading, 0)
+	}()
+}
+
+func (l *testLeader) isLeading() bool { return atomic.LoadInt32(&l.leading) == 1 }
+
+func TestRunWithLease(t *testing.T) {
+	mr := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ttl := 300 * time.Millisecond
+	a, b := new(testLeader), new(testLeader)
+	go RunWithLease(ctx, "test", ttl, a.fn)
+	assert.Eventually(t, a.isLeading, time.Second, 10*time.Millisecond)
+	go RunWithLease(ctx, "test", ttl, b.fn)
+
+	// the leader keeps extending the lease, the other instance never leads
+	for i := 0; i < 5; i++ {
+		time.Sleep(ttl / 2)
+		mr.FastForward(ttl / 2)
+		assert.True(t, a.isLeading())
+		assert.False(t, b.isLeading())
+	}
+	assert.True(t, mr.Exists("evo:Lease:test"))
+
+	// losing the lease cancels the leader, then one of the instances takes it over
+	mr.Del("evo:Lease:test")
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&a.leads)+atomic.LoadInt32(&b.leads) == 2 }, 2*time.Second, 10*time.Millisecond)
+	assert.NotEqual(t, a.isLeading(), b.isLeading())
+
+	// the lease is released on exit
+	cancel()
+	assert.Eventually(t, func() bool { return !a.isLeading() && !b.isLeading() && !mr.Exists("evo:Lease:test") }, time.Second, 10*time.Millisecond)
+}
+
+func TestRunWithLeasePanic(t *testing.T) {
+	mr := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var runs int32
+	go RunWithLease(ctx, "panic", 300*time.Millisecond, func(ctx context.Context) {
+		if atomic.AddInt32(&runs, 1) == 1 {
+			panic("scan error")
+		}
+	})
+	// the panic releases the lease and the fn leads again
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 2 }, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, mr.Exists("evo:Lease:panic"))
+}
@@ -1,8 +1,14 @@
 package roundrobin
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"time"
+
+	"github.com/evolutionlandorg/evo-backend/util/log"
+	"github.com/evolutionlandorg/evo-backend/util/metrics"
 )
 
 var (
@@ -10,29 +16,354 @@ var (
 	ErrNoAvailableItem = errors.New("no item is available")
 )
 
+// Checker active health check of endpoint, returns the latest block height of the node
+type Checker func(ctx context.Context) (height uint64, err error)
+
+// Endpoint balancer item with its health stats
+type Endpoint struct {
+	Name   string
+	Item   interface{}
+	Weight int     // static weight among healthy endpoints, default 1
+	Check  Checker // optional, endpoint without checker is only judged by reported errors
+
+	mu          sync.Mutex
+	healthy     bool
+	reason      string
+	height      uint64
+	latency     time.Duration // moving average
+	results     []bool        // recent results, true is error
+	window      int           // size of results, HealthOption.Window of the balancer
+	cursor      int
+	passes      int // consecutive passed checks while ejected
+	requests    uint64
+	lastErr     string
+	lastCheckAt time.Time
+	current     int // smooth weighted roundrobin state, guarded by Balancer.m
+}
+
+// HealthOption thresholds of ejecting endpoint
+type HealthOption struct {
+	Interval     time.Duration
+	Timeout      time.Duration
+	MaxLag       uint64        // blocks behind the highest endpoint
+	MaxLatency   time.Duration // 0 means no limit
+	MaxErrorRate float64
+	Window       int // number of recent results the error rate counts
+	MinSamples   int // error rate is ignored before enough samples
+	Readmit      int // consecutive passed checks to re-admit ejected endpoint
+}
+
+// EndpointHealth health snapshot of endpoint
+type EndpointHealth struct {
+	Name        string  `json:"name"`
+	Healthy     bool    `json:"healthy"`
+	Reason      string  `json:"reason,omitempty"`
+	Serving     bool    `json:"serving"`
+	Weight      int     `json:"weight"`
+	Height      uint64  `json:"height"`
+	Lag         uint64  `json:"lag"`
+	LatencyMs   int64   `json:"latency_ms"`
+	ErrorRate   float64 `json:"error_rate"`
+	Requests    uint64  `json:"requests"`
+	LastError   string  `json:"last_error,omitempty"`
+	LastCheckAt int64   `json:"last_check_at"`
+}
+
 // Balancer roundrobin instance
 type Balancer struct {
 	m sync.Mutex
 
-	next  int
-	items []interface{}
+	endpoints  []*Endpoint
+	last       *Endpoint
+	bestHeight uint64
+	opt        HealthOption
+	checking   bool
+}
+
+func DefaultHealthOption() HealthOption {
+	return HealthOption{
+		Interval:     time.Second * 15,
+		Timeout:      time.Second * 5,
+		MaxLag:       10,
+		MaxLatency:   time.Second * 3,
+		MaxErrorRate: 0.5,
+		Window:       20,
+		MinSamples:   5,
+		Readmit:      2,
+	}
 }
 
 // New balancer instance
 func New(items []interface{}) *Balancer {
-	return &Balancer{items: items}
+	var endpoints []*Endpoint
+	for i, item := range items {
+		endpoints = append(endpoints, &Endpoint{Name: fmt.Sprintf("#%d", i), Item: item})
+	}
+	return NewWithEndpoints(endpoints...)
 }
 
-// Pick available item
+// NewWithEndpoints balancer of weighted endpoints, all endpoints are healthy until checked
+func NewWithEndpoints(endpoints ...*Endpoint) *Balancer {
+	b := &Balancer{opt: DefaultHealthOption()}
+	for _, e := range endpoints {
+		if e.Weight <= 0 {
+			e.Weight = 1
+		}
+		e.healthy = true
+		e.window = b.opt.Window
+		b.endpoints = append(b.endpoints, e)
+	}
+	return b
+}
+
+// Pick available item, healthy endpoints are picked by smooth weighted roundrobin.
+// When all endpoints are ejected it falls back to all of them rather than failing
 func (b *Balancer) Pick() (interface{}, error) {
-	if len(b.items) == 0 {
+	if len(b.endpoints) == 0 {
 		return nil, ErrNoAvailableItem
 	}
 
 	b.m.Lock()
-	r := b.items[b.next]
-	b.next = (b.next + 1) % len(b.items)
+	defer b.m.Unlock()
+	var candidates []*Endpoint
+	for _, e := range b.endpoints {
+		if e.isHealthy() {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = b.endpoints
+	}
+	var (
+		best  *Endpoint
+		total int
+	)
+	for _, e := range candidates {
+		e.current += e.Weight
+		total += e.Weight
+		if best == nil || e.current > best.current {
+			best = e
+		}
+	}
+	best.current -= total
+	b.last = best
+
+	best.mu.Lock()
+	best.requests++
+	best.mu.Unlock()
+	return best.Item, nil
+}
+
+// StartHealthCheck check endpoints every opt.Interval until ctx done, only the first call takes effect
+func (b *Balancer) StartHealthCheck(ctx context.Context, opt HealthOption) {
+	b.m.Lock()
+	if b.checking {
+		b.m.Unlock()
+		return
+	}
+	b.checking = true
+	b.opt = opt
+	for _, e := range b.endpoints {
+		e.mu.Lock()
+		e.window = opt.Window
+		e.mu.Unlock()
+	}
+	b.m.Unlock()
+
+	go func() {
+		t := time.NewTicker(opt.Interval)
+		defer t.Stop()
+		for {
+			b.CheckNow(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
+
+// CheckNow run the health checks of all endpoints once, then eject or re-admit them
+func (b *Balancer) CheckNow(ctx context.Context) {
+	b.m.Lock()
+	opt := b.opt
 	b.m.Unlock()
 
-	return r, nil
+	var wg sync.WaitGroup
+	for _, e := range b.endpoints {
+		if e.Check == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(e *Endpoint) {
+			defer wg.Done()
+			e.check(ctx, opt)
+		}(e)
+	}
+	wg.Wait()
+
+	var best uint64
+	for _, e := range b.endpoints {
+		if height := e.Height(); height > best {
+			best = height
+		}
+	}
+	b.m.Lock()
+	b.bestHeight = best
+	b.m.Unlock()
+	for _, e := range b.endpoints {
+		e.evaluate(best, opt)
+	}
+}
+
+// Health snapshot of all endpoints
+func (b *Balancer) Health() []EndpointHealth {
+	b.m.Lock()
+	best, last := b.bestHeight, b.last
+	b.m.Unlock()
+
+	var list []EndpointHealth
+	for _, e := range b.endpoints {
+		e.mu.Lock()
+		h := EndpointHealth{
+			Name:      e.Name,
+			Healthy:   e.healthy,
+			Reason:    e.reason,
+			Serving:   e == last,
+			Weight:    e.Weight,
+			Height:    e.height,
+			LatencyMs: e.latency.Milliseconds(),
+			ErrorRate: e.errorRate(),
+			Requests:  e.requests,
+			LastError: e.lastErr,
+		}
+		if best > e.height && e.height > 0 {
+			h.Lag = best - e.height
+		}
+		if !e.lastCheckAt.IsZero() {
+			h.LastCheckAt = e.lastCheckAt.Unix()
+		}
+		e.mu.Unlock()
+		list = append(list, h)
+	}
+	return list
+}
+
+// Report result of a call through endpoint, counted in its latency and error rate
+func (e *Endpoint) Report(latency time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.record(latency, err, e.window)
+}
+
+func (e *Endpoint) Height() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.height
+}
+
+func (e *Endpoint) isHealthy() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.healthy
+}
+
+func (e *Endpoint) record(latency time.Duration, err error, window int) {
+	metrics.ObserveRpc(e.Name, latency, err)
+	if window <= 0 {
+		window = 1
+	}
+	if len(e.results) < window {
+		e.results = append(e.results, err != nil)
+	} else {
+		e.results[e.cursor%len(e.results)] = err != nil
+	}
+	e.cursor++
+	if err != nil {
+		e.lastErr = err.Error()
+		return
+	}
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = (e.latency*4 + latency) / 5
+	}
+}
+
+func (e *Endpoint) errorRate() float64 {
+	if len(e.results) == 0 {
+		return 0
+	}
+	var errs int
+	for _, failed := range e.results {
+		if failed {
+			errs++
+		}
+	}
+	return float64(errs) / float64(len(e.results))
+}
+
+func (e *Endpoint) check(ctx context.Context, opt HealthOption) {
+	ctx, cancel := context.WithTimeout(ctx, opt.Timeout)
+	defer cancel()
+	type result struct {
+		height uint64
+		err    error
+	}
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		height, err := e.Check(ctx)
+		done <- result{height, err}
+	}()
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = fmt.Errorf("health check timeout after %s", opt.Timeout)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastCheckAt = time.Now()
+	e.record(time.Since(start), r.err, opt.Window)
+	if r.err == nil {
+		e.height = r.height
+	}
+}
+
+// evaluate eject endpoint breaking any threshold, re-admit it after opt.Readmit passed checks
+func (e *Endpoint) evaluate(best uint64, opt HealthOption) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var reason string
+	switch {
+	case e.Check != nil && len(e.results) > 0 && e.results[(e.cursor-1)%len(e.results)] && e.lastErr != "":
+		reason = fmt.Sprintf("unreachable: %s", e.lastErr)
+	case e.height > 0 && best > e.height && best-e.height > opt.MaxLag:
+		reason = fmt.Sprintf("lagging %d blocks", best-e.height)
+	case opt.MaxLatency > 0 && e.latency > opt.MaxLatency:
+		reason = fmt.Sprintf("latency %s", e.latency)
+	case len(e.results) >= opt.MinSamples && e.errorRate() > opt.MaxErrorRate:
+		reason = fmt.Sprintf("error rate %.2f", e.errorRate())
+	}
+
+	if reason != "" {
+		if e.healthy {
+			log.Warn("rpc endpoint %s ejected, %s", e.Name, reason)
+			e.results = e.results[:0] // judge the ejected endpoint by health checks only
+			e.cursor = 0
+		}
+		e.healthy, e.reason, e.passes = false, reason, 0
+		return
+	}
+	if e.healthy {
+		return
+	}
+	e.passes++
+	if e.passes >= opt.Readmit {
+		log.Info("rpc endpoint %s re-admitted", e.Name)
+		e.healthy, e.reason, e.passes = true, "", 0
+	}
 }
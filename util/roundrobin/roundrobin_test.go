@@ -0,0 +1,102 @@
+package roundrobin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalancer_Pick(t *testing.T) {
+	b := New([]interface{}{"a", "b"})
+	var picked []interface{}
+	for i := 0; i < 4; i++ {
+		item, err := b.Pick()
+		assert.NoError(t, err)
+		picked = append(picked, item)
+	}
+	assert.Equal(t, []interface{}{"a", "b", "a", "b"}, picked)
+
+	b = NewWithEndpoints(&Endpoint{Name: "a", Item: "a", Weight: 3}, &Endpoint{Name: "b", Item: "b"})
+	count := make(map[interface{}]int)
+	for i := 0; i < 8; i++ {
+		item, _ := b.Pick()
+		count[item]++
+	}
+	assert.Equal(t, 6, count["a"])
+	assert.Equal(t, 2, count["b"])
+
+	_, err := New(nil).Pick()
+	assert.Equal(t, ErrNoAvailableItem, err)
+}
+
+func TestBalancer_CheckNow(t *testing.T) {
+	heights := map[string]uint64{"a": 100, "b": 100}
+	var down bool
+	checker := func(name string) Checker {
+		return func(context.Context) (uint64, error) {
+			if name == "b" && down {
+				return 0, errors.New("connection refused")
+			}
+			return heights[name], nil
+		}
+	}
+	b := NewWithEndpoints(&Endpoint{Name: "a", Item: "a", Check: checker("a")}, &Endpoint{Name: "b", Item: "b", Check: checker("b")})
+	ctx := context.TODO()
+
+	// lagging
+	heights["a"] = 120
+	b.CheckNow(ctx)
+	for i := 0; i < 3; i++ {
+		item, _ := b.Pick()
+		assert.Equal(t, "a", item)
+	}
+	health := b.Health()
+	assert.False(t, health[1].Healthy)
+	assert.Equal(t, uint64(20), health[1].Lag)
+	assert.True(t, health[0].Serving)
+
+	// re-admitted after passed checks
+	heights["b"] = 120
+	b.CheckNow(ctx)
+	assert.False(t, b.Health()[1].Healthy)
+	b.CheckNow(ctx)
+	assert.True(t, b.Health()[1].Healthy)
+
+	// unreachable
+	down = true
+	b.CheckNow(ctx)
+	health = b.Health()
+	assert.False(t, health[1].Healthy)
+	assert.Equal(t, "connection refused", health[1].LastError)
+
+	// error rate of reported calls
+	a := b.endpoints[0]
+	for i := 0; i < 10; i++ {
+		a.Report(0, errors.New("bad gateway"))
+	}
+	b.CheckNow(ctx)
+	assert.False(t, b.Health()[0].Healthy)
+	item, err := b.Pick() // all ejected, fall back to all endpoints
+	assert.NoError(t, err)
+	assert.NotNil(t, item)
+}
+
+func TestEndpoint_Report(t *testing.T) {
+	e := &Endpoint{Name: "a", Item: "a"}
+	b := NewWithEndpoints(e)
+	opt := DefaultHealthOption()
+	opt.Window = 3
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	b.StartHealthCheck(ctx, opt)
+
+	// reported calls are counted in the window of the balancer option
+	for i := 0; i < 10; i++ {
+		e.Report(0, errors.New("bad gateway"))
+	}
+	e.Report(0, nil)
+	assert.Len(t, e.results, 3)
+	assert.InDelta(t, 2.0/3, e.errorRate(), 0.001)
+}
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/jinzhu/gorm"
 	"github.com/spf13/cast"
 )
 
@@ -42,3 +43,89 @@ func StructToSql(src interface{}) (wheres []string, values []interface{}) {
 	}
 	return
 }
+
+// Where condition with bound parameters, Query contains only column names, operators and placeholders.
+// Column names are constants of callers, values always go to Args
+type Where struct {
+	Query string
+	Args  []interface{}
+}
+
+func Eq(column string, value interface{}) Where {
+	return Where{Query: column + " = ?", Args: []interface{}{value}}
+}
+
+func Ne(column string, value interface{}) Where {
+	return Where{Query: column + " != ?", Args: []interface{}{value}}
+}
+
+func Gte(column string, value interface{}) Where {
+	return Where{Query: column + " >= ?", Args: []interface{}{value}}
+}
+
+func Lte(column string, value interface{}) Where {
+	return Where{Query: column + " <= ?", Args: []interface{}{value}}
+}
+
+// In column in values, values is a slice. Empty values match nothing
+func In(column string, values interface{}) Where {
+	if reflect.ValueOf(values).Len() == 0 {
+		return Where{Query: "1 = 0"}
+	}
+	return Where{Query: column + " IN (?)", Args: []interface{}{values}}
+}
+
+// NotIn column not in values, values is a slice. Empty values match everything
+func NotIn(column string, values interface{}) Where {
+	if reflect.ValueOf(values).Len() == 0 {
+		return Where{Query: "1 = 1"}
+	}
+	return Where{Query: column + " NOT IN (?)", Args: []interface{}{values}}
+}
+
+func Or(wheres ...Where) Where {
+	return join(" OR ", wheres)
+}
+
+func And(wheres ...Where) Where {
+	return join(" AND ", wheres)
+}
+
+func join(sep string, wheres []Where) Where {
+	var (
+		queries []string
+		w       Where
+	)
+	for _, where := range wheres {
+		queries = append(queries, fmt.Sprintf("(%s)", where.Query))
+		w.Args = append(w.Args, where.Args...)
+	}
+	w.Query = strings.Join(queries, sep)
+	return w
+}
+
+// Wheres conditions joined by AND
+type Wheres []Where
+
+func (ws Wheres) Apply(db *gorm.DB) *gorm.DB {
+	for _, w := range ws {
+		db = db.Where(w.Query, w.Args...)
+	}
+	return db
+}
+
+// OrderBy order clause of field in allowed and direction asc or desc, empty if field is not allowed
+func OrderBy(field, direction string, allowed []string) string {
+	if !StringInSlice(field, allowed) {
+		return ""
+	}
+	return field + " " + SortDirection(direction)
+}
+
+// SortDirection asc or desc, desc by default
+func SortDirection(direction string) string {
+	if strings.EqualFold(direction, "asc") {
+		return "asc"
+	}
+	return "desc"
+}
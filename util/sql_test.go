@@ -35,3 +35,31 @@ func TestStructToSql(t *testing.T) {
 		})
 	}
 }
+
+func TestWhere(t *testing.T) {
+	tests := []struct {
+		name      string
+		where     Where
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{"eq", Eq("owner", "0x1' or '1'='1"), "owner = ?", []interface{}{"0x1' or '1'='1"}},
+		{"in", In("token_id", []string{"a", "b"}), "token_id IN (?)", []interface{}{[]string{"a", "b"}}},
+		{"empty in", In("token_id", []string{}), "1 = 0", nil},
+		{"empty not in", NotIn("token_id", []string(nil)), "1 = 1", nil},
+		{"or", Or(Eq("owner", "a"), In("token_id", []string{"b"})), "(owner = ?) OR (token_id IN (?))", []interface{}{"a", []string{"b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantQuery, tt.where.Query)
+			assert.Equal(t, tt.wantArgs, tt.where.Args)
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	allowed := []string{"token_index", "gold_rate"}
+	assert.Equal(t, "token_index asc", OrderBy("token_index", "ASC", allowed))
+	assert.Equal(t, "gold_rate desc", OrderBy("gold_rate", "desc; drop table lands", allowed))
+	assert.Equal(t, "", OrderBy("token_index; drop table lands", "asc", allowed))
+}
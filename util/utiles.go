@@ -67,14 +67,14 @@ func TryReturn(f func() (result interface{}, err error), tryCount ...int) (resul
 	return nil, err
 }
 
+// ScheduledTask run f every interval until ctx is done, a panic of f waits for the next tick
 func ScheduledTask(ctx context.Context, f func(), interval time.Duration) {
-	defer RecoverRunForever("ScheduledTask error", func() { ScheduledTask(ctx, f, interval) }, time.Second*20, true)
 	t := time.NewTicker(interval)
 	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
-			f()
+			runRecover("ScheduledTask error", f, true)
 		case <-ctx.Done():
 			return
 		}
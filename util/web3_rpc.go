@@ -1,27 +1,68 @@
 package util
 
 import (
+	"context"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/evolutionlandorg/evo-backend/pkg/github.com/regcostajr/go-web3"
 	"github.com/evolutionlandorg/evo-backend/pkg/github.com/regcostajr/go-web3/providers"
 	"github.com/evolutionlandorg/evo-backend/util/roundrobin"
+	"github.com/spf13/cast"
 )
 
 var (
-	initEthRPC []interface{}
-	Rb         *roundrobin.Balancer
+	Rb *roundrobin.Balancer
 )
 
 func init() {
 	rpc := GetEnv("ETH_RPC", "")
 	ssl := GetEnv("SSL", "true")
+	Rb = NewRPCBalancer(strings.Split(rpc, ","), 10, ssl == "true")
+}
+
+// rpcProvider report each request to the health stats of endpoint
+type rpcProvider struct {
+	providers.ProviderInterface
+	endpoint *roundrobin.Endpoint
+}
+
+func (p rpcProvider) SendRequest(v interface{}, method string, params interface{}) error {
+	start := time.Now()
+	err := p.ProviderInterface.SendRequest(v, method, params)
+	p.endpoint.Report(time.Since(start), err)
+	return err
+}
+
+// NewRPCBalancer balancer of *web3.Web3, endpoint can be weighted as "url|weight"
+func NewRPCBalancer(endpoints []string, timeout int32, secure bool) *roundrobin.Balancer {
+	var items []*roundrobin.Endpoint
+	for _, endpoint := range endpoints {
+		address, weight, _ := strings.Cut(strings.TrimSpace(endpoint), "|")
+		provider := providers.NewHTTPProvider(address, timeout, secure)
+		checker := web3.NewWeb3(provider)
+		e := &roundrobin.Endpoint{Name: rpcEndpointName(address), Weight: cast.ToInt(weight)}
+		e.Item = web3.NewWeb3(rpcProvider{ProviderInterface: provider, endpoint: e})
+		e.Check = func(context.Context) (uint64, error) {
+			height, err := checker.Eth.GetBlockNumber()
+			if err != nil {
+				return 0, err
+			}
+			return height.Uint64(), nil
+		}
+		items = append(items, e)
+	}
+	return roundrobin.NewWithEndpoints(items...)
+}
 
-	for _, endpoint := range strings.Split(rpc, ",") {
-		EthProvider := providers.NewHTTPProvider(endpoint, 10, ssl == "true")
-		initEthRPC = append(initEthRPC, web3.NewWeb3(EthProvider))
+// rpcEndpointName host of rpc url, the path may contain api key
+func rpcEndpointName(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		return u.Host
 	}
-	Rb = roundrobin.New(initEthRPC)
+	host, _, _ := strings.Cut(address, "/")
+	return host
 }
 
 func GetContractAddress(contract string, chain ...string) (addr string) {